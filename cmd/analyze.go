@@ -0,0 +1,68 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "strconv"
+    "text/tabwriter"
+
+    "github.com/ryuzxy/FuncPro/pkg/komoditas"
+    "github.com/ryuzxy/FuncPro/pkg/price"
+)
+
+func runAnalyze(args []string) error {
+    fs, loadConfig := newFlagSet("analyze", "<komoditas id or name>")
+    fill := fs.String("fill", "", "gap fill before analysing: ffill, linear or seasonal")
+    currency := fs.String("currency", "", "convert prices to this currency first")
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+    if fs.NArg() != 1 {
+        fs.Usage()
+        return fmt.Errorf("expected one komoditas")
+    }
+
+    svc, err := openServices(loadConfig)
+    if err != nil {
+        return err
+    }
+    ctx := context.Background()
+
+    k, err := findKomoditas(ctx, svc, fs.Arg(0))
+    if err != nil {
+        return err
+    }
+    opts := price.AnalysisOptions{Values: price.ValueOptions{Currency: *currency}}
+    if opts.Fill, err = price.ParseFillStrategy(*fill); err != nil {
+        return err
+    }
+    analysis, err := svc.prices.GetPriceAnalysis(ctx, k.ID, opts).Unwrap()
+    if err != nil {
+        return err
+    }
+
+    w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+    fmt.Fprintf(w, "Komoditas\t%s (id %d, per %s)\n", k.Name, k.ID, k.BaseUnit)
+    fmt.Fprintf(w, "Current\t%s\n", analysis.Current)
+    fmt.Fprintf(w, "Previous\t%s\n", analysis.Previous)
+    fmt.Fprintf(w, "Change\t%s (%.2f%%)\n", analysis.Change, analysis.ChangePct)
+    fmt.Fprintf(w, "Trend\t%s\n", analysis.Trend)
+    fmt.Fprintf(w, "Volatility\t%.4f\n", analysis.Volatility)
+    if analysis.Forecast != nil {
+        fmt.Fprintf(w, "Forecast\t%s\n", *analysis.Forecast)
+    }
+    return w.Flush()
+}
+
+// findKomoditas takes a komoditas id, or else its exact name.
+func findKomoditas(ctx context.Context, svc services, arg string) (*komoditas.Komoditas, error) {
+    if id, err := strconv.ParseUint(arg, 10, 64); err == nil {
+        return svc.komoditas.GetKomoditasByID(ctx, uint(id)).Unwrap()
+    }
+    k, err := svc.komoditasRepo.GetByName(ctx, arg).Unwrap()
+    if err != nil {
+        return nil, fmt.Errorf("komoditas %q: %w", arg, err)
+    }
+    return k, nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "strconv"
+    "text/tabwriter"
+    "time"
+
+    "github.com/ryuzxy/FuncPro/pkg/auth"
+)
+
+// runAPIKey manages API keys from the shell, which is how the first admin
+// key comes to exist.
+func runAPIKey(args []string) error {
+    fs, loadConfig := newFlagSet("apikey", "create | list | revoke <id>")
+    name := fs.String("name", "", "name of the key to create, e.g. the integration using it")
+    role := fs.String("role", string(auth.RoleViewer), "role of the key to create: viewer, contributor or admin")
+    expires := fs.Duration("expires", 0, "lifetime of the key to create, 0 for no expiry")
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+    args = fs.Args()
+    if len(args) == 0 {
+        fs.Usage()
+        return fmt.Errorf("missing apikey action")
+    }
+
+    svc, err := openServices(loadConfig)
+    if err != nil {
+        return err
+    }
+    ctx := context.Background()
+
+    switch args[0] {
+    case "create":
+        req := auth.CreateKeyRequest{Name: *name, Role: *role}
+        if req.Name == "" {
+            return fmt.Errorf("-name is required")
+        }
+        if *expires > 0 {
+            at := time.Now().Add(*expires)
+            req.ExpiresAt = &at
+        }
+        created, err := svc.keys.CreateKey(ctx, req).Unwrap()
+        if err != nil {
+            return err
+        }
+        // The key goes to stdout alone, so it can be captured by a script.
+        fmt.Fprintf(os.Stderr, "created API key %d (%s, %s); it is not shown again\n", created.ID, created.Name, created.Role)
+        fmt.Println(created.Key)
+        return nil
+
+    case "list":
+        keys, err := svc.keys.GetAllKeys(ctx).Unwrap()
+        if err != nil {
+            return err
+        }
+        w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+        fmt.Fprintln(w, "ID\tNAME\tPREFIX\tROLE\tEXPIRES\tLAST USED")
+        for _, k := range keys {
+            fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", k.ID, k.Name, k.Prefix, k.Role, formatTime(k.ExpiresAt, "never"), formatTime(k.LastUsedAt, "never"))
+        }
+        return w.Flush()
+
+    case "revoke":
+        if len(args) != 2 {
+            return fmt.Errorf("revoke takes the id of one key")
+        }
+        id, err := strconv.ParseUint(args[1], 10, 32)
+        if err != nil {
+            return fmt.Errorf("invalid key id %q", args[1])
+        }
+        if _, err := svc.keys.RevokeKey(ctx, uint(id)).Unwrap(); err != nil {
+            return err
+        }
+        fmt.Fprintf(os.Stderr, "revoked API key %d\n", id)
+        return nil
+    }
+
+    fs.Usage()
+    return fmt.Errorf("unknown apikey action %q", args[0])
+}
+
+func formatTime(t *time.Time, unset string) string {
+    if t == nil {
+        return unset
+    }
+    return t.Format("2006-01-02 15:04:05 MST")
+}
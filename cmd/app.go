@@ -0,0 +1,85 @@
+package main
+
+import (
+    "flag"
+    "fmt"
+    "log"
+    "os"
+
+    "github.com/joho/godotenv"
+    "gorm.io/gorm"
+
+    "github.com/ryuzxy/FuncPro/db"
+    "github.com/ryuzxy/FuncPro/internal/config"
+    "github.com/ryuzxy/FuncPro/internal/logging"
+    "github.com/ryuzxy/FuncPro/pkg/auth"
+    "github.com/ryuzxy/FuncPro/pkg/komoditas"
+    "github.com/ryuzxy/FuncPro/pkg/market"
+    "github.com/ryuzxy/FuncPro/pkg/price"
+)
+
+// loadEnv reads the .env file, when there is one, into the environment
+// before any command loads its configuration.
+func loadEnv() {
+    if err := godotenv.Load(); err != nil {
+        log.Println("No .env file found, using system environment variables")
+    }
+}
+
+// services are the parts of the API the offline commands share, built the
+// same way the router builds them.
+type services struct {
+    komoditas     komoditas.Service
+    komoditasRepo komoditas.Repository
+    prices        price.Service
+    keys          auth.Service
+}
+
+func newServices(database *gorm.DB, cfg *config.Config) services {
+    komoditasRepo := komoditas.NewRepository(database)
+    priceRepo := price.NewPriceRepository(database)
+    marketRepo := market.NewRepository(database)
+    return services{
+        komoditas:     komoditas.NewService(komoditasRepo),
+        komoditasRepo: komoditasRepo,
+        prices:        price.NewService(priceRepo, marketRepo, komoditasRepo, price.Config{WindowDays: cfg.Analysis.WindowDays}),
+        keys:          auth.NewService(auth.NewRepository(database)),
+    }
+}
+
+// openServices loads the config and opens a migrated database.
+func openServices(loadConfig configLoader) (services, error) {
+    cfg, err := loadConfig()
+    if err != nil {
+        return services{}, err
+    }
+    database, err := db.InitDB(cfg)
+    if err != nil {
+        return services{}, err
+    }
+    return newServices(database, cfg), nil
+}
+
+// configLoader reads the configuration once the command line is parsed.
+type configLoader func() (*config.Config, error)
+
+// newFlagSet returns a flag set for a command whose usage line lists its
+// positional arguments. Every command takes the configuration flags too.
+func newFlagSet(name, positional string) (*flag.FlagSet, configLoader) {
+    fs := flag.NewFlagSet(name, flag.ContinueOnError)
+    fs.Usage = func() {
+        fmt.Fprintf(os.Stderr, "usage: funcpro %s [flags] %s\n", name, positional)
+        fs.PrintDefaults()
+    }
+    load := config.Flags(fs)
+    return fs, func() (*config.Config, error) {
+        cfg, err := load()
+        if err != nil {
+            return nil, err
+        }
+        if err := logging.Setup(os.Stderr, cfg.Log.Level, cfg.Log.Format); err != nil {
+            return nil, err
+        }
+        return cfg, nil
+    }
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "os"
+
+    "github.com/ryuzxy/FuncPro/pkg/price"
+)
+
+func runExport(args []string) error {
+    fs, loadConfig := newFlagSet("export", "")
+    komoditasID := fs.Uint("komoditas", 0, "only export this komoditas id")
+    out := fs.String("o", "-", "output file, - for stdout")
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    svc, err := openServices(loadConfig)
+    if err != nil {
+        return err
+    }
+    ctx := context.Background()
+
+    ids := []uint{*komoditasID}
+    if *komoditasID == 0 {
+        all, err := svc.komoditas.GetAllKomoditas(ctx).Unwrap()
+        if err != nil {
+            return err
+        }
+        ids = ids[:0]
+        for _, k := range all {
+            ids = append(ids, k.ID)
+        }
+    }
+
+    var prices []price.Price
+    for _, id := range ids {
+        batch, err := svc.prices.GetPricesByKomoditas(ctx, id, price.ValueOptions{}).Unwrap()
+        if err != nil {
+            return fmt.Errorf("komoditas %d: %w", id, err)
+        }
+        prices = append(prices, batch...)
+    }
+
+    var w io.Writer = os.Stdout
+    if *out != "-" {
+        f, err := os.Create(*out)
+        if err != nil {
+            return err
+        }
+        defer f.Close()
+        w = f
+    }
+    return price.WriteCSV(w, prices)
+}
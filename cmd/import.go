@@ -0,0 +1,53 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "log"
+    "os"
+
+    "github.com/ryuzxy/FuncPro/pkg/price"
+)
+
+func runImport(args []string) error {
+    fs, loadConfig := newFlagSet("import", "<file.csv | ->")
+    dryRun := fs.Bool("dry-run", false, "only check the file")
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+    if fs.NArg() != 1 {
+        fs.Usage()
+        return fmt.Errorf("expected one CSV file")
+    }
+
+    var in io.Reader = os.Stdin
+    if path := fs.Arg(0); path != "-" {
+        f, err := os.Open(path)
+        if err != nil {
+            return err
+        }
+        defer f.Close()
+        in = f
+    }
+    reqs, err := price.ParseCSV(in)
+    if err != nil {
+        return err
+    }
+    if *dryRun {
+        log.Printf("%d prices read, nothing imported", len(reqs))
+        return nil
+    }
+
+    svc, err := openServices(loadConfig)
+    if err != nil {
+        return err
+    }
+    // One bulk insert, so a file is imported whole or not at all.
+    created, err := svc.prices.BulkCreatePrices(context.Background(), reqs).Unwrap()
+    if err != nil {
+        return err
+    }
+    log.Printf("imported %d prices", len(created))
+    return nil
+}
@@ -1,43 +1,69 @@
 package main
 
 import (
+    "errors"
+    "flag"
+    "fmt"
     "log"
     "os"
-    
-    "github.com/joho/godotenv"
-    "github.com/ryuzxy/FuncPro/db"
-    "github.com/ryuzxy/FuncPro/internal/config"
-    "github.com/ryuzxy/FuncPro/router"
-    "github.com/ryuzxy/FuncPro/internal/middleware"
+    "sort"
 )
 
-func main() {
-    // Load environment variables
-    if err := godotenv.Load(); err != nil {
-        log.Println("No .env file found, using system environment variables")
+// command is one subcommand of the binary. run gets the arguments after the
+// command name.
+type command struct {
+    summary string
+    run     func(args []string) error
+}
+
+var commands = map[string]command{
+    "serve":   {"run the HTTP API (the default)", runServe},
+    "migrate": {"apply, revert or list schema migrations", runMigrate},
+    "seed":    {"add demo komoditas with synthetic prices", runSeed},
+    "import":  {"load a CSV file of prices", runImport},
+    "export":  {"write prices as CSV", runExport},
+    "analyze": {"print the price analysis of a komoditas", runAnalyze},
+    "apikey":  {"create, list or revoke API keys", runAPIKey},
+}
+
+func usage() {
+    fmt.Fprintln(os.Stderr, "usage: funcpro <command> [flags] [args]")
+    fmt.Fprintln(os.Stderr)
+    names := make([]string, 0, len(commands))
+    for name := range commands {
+        names = append(names, name)
     }
-    
-    // Load configuration
-    cfg := config.Load()
-    
-    // Initialize database
-    database, err := db.InitDB(cfg)
-    if err != nil {
-        log.Printf("Failed to initialize database: %v", err)
-        os.Exit(1)
+    sort.Strings(names)
+    for _, name := range names {
+        fmt.Fprintf(os.Stderr, "  %-8s %s\n", name, commands[name].summary)
     }
-    
-    // Setup router
-    r := router.SetupRouter(database)
+    fmt.Fprintln(os.Stderr)
+    fmt.Fprintln(os.Stderr, "Run funcpro <command> -h for the flags of a command.")
+}
 
-    //setup midleware
-    r.Use(middleware.Logger(), middleware.CORS())
+func main() {
+    // Without a command the binary serves, as it always has.
+    name, args := "serve", os.Args[1:]
+    if len(args) > 0 {
+        name, args = args[0], args[1:]
+    }
+    if name == "help" || name == "-h" || name == "--help" {
+        usage()
+        return
+    }
 
-    
-    // Start server
-    log.Printf("Server starting on port %s", cfg.ServerPort)
-    if err := r.Run(":" + cfg.ServerPort); err != nil {
-        log.Printf("Server failed: %v", err)
+    cmd, ok := commands[name]
+    if !ok {
+        fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+        usage()
+        os.Exit(2)
+    }
+    loadEnv()
+    if err := cmd.run(args); err != nil {
+        if errors.Is(err, flag.ErrHelp) {
+            return
+        }
+        log.Printf("%s: %v", name, err)
         os.Exit(1)
     }
-}
\ No newline at end of file
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "os"
+    "strconv"
+    "text/tabwriter"
+
+    "github.com/ryuzxy/FuncPro/db"
+)
+
+func runMigrate(args []string) error {
+    fs, loadConfig := newFlagSet("migrate", "up | down [steps] | status")
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+    args = fs.Args()
+    if len(args) == 0 {
+        fs.Usage()
+        return fmt.Errorf("missing migrate action")
+    }
+
+    // The schema is not checked here: bringing it up to date is the point.
+    cfg, err := loadConfig()
+    if err != nil {
+        return err
+    }
+    database, err := db.Open(cfg)
+    if err != nil {
+        return err
+    }
+    migrator, err := db.NewMigrator(database)
+    if err != nil {
+        return err
+    }
+    ctx := context.Background()
+
+    switch args[0] {
+    case "up":
+        applied, err := migrator.Up(ctx)
+        for _, m := range applied {
+            log.Printf("applied %04d_%s", m.Version, m.Name)
+        }
+        if err == nil && len(applied) == 0 {
+            log.Println("schema is up to date")
+        }
+        return err
+
+    case "down":
+        steps := 1
+        if len(args) > 1 {
+            n, err := strconv.Atoi(args[1])
+            if err != nil || n < 1 {
+                return fmt.Errorf("steps must be a positive number, got %q", args[1])
+            }
+            steps = n
+        }
+        reverted, err := migrator.Down(ctx, steps)
+        for _, m := range reverted {
+            log.Printf("reverted %04d_%s", m.Version, m.Name)
+        }
+        if err == nil && len(reverted) == 0 {
+            log.Println("no migrations to revert")
+        }
+        return err
+
+    case "status":
+        statuses, err := migrator.Status(ctx)
+        if err != nil {
+            return err
+        }
+        w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+        fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+        for _, s := range statuses {
+            applied := "pending"
+            if s.AppliedAt != nil {
+                applied = s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+            }
+            fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, applied)
+        }
+        return w.Flush()
+    }
+
+    fs.Usage()
+    return fmt.Errorf("unknown migrate action %q", args[0])
+}
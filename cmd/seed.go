@@ -0,0 +1,91 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "math"
+    "math/rand"
+    "time"
+
+    "github.com/ryuzxy/FuncPro/pkg/komoditas"
+    "github.com/ryuzxy/FuncPro/pkg/money"
+    "github.com/ryuzxy/FuncPro/pkg/price"
+)
+
+// demoKomoditas are seeded with prices around base, in rupiah per base unit.
+var demoKomoditas = []struct {
+    name     string
+    kind     string
+    baseUnit string
+    base     float64
+}{
+    {"Beras Medium", "pangan pokok", "kg", 13000},
+    {"Cabai Merah Keriting", "hortikultura", "kg", 45000},
+    {"Bawang Merah", "hortikultura", "kg", 35000},
+    {"Telur Ayam Ras", "peternakan", "kg", 28000},
+    {"Gula Pasir", "pangan pokok", "kg", 17500},
+    {"Minyak Goreng Curah", "pangan pokok", "l", 15000},
+}
+
+func runSeed(args []string) error {
+    fs, loadConfig := newFlagSet("seed", "")
+    days := fs.Int("days", 365, "days of daily prices per komoditas, ending today")
+    seed := fs.Int64("seed", 1, "random seed, so a seed can be reproduced")
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+    if *days < 1 {
+        return fmt.Errorf("days must be at least 1")
+    }
+
+    svc, err := openServices(loadConfig)
+    if err != nil {
+        return err
+    }
+    ctx := context.Background()
+    rng := rand.New(rand.NewSource(*seed))
+    today := time.Now().UTC().Truncate(24 * time.Hour)
+
+    for _, demo := range demoKomoditas {
+        if svc.komoditasRepo.GetByName(ctx, demo.name).IsOk() {
+            log.Printf("%s already exists, skipping", demo.name)
+            continue
+        }
+        k, err := svc.komoditas.CreateKomoditas(ctx, komoditas.CreateKomoditasRequest{
+            Name:     demo.name,
+            Type:     demo.kind,
+            BaseUnit: demo.baseUnit,
+        }).Unwrap()
+        if err != nil {
+            return fmt.Errorf("creating %s: %w", demo.name, err)
+        }
+
+        reqs := syntheticPrices(rng, k.ID, demo.base, today, *days)
+        if _, err := svc.prices.BulkCreatePrices(ctx, reqs).Unwrap(); err != nil {
+            return fmt.Errorf("adding prices for %s: %w", demo.name, err)
+        }
+        log.Printf("seeded %s (id %d) with %d prices", demo.name, k.ID, len(reqs))
+    }
+    return nil
+}
+
+// syntheticPrices is a random walk around base with a weekly pattern and a
+// yearly one, rounded to whole rupiah, for the days up to and including end.
+func syntheticPrices(rng *rand.Rand, komoditasID uint, base float64, end time.Time, days int) []price.CreatePriceRequest {
+    reqs := make([]price.CreatePriceRequest, 0, days)
+    walk := 0.0
+    for i := days - 1; i >= 0; i-- {
+        date := end.AddDate(0, 0, -i)
+        walk = 0.98*walk + rng.NormFloat64()*0.01
+        weekly := 0.02 * math.Sin(2*math.Pi*float64(date.Weekday())/7)
+        yearly := 0.05 * math.Sin(2*math.Pi*float64(date.YearDay())/365)
+        value := math.Round(base * (1 + walk + weekly + yearly))
+        reqs = append(reqs, price.CreatePriceRequest{
+            KomoditasID: komoditasID,
+            Value:       money.FromFloat(value),
+            Date:        date,
+        })
+    }
+    return reqs
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+    "math/rand"
+    "testing"
+    "time"
+)
+
+func TestSyntheticPrices(t *testing.T) {
+    end := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+    reqs := syntheticPrices(rand.New(rand.NewSource(1)), 3, 10000, end, 90)
+    if len(reqs) != 90 {
+        t.Fatalf("got %d prices, want 90", len(reqs))
+    }
+    if !reqs[0].Date.Equal(end.AddDate(0, 0, -89)) || !reqs[89].Date.Equal(end) {
+        t.Errorf("prices run %s to %s", reqs[0].Date, reqs[89].Date)
+    }
+    for i, r := range reqs {
+        if r.KomoditasID != 3 {
+            t.Fatalf("price %d is for komoditas %d", i, r.KomoditasID)
+        }
+        if v := r.Value.Float64(); v < 7000 || v > 13000 {
+            t.Errorf("price %d = %s, far from the base", i, r.Value)
+        }
+    }
+
+    again := syntheticPrices(rand.New(rand.NewSource(1)), 3, 10000, end, 90)
+    for i := range reqs {
+        if reqs[i].Value != again[i].Value {
+            t.Fatal("the same seed gave different prices")
+        }
+    }
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log/slog"
+    "net"
+    "net/http"
+    "os/signal"
+    "syscall"
+    "time"
+
+    "github.com/ryuzxy/FuncPro/db"
+    "github.com/ryuzxy/FuncPro/internal/telemetry"
+    "github.com/ryuzxy/FuncPro/router"
+)
+
+func runServe(args []string) error {
+    fs, loadConfig := newFlagSet("serve", "")
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    // Load configuration
+    cfg, err := loadConfig()
+    if err != nil {
+        return err
+    }
+
+    // Tracing, flushed after the server and workers have stopped
+    shutdownTracing, err := telemetry.Setup(context.Background(), cfg.Tracing)
+    if err != nil {
+        return err
+    }
+    defer func() {
+        ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+        defer cancel()
+        if err := shutdownTracing(ctx); err != nil {
+            slog.Warn("flushing traces", "error", err)
+        }
+    }()
+
+    // Initialize database
+    database, err := db.InitDB(cfg)
+    if err != nil {
+        return err
+    }
+    sqlDB, err := database.DB()
+    if err != nil {
+        return err
+    }
+    defer sqlDB.Close()
+
+    // Setup router
+    r, background, err := router.SetupRouter(database, cfg)
+    if err != nil {
+        return err
+    }
+    defer background.Close()
+
+    srv := &http.Server{
+        Addr:              fmt.Sprintf(":%d", cfg.Server.Port),
+        Handler:           r,
+        ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+        ReadTimeout:       cfg.Server.ReadTimeout,
+        WriteTimeout:      cfg.Server.WriteTimeout,
+        IdleTimeout:       cfg.Server.IdleTimeout,
+    }
+    srv.RegisterOnShutdown(background.CloseStreams)
+
+    ln, err := net.Listen("tcp", srv.Addr)
+    if err != nil {
+        return err
+    }
+
+    // Start server
+    ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+    defer stop()
+    slog.Info("server starting", "port", cfg.Server.Port, "env", cfg.Env)
+    return serveUntil(ctx, srv, ln, cfg.Server.ShutdownTimeout)
+}
+
+// serveUntil serves on ln until ctx is done, then stops accepting
+// connections and gives in-flight requests up to timeout to finish. The
+// deferred closes in runServe stop the workers and the database after it.
+func serveUntil(ctx context.Context, srv *http.Server, ln net.Listener, timeout time.Duration) error {
+    served := make(chan error, 1)
+    go func() {
+        served <- srv.Serve(ln)
+    }()
+
+    select {
+    case err := <-served:
+        return err
+    case <-ctx.Done():
+    }
+
+    slog.Info("shutting down, draining in-flight requests", "timeout", timeout.String())
+    shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+    defer cancel()
+    if err := srv.Shutdown(shutdownCtx); err != nil {
+        srv.Close()
+        return fmt.Errorf("requests still running after %s were cut off: %w", timeout, err)
+    }
+    if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+        return err
+    }
+    slog.Info("server stopped")
+    return nil
+}
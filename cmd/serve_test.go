@@ -0,0 +1,83 @@
+package main
+
+import (
+    "context"
+    "io"
+    "net"
+    "net/http"
+    "testing"
+    "time"
+)
+
+// startSlow serves a handler that answers once release is closed, and
+// returns the server's URL and a channel reporting when serveUntil returns.
+func startSlow(t *testing.T, ctx context.Context, timeout time.Duration, started, release chan struct{}) (string, chan error) {
+    t.Helper()
+    ln, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        t.Fatal(err)
+    }
+    srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        close(started)
+        <-release
+        io.WriteString(w, "done")
+    })}
+    stopped := make(chan error, 1)
+    go func() {
+        stopped <- serveUntil(ctx, srv, ln, timeout)
+    }()
+    return "http://" + ln.Addr().String(), stopped
+}
+
+func TestServeUntilDrainsInFlightRequests(t *testing.T) {
+    ctx, cancel := context.WithCancel(context.Background())
+    started, release := make(chan struct{}), make(chan struct{})
+    url, stopped := startSlow(t, ctx, 5*time.Second, started, release)
+
+    body := make(chan string, 1)
+    go func() {
+        resp, err := http.Get(url)
+        if err != nil {
+            body <- err.Error()
+            return
+        }
+        defer resp.Body.Close()
+        b, _ := io.ReadAll(resp.Body)
+        body <- string(b)
+    }()
+
+    <-started
+    cancel()
+    select {
+    case err := <-stopped:
+        t.Fatalf("server stopped with a request in flight: %v", err)
+    case <-time.After(50 * time.Millisecond):
+    }
+
+    close(release)
+    if got := <-body; got != "done" {
+        t.Errorf("in-flight request got %q", got)
+    }
+    if err := <-stopped; err != nil {
+        t.Errorf("serveUntil = %v", err)
+    }
+}
+
+func TestServeUntilCutsOffAfterTimeout(t *testing.T) {
+    ctx, cancel := context.WithCancel(context.Background())
+    started, release := make(chan struct{}), make(chan struct{})
+    defer close(release)
+    url, stopped := startSlow(t, ctx, 20*time.Millisecond, started, release)
+
+    go http.Get(url)
+    <-started
+    cancel()
+    select {
+    case err := <-stopped:
+        if err == nil {
+            t.Error("serveUntil reported a clean stop with a request cut off")
+        }
+    case <-time.After(5 * time.Second):
+        t.Fatal("serveUntil ignored the shutdown timeout")
+    }
+}
@@ -1,37 +1,120 @@
 package db
 
 import (
+    "context"
     "fmt"
+    "strconv"
+    "strings"
+
     "gorm.io/driver/postgres"
     "gorm.io/gorm"
+    "gorm.io/plugin/opentelemetry/tracing"
+
     "github.com/ryuzxy/FuncPro/internal/config"
-    "github.com/ryuzxy/FuncPro/pkg/komoditas"
-    "github.com/ryuzxy/FuncPro/pkg/price"
 )
 
-// InitDB initializes database connection
-func InitDB(cfg *config.Config) (*gorm.DB, error) {
-    dsn := fmt.Sprintf(
-        "host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-        cfg.DBHost,
-        cfg.DBUser,
-        cfg.DBPassword,
-        cfg.DBName,
-        cfg.DBPort,
-    )
-    
-    db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+// Open connects to the database without looking at its schema, with the
+// pool sized and aged as configured.
+func Open(cfg *config.Config) (*gorm.DB, error) {
+    db, err := gorm.Open(postgres.Open(DSN(cfg.DB)), &gorm.Config{
+        Logger: newQueryLogger(cfg.DB.SlowQuery),
+    })
     if err != nil {
         return nil, fmt.Errorf("opening DB: %w", err)
     }
-    
-    // Auto migrate tables
-    if err := db.AutoMigrate(
-        &komoditas.Komoditas{},
-        &price.Price{},
-    ); err != nil {
-        return nil, fmt.Errorf("migrating DB: %w", err)
+
+    // A span per statement, without its bound values, under the span of the
+    // request that ran it. It records nothing until tracing is set up.
+    if err := db.Use(tracing.NewPlugin(tracing.WithoutMetrics(), tracing.WithoutQueryVariables())); err != nil {
+        return nil, fmt.Errorf("opening DB: %w", err)
+    }
+
+    sqlDB, err := db.DB()
+    if err != nil {
+        return nil, fmt.Errorf("opening DB: %w", err)
+    }
+    sqlDB.SetMaxOpenConns(cfg.DB.MaxOpenConns)
+    sqlDB.SetMaxIdleConns(cfg.DB.MaxIdleConns)
+    sqlDB.SetConnMaxLifetime(cfg.DB.ConnMaxLifetime)
+    sqlDB.SetConnMaxIdleTime(cfg.DB.ConnMaxIdleTime)
+    return db, nil
+}
+
+// DSN builds a libpq keyword/value connection string. statement_timeout is
+// sent as a run-time parameter, so it applies to every pooled connection.
+func DSN(cfg config.DBConfig) string {
+    params := []struct{ key, value string }{
+        {"host", cfg.Host},
+        {"port", strconv.Itoa(cfg.Port)},
+        {"user", cfg.User},
+        {"password", cfg.Password},
+        {"dbname", cfg.Name},
+        {"sslmode", cfg.SSLMode},
+        {"sslrootcert", cfg.SSLRootCert},
+        {"sslcert", cfg.SSLCert},
+        {"sslkey", cfg.SSLKey},
+        {"application_name", cfg.ApplicationName},
+        {"connect_timeout", strconv.Itoa(int(cfg.ConnectTimeout.Seconds()))},
+        {"statement_timeout", strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)},
+    }
+
+    var parts []string
+    for _, p := range params {
+        if p.value == "" {
+            continue
+        }
+        parts = append(parts, p.key+"="+quoteDSN(p.value))
+    }
+    return strings.Join(parts, " ")
+}
+
+// quoteDSN single-quotes a value when it holds spaces, quotes or
+// backslashes, which would otherwise end or corrupt it.
+func quoteDSN(value string) string {
+    if !strings.ContainsAny(value, ` '\`) {
+        return value
+    }
+    value = strings.ReplaceAll(value, `\`, `\\`)
+    value = strings.ReplaceAll(value, `'`, `\'`)
+    return "'" + value + "'"
+}
+
+// InitDB initializes database connection and checks that every migration
+// has been applied. It never changes the schema; run the migrate command
+// for that.
+func InitDB(cfg *config.Config) (*gorm.DB, error) {
+    db, err := Open(cfg)
+    if err != nil {
+        return nil, err
+    }
+    if err := CheckSchema(context.Background(), db); err != nil {
+        return nil, err
     }
-    
     return db, nil
-}
\ No newline at end of file
+}
+
+// CheckSchema fails when the database lacks migrations this build needs.
+func CheckSchema(ctx context.Context, db *gorm.DB) error {
+    migrator, err := NewMigrator(db)
+    if err != nil {
+        return err
+    }
+    current, err := migrator.Current(ctx)
+    if err != nil {
+        return fmt.Errorf("reading schema version: %w", err)
+    }
+    if current < migrator.Latest() {
+        return fmt.Errorf("database schema is at version %d, this build needs %d: run `migrate up` first",
+            current, migrator.Latest())
+    }
+    return nil
+}
+
+// Ping checks that the database answers.
+func Ping(ctx context.Context, db *gorm.DB) error {
+    sqlDB, err := db.DB()
+    if err != nil {
+        return err
+    }
+    return sqlDB.PingContext(ctx)
+}
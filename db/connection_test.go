@@ -0,0 +1,52 @@
+package db
+
+import (
+    "testing"
+    "time"
+
+    "github.com/jackc/pgx/v5/pgconn"
+
+    "github.com/ryuzxy/FuncPro/internal/config"
+)
+
+func TestDSN(t *testing.T) {
+    cfg := config.Default().DB
+    cfg.Password = `it's a \secret`
+    cfg.SSLMode = "require"
+    cfg.StatementTimeout = 1500 * time.Millisecond
+
+    // pgx reads the string the same way the driver will.
+    parsed, err := pgconn.ParseConfig(DSN(cfg))
+    if err != nil {
+        t.Fatalf("pgx rejected %q: %v", DSN(cfg), err)
+    }
+    if parsed.Password != cfg.Password {
+        t.Errorf("password = %q, want %q", parsed.Password, cfg.Password)
+    }
+    if parsed.Host != cfg.Host || parsed.Port != uint16(cfg.Port) || parsed.Database != cfg.Name {
+        t.Errorf("connection target = %s:%d/%s", parsed.Host, parsed.Port, parsed.Database)
+    }
+    if parsed.ConnectTimeout != cfg.ConnectTimeout {
+        t.Errorf("connect timeout = %s, want %s", parsed.ConnectTimeout, cfg.ConnectTimeout)
+    }
+    want := map[string]string{"statement_timeout": "1500", "application_name": "funcpro"}
+    for key, value := range want {
+        if got := parsed.RuntimeParams[key]; got != value {
+            t.Errorf("%s = %q, want %q", key, got, value)
+        }
+    }
+}
+
+func TestDSNSkipsEmptySettings(t *testing.T) {
+    cfg := config.Default().DB
+    cfg.Password = ""
+    cfg.SSLMode = "verify-ca"
+    cfg.SSLRootCert = "/etc/ssl/ca.pem"
+    cfg.StatementTimeout = 0
+    got := DSN(cfg)
+    want := "host=localhost port=5432 user=postgres dbname=FUNCPRO sslmode=verify-ca sslrootcert=/etc/ssl/ca.pem " +
+        "application_name=funcpro connect_timeout=10 statement_timeout=0"
+    if got != want {
+        t.Errorf("DSN = %q\nwant  %q", got, want)
+    }
+}
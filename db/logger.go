@@ -0,0 +1,66 @@
+package db
+
+import (
+    "context"
+    "errors"
+    "log/slog"
+    "time"
+
+    "gorm.io/gorm"
+    "gorm.io/gorm/logger"
+)
+
+// queryLogger sends gorm's output to slog, so a failed or slow query is
+// logged with the request id of the context the repository was called with.
+type queryLogger struct {
+    slow time.Duration
+}
+
+func newQueryLogger(slow time.Duration) logger.Interface {
+    return queryLogger{slow: slow}
+}
+
+// LogMode is a no-op: the slog level decides what is written.
+func (l queryLogger) LogMode(logger.LogLevel) logger.Interface {
+    return l
+}
+
+func (l queryLogger) Info(ctx context.Context, msg string, args ...any) {
+    slog.InfoContext(ctx, msg, "args", args)
+}
+
+func (l queryLogger) Warn(ctx context.Context, msg string, args ...any) {
+    slog.WarnContext(ctx, msg, "args", args)
+}
+
+func (l queryLogger) Error(ctx context.Context, msg string, args ...any) {
+    slog.ErrorContext(ctx, msg, "args", args)
+}
+
+// Trace logs every query at debug level, slow ones as warnings and failed
+// ones as errors. A missing record is an answer, not a failure.
+func (l queryLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+    elapsed := time.Since(begin)
+    level := slog.LevelDebug
+    msg := "query"
+    switch {
+    case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && !errors.Is(err, context.Canceled):
+        level, msg = slog.LevelError, "query failed"
+    case l.slow > 0 && elapsed > l.slow:
+        level, msg = slog.LevelWarn, "slow query"
+    }
+    if !slog.Default().Enabled(ctx, level) {
+        return
+    }
+
+    sql, rows := fc()
+    attrs := []slog.Attr{
+        slog.String("sql", sql),
+        slog.Int64("rows", rows),
+        slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+    }
+    if err != nil {
+        attrs = append(attrs, slog.String("error", err.Error()))
+    }
+    slog.LogAttrs(ctx, level, msg, attrs...)
+}
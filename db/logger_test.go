@@ -0,0 +1,54 @@
+package db
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "log/slog"
+    "strings"
+    "testing"
+    "time"
+
+    "gorm.io/gorm"
+
+    "github.com/ryuzxy/FuncPro/internal/logging"
+)
+
+func TestQueryLogger(t *testing.T) {
+    var buf bytes.Buffer
+    defer slog.SetDefault(slog.Default())
+    slog.SetDefault(logging.New(&buf, slog.LevelInfo, "json"))
+
+    ctx := logging.WithRequestID(context.Background(), "req-1")
+    l := newQueryLogger(100 * time.Millisecond)
+    query := func() (string, int64) { return `SELECT * FROM "prices"`, 0 }
+    now := time.Now()
+
+    tests := []struct {
+        name  string
+        begin time.Time
+        err   error
+        want  string
+    }{
+        {"fast query", now, nil, ""},
+        {"missing record", now, gorm.ErrRecordNotFound, ""},
+        {"failed query", now, errors.New("relation does not exist"), `"msg":"query failed"`},
+        {"slow query", now.Add(-time.Second), nil, `"msg":"slow query"`},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            buf.Reset()
+            l.Trace(ctx, tt.begin, query, tt.err)
+            got := buf.String()
+            if tt.want == "" {
+                if got != "" {
+                    t.Errorf("logged %s", got)
+                }
+                return
+            }
+            if !strings.Contains(got, tt.want) || !strings.Contains(got, `"request_id":"req-1"`) {
+                t.Errorf("log = %s", got)
+            }
+        })
+    }
+}
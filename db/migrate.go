@@ -0,0 +1,262 @@
+package db
+
+import (
+    "context"
+    "embed"
+    "fmt"
+    "io/fs"
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    "gorm.io/gorm"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLock is the advisory lock key held while migrating, so replicas
+// started together apply each migration once.
+const migrationLock = 7_461_726_935
+
+var migrationName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is one versioned schema change with the SQL to apply and to
+// revert it.
+type Migration struct {
+    Version int64
+    Name    string
+    Up      string
+    Down    string
+}
+
+// MigrationStatus reports whether a migration has been applied.
+type MigrationStatus struct {
+    Migration
+    AppliedAt *time.Time
+}
+
+// Migrator applies the embedded migrations, recording each one in the
+// schema_migrations table. Every migration runs in its own transaction.
+type Migrator struct {
+    db         *gorm.DB
+    migrations []Migration
+}
+
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+    migrations, err := loadMigrations(migrationFiles)
+    if err != nil {
+        return nil, err
+    }
+    return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// loadMigrations reads NNNN_name.up.sql and NNNN_name.down.sql pairs, in
+// version order.
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+    paths, err := fs.Glob(fsys, "migrations/*.sql")
+    if err != nil {
+        return nil, err
+    }
+
+    byVersion := make(map[int64]*Migration)
+    for _, path := range paths {
+        base := path[strings.LastIndex(path, "/")+1:]
+        parts := migrationName.FindStringSubmatch(base)
+        if parts == nil {
+            return nil, fmt.Errorf("migration file %s is not named NNNN_name.up.sql or NNNN_name.down.sql", base)
+        }
+        version, _ := strconv.ParseInt(parts[1], 10, 64)
+        body, err := fs.ReadFile(fsys, path)
+        if err != nil {
+            return nil, err
+        }
+
+        m := byVersion[version]
+        if m == nil {
+            m = &Migration{Version: version, Name: parts[2]}
+            byVersion[version] = m
+        }
+        if m.Name != parts[2] {
+            return nil, fmt.Errorf("migration %d is named both %s and %s", version, m.Name, parts[2])
+        }
+        if parts[3] == "up" {
+            m.Up = string(body)
+        } else {
+            m.Down = string(body)
+        }
+    }
+
+    migrations := make([]Migration, 0, len(byVersion))
+    for _, m := range byVersion {
+        if strings.TrimSpace(m.Up) == "" {
+            return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+        }
+        migrations = append(migrations, *m)
+    }
+    sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+    return migrations, nil
+}
+
+// Latest is the version the embedded migrations bring the schema to.
+func (m *Migrator) Latest() int64 {
+    if len(m.migrations) == 0 {
+        return 0
+    }
+    return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up applies every pending migration and returns the ones it applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+    var applied []Migration
+    err := m.locked(ctx, func(conn *gorm.DB) error {
+        done, err := appliedVersions(conn)
+        if err != nil {
+            return err
+        }
+        for _, migration := range m.migrations {
+            if _, ok := done[migration.Version]; ok {
+                continue
+            }
+            err := conn.Transaction(func(tx *gorm.DB) error {
+                if err := tx.Exec(migration.Up).Error; err != nil {
+                    return err
+                }
+                return tx.Exec(`INSERT INTO schema_migrations (version, name) VALUES (?, ?)`,
+                    migration.Version, migration.Name).Error
+            })
+            if err != nil {
+                return fmt.Errorf("applying migration %d_%s: %w", migration.Version, migration.Name, err)
+            }
+            applied = append(applied, migration)
+        }
+        return nil
+    })
+    return applied, err
+}
+
+// Down reverts the last steps applied migrations, newest first, and returns
+// the ones it reverted.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+    var reverted []Migration
+    err := m.locked(ctx, func(conn *gorm.DB) error {
+        done, err := appliedVersions(conn)
+        if err != nil {
+            return err
+        }
+        for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+            migration := m.migrations[i]
+            if _, ok := done[migration.Version]; !ok {
+                continue
+            }
+            err := conn.Transaction(func(tx *gorm.DB) error {
+                if hasStatements(migration.Down) {
+                    if err := tx.Exec(migration.Down).Error; err != nil {
+                        return err
+                    }
+                }
+                return tx.Exec(`DELETE FROM schema_migrations WHERE version = ?`, migration.Version).Error
+            })
+            if err != nil {
+                return fmt.Errorf("reverting migration %d_%s: %w", migration.Version, migration.Name, err)
+            }
+            reverted = append(reverted, migration)
+        }
+        return nil
+    })
+    return reverted, err
+}
+
+// Status lists every embedded migration with the time it was applied, if
+// it was.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+    var statuses []MigrationStatus
+    err := m.locked(ctx, func(conn *gorm.DB) error {
+        done, err := appliedVersions(conn)
+        if err != nil {
+            return err
+        }
+        for _, migration := range m.migrations {
+            status := MigrationStatus{Migration: migration}
+            if at, ok := done[migration.Version]; ok {
+                status.AppliedAt = &at
+            }
+            statuses = append(statuses, status)
+        }
+        return nil
+    })
+    return statuses, err
+}
+
+// Current is the newest applied version, 0 on an empty database. It reads
+// without taking the lock, so servers can check it while a migration runs.
+func (m *Migrator) Current(ctx context.Context) (int64, error) {
+    var exists bool
+    err := m.db.WithContext(ctx).Raw(`SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists).Error
+    if err != nil || !exists {
+        return 0, err
+    }
+    var version int64
+    err = m.db.WithContext(ctx).Raw(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version).Error
+    return version, err
+}
+
+// locked runs fn on a single connection holding the migration lock, after
+// making sure the schema_migrations table exists.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *gorm.DB) error) error {
+    return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+        // The pool's statement_timeout is sized for requests; waiting on the
+        // lock or rebuilding an index may rightly take longer. RESET puts the
+        // connection's own setting back before it returns to the pool.
+        if err := conn.Exec(`SET statement_timeout = 0`).Error; err != nil {
+            return fmt.Errorf("disabling statement timeout: %w", err)
+        }
+        defer conn.WithContext(context.WithoutCancel(ctx)).Exec(`RESET statement_timeout`)
+
+        if err := conn.Exec(`SELECT pg_advisory_lock(?)`, migrationLock).Error; err != nil {
+            return fmt.Errorf("taking migration lock: %w", err)
+        }
+        // Unlock with a fresh context so a cancelled migration still
+        // releases the lock before the connection returns to the pool.
+        defer conn.WithContext(context.WithoutCancel(ctx)).Exec(`SELECT pg_advisory_unlock(?)`, migrationLock)
+
+        err := conn.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+            version    bigint PRIMARY KEY,
+            name       text NOT NULL,
+            applied_at timestamptz NOT NULL DEFAULT now()
+        )`).Error
+        if err != nil {
+            return fmt.Errorf("creating schema_migrations: %w", err)
+        }
+        return fn(conn)
+    })
+}
+
+func appliedVersions(conn *gorm.DB) (map[int64]time.Time, error) {
+    var rows []struct {
+        Version   int64
+        AppliedAt time.Time
+    }
+    if err := conn.Raw(`SELECT version, applied_at FROM schema_migrations`).Scan(&rows).Error; err != nil {
+        return nil, fmt.Errorf("reading schema_migrations: %w", err)
+    }
+    done := make(map[int64]time.Time, len(rows))
+    for _, row := range rows {
+        done[row.Version] = row.AppliedAt
+    }
+    return done, nil
+}
+
+// hasStatements reports whether sql holds anything besides comments, as a
+// down migration may only explain why it cannot undo anything.
+func hasStatements(sql string) bool {
+    for _, line := range strings.Split(sql, "\n") {
+        line = strings.TrimSpace(line)
+        if line != "" && !strings.HasPrefix(line, "--") {
+            return true
+        }
+    }
+    return false
+}
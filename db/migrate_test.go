@@ -0,0 +1,116 @@
+package db
+
+import (
+    "regexp"
+    "strings"
+    "sync"
+    "testing"
+    "testing/fstest"
+
+    "gorm.io/gorm/schema"
+
+    "github.com/ryuzxy/FuncPro/pkg/alert"
+    "github.com/ryuzxy/FuncPro/pkg/auth"
+    "github.com/ryuzxy/FuncPro/pkg/basket"
+    "github.com/ryuzxy/FuncPro/pkg/currency"
+    "github.com/ryuzxy/FuncPro/pkg/inflation"
+    "github.com/ryuzxy/FuncPro/pkg/komoditas"
+    "github.com/ryuzxy/FuncPro/pkg/market"
+    "github.com/ryuzxy/FuncPro/pkg/price"
+)
+
+func TestLoadMigrations(t *testing.T) {
+    migrations, err := loadMigrations(migrationFiles)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for i, m := range migrations {
+        if m.Version != int64(i+1) {
+            t.Errorf("migration %d has version %d, want consecutive versions", i, m.Version)
+        }
+        if !hasStatements(m.Up) || m.Down == "" {
+            t.Errorf("migration %d_%s lacks an up or down file", m.Version, m.Name)
+        }
+    }
+
+    tests := []struct {
+        name  string
+        files fstest.MapFS
+    }{
+        {"bad name", fstest.MapFS{"migrations/1-init.up.sql": {Data: []byte("SELECT 1;")}}},
+        {"down only", fstest.MapFS{"migrations/0001_init.down.sql": {Data: []byte("SELECT 1;")}}},
+        {"two names", fstest.MapFS{
+            "migrations/0001_init.up.sql":  {Data: []byte("SELECT 1;")},
+            "migrations/0001_other.up.sql": {Data: []byte("SELECT 1;")},
+        }},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if _, err := loadMigrations(tt.files); err == nil {
+                t.Error("loaded an invalid migration set")
+            }
+        })
+    }
+}
+
+func TestHasStatements(t *testing.T) {
+    if hasStatements("-- nothing to undo\n\n  -- really\n") {
+        t.Error("comments counted as statements")
+    }
+    if !hasStatements("-- drop it\nDROP TABLE x;") {
+        t.Error("statement missed")
+    }
+}
+
+var (
+    createTable = regexp.MustCompile(`(?s)CREATE TABLE IF NOT EXISTS (\w+) \((.*?)\n\);`)
+    addColumn   = regexp.MustCompile(`ALTER TABLE (\w+) ADD COLUMN IF NOT EXISTS (\w+)`)
+    columnLine  = regexp.MustCompile(`(?m)^\s+(\w+)\s`)
+)
+
+// The migrations must keep up with the models, or a new database would
+// lack columns the code reads and writes.
+func TestMigrationsCoverModels(t *testing.T) {
+    migrations, err := loadMigrations(migrationFiles)
+    if err != nil {
+        t.Fatal(err)
+    }
+    var schemaSQL strings.Builder
+    for _, m := range migrations {
+        schemaSQL.WriteString(m.Up)
+    }
+    all := schemaSQL.String()
+
+    columns := make(map[string]map[string]bool)
+    for _, m := range createTable.FindAllStringSubmatch(all, -1) {
+        columns[m[1]] = make(map[string]bool)
+        for _, c := range columnLine.FindAllStringSubmatch(m[2], -1) {
+            columns[m[1]][c[1]] = true
+        }
+    }
+    for _, m := range addColumn.FindAllStringSubmatch(all, -1) {
+        columns[m[1]][m[2]] = true
+    }
+
+    models := []any{
+        &komoditas.Komoditas{}, &market.Market{}, &currency.ExchangeRate{}, &inflation.PriceIndex{},
+        &price.Price{}, &basket.Basket{}, &basket.BasketItem{}, &alert.AlertRule{}, &alert.AlertDelivery{},
+        &auth.APIKey{},
+    }
+    for _, model := range models {
+        s, err := schema.Parse(model, &sync.Map{}, schema.NamingStrategy{})
+        if err != nil {
+            t.Fatal(err)
+        }
+        table, ok := columns[s.Table]
+        if !ok {
+            t.Errorf("no migration creates %s", s.Table)
+            continue
+        }
+        for _, f := range s.Fields {
+            if f.DBName != "" && !table[f.DBName] {
+                t.Errorf("migrations lack %s.%s", s.Table, f.DBName)
+            }
+        }
+    }
+}
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS alert_deliveries;
+DROP TABLE IF EXISTS alert_rules;
+DROP TABLE IF EXISTS basket_items;
+DROP TABLE IF EXISTS baskets;
+DROP TABLE IF EXISTS prices;
+DROP TABLE IF EXISTS price_indices;
+DROP TABLE IF EXISTS exchange_rates;
+DROP TABLE IF EXISTS markets;
+DROP TABLE IF EXISTS komoditas;
@@ -0,0 +1,148 @@
+-- Baseline schema. Every statement is guarded so the migration also brings
+-- a database that was set up by AutoMigrate up to date: tables that exist
+-- are kept, and columns added since the first release are filled in.
+
+CREATE TABLE IF NOT EXISTS komoditas (
+    id         bigserial PRIMARY KEY,
+    name       varchar(100) NOT NULL,
+    type       varchar(50) NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz
+);
+ALTER TABLE komoditas ADD COLUMN IF NOT EXISTS base_unit varchar(20) NOT NULL DEFAULT 'kg';
+ALTER TABLE komoditas ADD COLUMN IF NOT EXISTS piece_weight_grams bigint;
+CREATE INDEX IF NOT EXISTS idx_komoditas_deleted_at ON komoditas (deleted_at);
+
+CREATE TABLE IF NOT EXISTS markets (
+    id         bigserial PRIMARY KEY,
+    code       varchar(50) NOT NULL,
+    name       varchar(100) NOT NULL,
+    province   varchar(100),
+    regency    varchar(100),
+    latitude   decimal,
+    longitude  decimal,
+    type       varchar(20) NOT NULL DEFAULT 'retail',
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz
+);
+-- The first market code index also covered deleted markets, so a deleted
+-- market's code could never be used again.
+DROP INDEX IF EXISTS idx_markets_code;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_markets_code_active ON markets (code) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_markets_province ON markets (province);
+CREATE INDEX IF NOT EXISTS idx_markets_regency ON markets (regency);
+CREATE INDEX IF NOT EXISTS idx_markets_deleted_at ON markets (deleted_at);
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id            bigserial PRIMARY KEY,
+    from_currency varchar(3) NOT NULL,
+    to_currency   varchar(3) NOT NULL,
+    date          date NOT NULL,
+    rate          numeric(24,10) NOT NULL,
+    created_at    timestamptz,
+    updated_at    timestamptz,
+    deleted_at    timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_exchange_rate_pair_date ON exchange_rates (from_currency, to_currency, date);
+CREATE INDEX IF NOT EXISTS idx_exchange_rates_deleted_at ON exchange_rates (deleted_at);
+
+CREATE TABLE IF NOT EXISTS price_indices (
+    id         bigserial PRIMARY KEY,
+    period     date NOT NULL,
+    value      numeric(24,10) NOT NULL,
+    source     varchar(100),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_price_indices_period ON price_indices (period);
+CREATE INDEX IF NOT EXISTS idx_price_indices_deleted_at ON price_indices (deleted_at);
+
+CREATE TABLE IF NOT EXISTS prices (
+    id           bigserial PRIMARY KEY,
+    komoditas_id bigint NOT NULL,
+    value        numeric(18,4) NOT NULL,
+    date         date NOT NULL,
+    market       varchar(100),
+    created_at   timestamptz,
+    updated_at   timestamptz,
+    deleted_at   timestamptz
+);
+-- Prices were first stored as decimal(10,2). Widening keeps every stored
+-- value exact; a column that is already numeric(18,4) is not rewritten.
+ALTER TABLE prices ALTER COLUMN value TYPE numeric(18,4);
+ALTER TABLE prices ADD COLUMN IF NOT EXISTS reported_value numeric(18,4);
+ALTER TABLE prices ALTER COLUMN reported_value TYPE numeric(18,4);
+ALTER TABLE prices ADD COLUMN IF NOT EXISTS unit varchar(20);
+ALTER TABLE prices ADD COLUMN IF NOT EXISTS currency varchar(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE prices ADD COLUMN IF NOT EXISTS market_id bigint;
+CREATE INDEX IF NOT EXISTS idx_prices_komoditas_id ON prices (komoditas_id);
+CREATE INDEX IF NOT EXISTS idx_prices_market_id ON prices (market_id);
+CREATE INDEX IF NOT EXISTS idx_prices_deleted_at ON prices (deleted_at);
+
+CREATE TABLE IF NOT EXISTS baskets (
+    id          bigserial PRIMARY KEY,
+    name        varchar(100) NOT NULL,
+    description varchar(255),
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    deleted_at  timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_baskets_name ON baskets (name);
+CREATE INDEX IF NOT EXISTS idx_baskets_deleted_at ON baskets (deleted_at);
+
+CREATE TABLE IF NOT EXISTS basket_items (
+    id           bigserial PRIMARY KEY,
+    basket_id    bigint NOT NULL,
+    komoditas_id bigint NOT NULL,
+    quantity     numeric(18,4) NOT NULL,
+    created_at   timestamptz,
+    updated_at   timestamptz,
+    CONSTRAINT fk_baskets_items FOREIGN KEY (basket_id) REFERENCES baskets (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_basket_item ON basket_items (basket_id, komoditas_id);
+
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id            bigserial PRIMARY KEY,
+    name          varchar(100) NOT NULL,
+    komoditas_id  bigint NOT NULL,
+    market_id     bigint,
+    condition     varchar(20) NOT NULL,
+    threshold     numeric(18,4),
+    percent       decimal,
+    days          bigint,
+    webhook_url   varchar(500) NOT NULL,
+    secret        varchar(100) NOT NULL,
+    active        boolean NOT NULL DEFAULT true,
+    firing        boolean NOT NULL DEFAULT false,
+    last_trend    varchar(20),
+    last_fired_at timestamptz,
+    created_at    timestamptz,
+    updated_at    timestamptz,
+    deleted_at    timestamptz
+);
+ALTER TABLE alert_rules ADD COLUMN IF NOT EXISTS currency varchar(3) NOT NULL DEFAULT 'IDR';
+CREATE INDEX IF NOT EXISTS idx_alert_rules_komoditas_id ON alert_rules (komoditas_id);
+CREATE INDEX IF NOT EXISTS idx_alert_rules_market_id ON alert_rules (market_id);
+CREATE INDEX IF NOT EXISTS idx_alert_rules_deleted_at ON alert_rules (deleted_at);
+
+CREATE TABLE IF NOT EXISTS alert_deliveries (
+    id            bigserial PRIMARY KEY,
+    rule_id       bigint NOT NULL,
+    price_id      bigint,
+    url           varchar(500) NOT NULL,
+    payload       text NOT NULL,
+    status        varchar(20) NOT NULL,
+    attempts      bigint NOT NULL DEFAULT 0,
+    response_code bigint,
+    error         varchar(500),
+    delivered_at  timestamptz,
+    created_at    timestamptz,
+    updated_at    timestamptz
+);
+ALTER TABLE alert_deliveries ADD COLUMN IF NOT EXISTS next_attempt_at timestamptz;
+CREATE INDEX IF NOT EXISTS idx_alert_deliveries_rule_id ON alert_deliveries (rule_id);
+CREATE INDEX IF NOT EXISTS idx_alert_deliveries_status ON alert_deliveries (status);
+CREATE INDEX IF NOT EXISTS idx_alert_deliveries_next_attempt_at ON alert_deliveries (next_attempt_at);
@@ -0,0 +1,2 @@
+-- The backfills and purges cannot be undone; rolling back only forgets that
+-- they ran.
@@ -0,0 +1,42 @@
+-- Data fixes that used to run on every boot. They only change rows written
+-- before the features they cover, so on a new database they do nothing.
+
+-- Map the free-text prices.market values onto markets. The code is derived
+-- as market.CodeFromName does: collapse whitespace, upper-case, and turn
+-- every run of other characters into a dash.
+CREATE TEMPORARY TABLE legacy_markets ON COMMIT DROP AS
+SELECT DISTINCT market AS raw,
+       regexp_replace(btrim(market), '\s+', ' ', 'g') AS name,
+       btrim(regexp_replace(upper(regexp_replace(btrim(market), '\s+', ' ', 'g')), '[^A-Z0-9]+', '-', 'g'), '-') AS code
+FROM prices
+WHERE market_id IS NULL AND btrim(market) <> '';
+
+INSERT INTO markets (code, name, type, created_at, updated_at)
+SELECT DISTINCT ON (code) code, name, 'retail', now(), now()
+FROM legacy_markets
+WHERE code <> ''
+ORDER BY code, name
+ON CONFLICT (code) WHERE deleted_at IS NULL DO NOTHING;
+
+UPDATE prices
+SET market_id = markets.id
+FROM legacy_markets, markets
+WHERE prices.market_id IS NULL
+  AND prices.market = legacy_markets.raw
+  AND markets.code = legacy_markets.code
+  AND markets.deleted_at IS NULL;
+
+-- Prices recorded before units existed were implicitly in the base unit of
+-- their komoditas.
+UPDATE prices
+SET unit = komoditas.base_unit, reported_value = prices.value
+FROM komoditas
+WHERE komoditas.id = prices.komoditas_id
+  AND (prices.unit IS NULL OR prices.unit = '');
+
+-- Exchange rates and baskets are deleted for good now. Rows soft-deleted
+-- before that still hold their pair and date, or their name, in the unique
+-- indexes.
+DELETE FROM exchange_rates WHERE deleted_at IS NOT NULL;
+DELETE FROM basket_items WHERE basket_id IN (SELECT id FROM baskets WHERE deleted_at IS NOT NULL);
+DELETE FROM baskets WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,16 @@
+-- API keys are stored as SHA-256 hashes; the prefix identifies a key in
+-- listings without revealing it.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           bigserial PRIMARY KEY,
+    name         varchar(100) NOT NULL,
+    prefix       varchar(16) NOT NULL,
+    hash         varchar(64) NOT NULL,
+    role         varchar(20) NOT NULL,
+    expires_at   timestamptz,
+    last_used_at timestamptz,
+    created_at   timestamptz,
+    updated_at   timestamptz,
+    deleted_at   timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_hash ON api_keys (hash);
+CREATE INDEX IF NOT EXISTS idx_api_keys_deleted_at ON api_keys (deleted_at);
@@ -1,44 +1,436 @@
 package config
 
 import (
+    "errors"
+    "flag"
+    "fmt"
+    "net/netip"
     "os"
-    "strconv"
+    "regexp"
+    "strings"
+    "time"
 )
 
+// Config is the whole service configuration. Load fills it in layers:
+// defaults, then a YAML or TOML file, then environment variables, then
+// command-line flags.
 type Config struct {
-    DBHost     string
-    DBPort     string
-    DBUser     string
-    DBPassword string
-    DBName     string
-    ServerPort string
-    Env        string
+    Env      string
+    Log      LogConfig
+    Tracing  TracingConfig
+    Server   ServerConfig
+    CORS     CORSConfig
+    Auth     AuthConfig
+    Limits   LimitsConfig
+    DB       DBConfig
+    Analysis AnalysisConfig
 }
 
-func Load() *Config {
+type LogConfig struct {
+    // Level is debug, info, warn or error; debug logs every SQL query.
+    Level string
+    // Format is json or text.
+    Format string
+}
+
+type TracingConfig struct {
+    // Exporter is none, otlp or stdout.
+    Exporter string
+    // Endpoint is the OTLP/HTTP collector as host:port; Insecure sends to
+    // it without TLS, as to a collector on the same host.
+    Endpoint    string
+    Insecure    bool
+    ServiceName string
+    // SampleRatio is the share of new traces recorded; requests whose
+    // caller sampled them are always recorded.
+    SampleRatio float64
+}
+
+type ServerConfig struct {
+    Port int
+    // ReadHeaderTimeout and ReadTimeout bound reading a request, including
+    // a bulk upload; WriteTimeout bounds handling it and writing the
+    // response. Price streams lift the write timeout for themselves.
+    ReadHeaderTimeout time.Duration
+    ReadTimeout       time.Duration
+    WriteTimeout      time.Duration
+    IdleTimeout       time.Duration
+    // ShutdownTimeout is how long in-flight requests may take to finish
+    // after SIGTERM before their connections are closed.
+    ShutdownTimeout time.Duration
+    // ReadyTimeout bounds each dependency check behind /readyz.
+    ReadyTimeout time.Duration
+    // TrustedProxies lists, comma-separated, the IPs and CIDRs of proxies
+    // whose X-Forwarded-For is believed. Empty trusts none, so the client
+    // IP used for rate limits is the connection's peer.
+    TrustedProxies string
+}
+
+type CORSConfig struct {
+    // AllowedOrigins lists, comma-separated, the browser origins allowed
+    // to call the API: exact ones such as https://app.example.com, every
+    // subdomain with https://*.example.com, or * for any. Empty allows
+    // none.
+    AllowedOrigins   string
+    AllowCredentials bool
+    // AllowedHeaders may be sent by pages; ExposedHeaders may be read.
+    AllowedHeaders string
+    ExposedHeaders string
+    // MaxAge is how long browsers cache a preflight answer.
+    MaxAge time.Duration
+}
+
+type AuthConfig struct {
+    // AnonymousRole is granted to requests without credentials: empty
+    // refuses them, viewer opens the read-only endpoints.
+    AnonymousRole string
+    // JWTSecret verifies HS256 bearer tokens, JWKSFile holds the public
+    // keys for RS256 ones. With neither, only API keys are accepted.
+    JWTSecret string
+    JWKSFile  string
+    // JWTIssuer and JWTAudience, when set, must match the iss and aud
+    // claims. RoleClaim names the claim holding the role.
+    JWTIssuer   string
+    JWTAudience string
+    RoleClaim   string
+}
+
+type LimitsConfig struct {
+    // MaxBodyBytes refuses larger request bodies with 413, 0 never does.
+    MaxBodyBytes int
+    // MaxBulkItems caps the prices of one bulk request, 0 never does.
+    MaxBulkItems int
+    // Read, Analysis and Write limit each client per route group: plain
+    // reads, the computed analyses, and every request that changes data.
+    Read     RateLimit
+    Analysis RateLimit
+    Write    RateLimit
+}
+
+// RateLimit is a token bucket refilled at PerMinute requests a minute and
+// holding up to Burst. PerMinute 0 turns the limit off.
+type RateLimit struct {
+    PerMinute int
+    Burst     int
+}
+
+type DBConfig struct {
+    Host     string
+    Port     int
+    User     string
+    Password string
+    Name     string
+    // SSLMode is the libpq sslmode. SSLRootCert verifies the server for
+    // verify-ca and verify-full; SSLCert and SSLKey, set together, are a
+    // client certificate.
+    SSLMode     string
+    SSLRootCert string
+    SSLCert     string
+    SSLKey      string
+    // ApplicationName shows up in pg_stat_activity.
+    ApplicationName string
+    // ConnectTimeout bounds establishing a connection; libpq takes whole
+    // seconds, 0 waits forever.
+    ConnectTimeout time.Duration
+    // StatementTimeout cancels queries running longer, 0 never does.
+    StatementTimeout time.Duration
+    // SlowQuery logs queries taking longer as warnings, 0 never does.
+    SlowQuery time.Duration
+    // MaxOpenConns of 0 leaves the pool unbounded.
+    MaxOpenConns    int
+    MaxIdleConns    int
+    ConnMaxLifetime time.Duration
+    ConnMaxIdleTime time.Duration
+}
+
+type AnalysisConfig struct {
+    // WindowDays is how far back price analyses and alert trends look, and
+    // the default range of series and disparity queries.
+    WindowDays int
+    // MaxSeriesDays caps the from-to span of a daily series, as each day
+    // becomes a point whether or not a price was reported.
+    MaxSeriesDays int
+}
+
+const EnvProduction = "production"
+
+func Default() *Config {
     return &Config{
-        DBHost:     getEnv("DB_HOST", "localhost"),
-        DBPort:     getEnv("DB_PORT", "5432"),
-        DBUser:     getEnv("DB_USER", "postgres"),
-        DBPassword: getEnv("DB_PASSWORD", "Ryuxy27."),
-        DBName:     getEnv("DB_NAME", "FUNCPRO"),
-        ServerPort: getEnv("SERVER_PORT", "8080"),
-        Env:        getEnv("ENV", "development"),
+        Env: "development",
+        Log: LogConfig{Level: "info", Format: "json"},
+        Tracing: TracingConfig{
+            Exporter:    "none",
+            Endpoint:    "localhost:4318",
+            Insecure:    true,
+            ServiceName: "funcpro",
+            SampleRatio: 1,
+        },
+        Server: ServerConfig{
+            Port:              8080,
+            ReadHeaderTimeout: 10 * time.Second,
+            ReadTimeout:       time.Minute,
+            WriteTimeout:      2 * time.Minute,
+            IdleTimeout:       2 * time.Minute,
+            ShutdownTimeout:   30 * time.Second,
+            ReadyTimeout:      2 * time.Second,
+        },
+        CORS: CORSConfig{
+            AllowedHeaders: "Content-Type, Authorization, X-API-Key, X-Request-ID",
+            ExposedHeaders: "X-Request-ID, X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset, Retry-After",
+            MaxAge:         10 * time.Minute,
+        },
+        Auth: AuthConfig{RoleClaim: "role"},
+        Limits: LimitsConfig{
+            MaxBodyBytes: 8 << 20,
+            MaxBulkItems: 1000,
+            Read:         RateLimit{PerMinute: 600, Burst: 100},
+            Analysis:     RateLimit{PerMinute: 60, Burst: 10},
+            Write:        RateLimit{PerMinute: 120, Burst: 30},
+        },
+        DB: DBConfig{
+            Host:             "localhost",
+            Port:             5432,
+            User:             "postgres",
+            Name:             "FUNCPRO",
+            SSLMode:          "disable",
+            ApplicationName:  "funcpro",
+            ConnectTimeout:   10 * time.Second,
+            StatementTimeout: 30 * time.Second,
+            SlowQuery:        200 * time.Millisecond,
+            MaxOpenConns:     25,
+            MaxIdleConns:     5,
+            ConnMaxLifetime:  30 * time.Minute,
+            ConnMaxIdleTime:  5 * time.Minute,
+        },
+        Analysis: AnalysisConfig{WindowDays: 30, MaxSeriesDays: 1830},
+    }
+}
+
+// Load reads the configuration without command-line flags: defaults, the
+// file named by CONFIG_FILE, then the environment.
+func Load() (*Config, error) {
+    return load(os.Getenv("CONFIG_FILE"), nil)
+}
+
+// Flags registers -config and a flag for every setting except secrets on
+// fs. The returned function loads the configuration once fs is parsed;
+// only flags given on the command line override the other layers.
+func Flags(fs *flag.FlagSet) func() (*Config, error) {
+    path := fs.String("config", "", "YAML or TOML config file (env CONFIG_FILE)")
+    values := make(map[string]*string)
+    for _, s := range settings {
+        if s.secret {
+            continue
+        }
+        values[s.flag] = fs.String(s.flag, "", fmt.Sprintf("%s (env %s)", s.usage, s.env))
+    }
+
+    return func() (*Config, error) {
+        given := make(map[string]string)
+        fs.Visit(func(f *flag.Flag) {
+            if v, ok := values[f.Name]; ok {
+                given[f.Name] = *v
+            }
+        })
+        file := *path
+        if file == "" {
+            file = os.Getenv("CONFIG_FILE")
+        }
+        return load(file, given)
+    }
+}
+
+func load(path string, flags map[string]string) (*Config, error) {
+    cfg := Default()
+    var errs []error
+
+    if path != "" {
+        values, err := readFile(path)
+        if err != nil {
+            return nil, err
+        }
+        for key, raw := range values {
+            s, ok := byKey[key]
+            if !ok {
+                errs = append(errs, fmt.Errorf("%s: unknown setting %q", path, key))
+                continue
+            }
+            if err := s.apply(cfg, raw); err != nil {
+                errs = append(errs, fmt.Errorf("%s: %s: %w", path, key, err))
+            }
+        }
+    }
+
+    for _, s := range settings {
+        if raw, ok := os.LookupEnv(s.env); ok && raw != "" {
+            if err := s.apply(cfg, raw); err != nil {
+                errs = append(errs, fmt.Errorf("env %s: %w", s.env, err))
+            }
+        }
+    }
+
+    for _, s := range settings {
+        if raw, ok := flags[s.flag]; ok {
+            if err := s.apply(cfg, raw); err != nil {
+                errs = append(errs, fmt.Errorf("flag -%s: %w", s.flag, err))
+            }
+        }
+    }
+
+    if len(errs) > 0 {
+        return nil, errors.Join(errs...)
+    }
+    if err := cfg.Validate(); err != nil {
+        return nil, err
+    }
+    return cfg, nil
+}
+
+// defaultSecrets are passwords from examples and old defaults that must
+// never reach production.
+var defaultSecrets = []string{"", "password", "postgres", "secret", "secretpassword", "changeme", "Ryuxy27."}
+
+var (
+    logLevels        = []string{"debug", "info", "warn", "error"}
+    logFormats       = []string{"json", "text"}
+    tracingExporters = []string{"none", "otlp", "stdout"}
+)
+
+// corsOrigin is a scheme and host, with an optional port and an optional
+// leading "*." standing for any subdomain.
+var corsOrigin = regexp.MustCompile(`^https?://(\*\.)?[a-z0-9-]+(\.[a-z0-9-]+)*(:\d+)?$`)
+
+var anonymousRoles = []string{"", "viewer", "contributor"}
+
+// minJWTSecret is the shortest HS256 secret accepted, the size of the hash.
+const minJWTSecret = 32
+
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// Validate checks every setting and reports all problems at once.
+func (c *Config) Validate() error {
+    var errs []error
+    check := func(ok bool, format string, args ...any) {
+        if !ok {
+            errs = append(errs, fmt.Errorf(format, args...))
+        }
+    }
+
+    check(c.Env != "", "env must not be empty")
+    check(contains(logLevels, c.Log.Level), "log.level %q must be one of %s", c.Log.Level, strings.Join(logLevels, ", "))
+    check(contains(logFormats, c.Log.Format), "log.format %q must be one of %s", c.Log.Format, strings.Join(logFormats, ", "))
+    check(contains(tracingExporters, c.Tracing.Exporter),
+        "tracing.exporter %q must be one of %s", c.Tracing.Exporter, strings.Join(tracingExporters, ", "))
+    check(c.Tracing.Exporter != "otlp" || c.Tracing.Endpoint != "", "tracing.endpoint is required for the otlp exporter")
+    check(c.Tracing.ServiceName != "", "tracing.service_name must not be empty")
+    check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio %g must be between 0 and 1", c.Tracing.SampleRatio)
+
+    check(c.Server.Port > 0 && c.Server.Port < 65536, "server.port %d is not a TCP port", c.Server.Port)
+    check(c.Server.ReadHeaderTimeout > 0, "server.read_header_timeout must be > 0")
+    check(c.Server.ReadTimeout >= 0, "server.read_timeout must be >= 0")
+    check(c.Server.WriteTimeout >= 0, "server.write_timeout must be >= 0")
+    check(c.Server.IdleTimeout >= 0, "server.idle_timeout must be >= 0")
+    check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout must be > 0")
+    check(c.Server.ReadyTimeout > 0, "server.ready_timeout must be > 0")
+
+    for _, proxy := range SplitList(c.Server.TrustedProxies) {
+        check(validProxy(proxy), "server.trusted_proxies: %q is not an IP address or CIDR", proxy)
+    }
+
+    origins := SplitList(c.CORS.AllowedOrigins)
+    for _, origin := range origins {
+        check(origin == "*" || corsOrigin.MatchString(strings.ToLower(origin)),
+            "cors.allowed_origins: %q is not *, an origin such as https://app.example.com or a wildcard such as https://*.example.com", origin)
+    }
+    check(!c.CORS.AllowCredentials || !contains(origins, "*"),
+        "cors.allow_credentials cannot be combined with the * origin, list the origins instead")
+    check(c.CORS.MaxAge >= 0, "cors.max_age must be >= 0")
+
+    check(contains(anonymousRoles, c.Auth.AnonymousRole),
+        "auth.anonymous_role %q must be empty, viewer or contributor", c.Auth.AnonymousRole)
+    check(c.Auth.JWTSecret == "" || len(c.Auth.JWTSecret) >= minJWTSecret,
+        "auth.jwt_secret must be at least %d bytes", minJWTSecret)
+    check(c.Auth.JWTSecret == "" || strings.Trim(c.Auth.JWTSecret, c.Auth.JWTSecret[:1]) != "",
+        "auth.jwt_secret must not repeat a single character")
+    if c.Auth.JWKSFile != "" {
+        _, err := os.Stat(c.Auth.JWKSFile)
+        check(err == nil, "auth.jwks_file: %v", err)
+    }
+    check(c.Auth.RoleClaim != "", "auth.role_claim must not be empty")
+
+    check(c.Limits.MaxBodyBytes >= 0, "limits.max_body_bytes must be >= 0")
+    check(c.Limits.MaxBulkItems >= 0, "limits.max_bulk_items must be >= 0")
+    for _, limit := range []struct {
+        group string
+        RateLimit
+    }{{"read", c.Limits.Read}, {"analysis", c.Limits.Analysis}, {"write", c.Limits.Write}} {
+        check(limit.PerMinute >= 0, "limits.%s.per_minute must be >= 0", limit.group)
+        check(limit.PerMinute == 0 || limit.Burst >= 1, "limits.%s.burst must be >= 1", limit.group)
+    }
+
+    check(c.DB.Host != "", "db.host must not be empty")
+    check(c.DB.Port > 0 && c.DB.Port < 65536, "db.port %d is not a TCP port", c.DB.Port)
+    check(c.DB.User != "", "db.user must not be empty")
+    check(c.DB.Name != "", "db.name must not be empty")
+    check(contains(sslModes, c.DB.SSLMode), "db.sslmode %q must be one of %s", c.DB.SSLMode, strings.Join(sslModes, ", "))
+    check((c.DB.SSLCert == "") == (c.DB.SSLKey == ""), "db.sslcert and db.sslkey must be set together")
+    check(c.DB.SSLMode != "disable" || c.DB.SSLRootCert+c.DB.SSLCert == "",
+        "db.sslmode is disable, so db.sslrootcert and db.sslcert would be ignored")
+    for _, file := range []struct{ key, path string }{
+        {"db.sslrootcert", c.DB.SSLRootCert}, {"db.sslcert", c.DB.SSLCert}, {"db.sslkey", c.DB.SSLKey},
+    } {
+        if file.path != "" {
+            _, err := os.Stat(file.path)
+            check(err == nil, "%s: %v", file.key, err)
+        }
     }
+    check(c.DB.ConnectTimeout >= 0 && c.DB.ConnectTimeout%time.Second == 0,
+        "db.connect_timeout %s must be a non-negative whole number of seconds", c.DB.ConnectTimeout)
+    check(c.DB.MaxOpenConns >= 0, "db.max_open_conns must be >= 0")
+    check(c.DB.MaxIdleConns >= 0, "db.max_idle_conns must be >= 0")
+    check(c.DB.StatementTimeout >= 0, "db.statement_timeout must be >= 0")
+    check(c.DB.SlowQuery >= 0, "db.slow_query must be >= 0")
+    check(c.DB.ConnMaxLifetime >= 0, "db.conn_max_lifetime must be >= 0")
+    check(c.DB.ConnMaxIdleTime >= 0, "db.conn_max_idle_time must be >= 0")
+    check(c.DB.MaxOpenConns == 0 || c.DB.MaxIdleConns <= c.DB.MaxOpenConns,
+        "db.max_idle_conns %d exceeds db.max_open_conns %d", c.DB.MaxIdleConns, c.DB.MaxOpenConns)
+
+    check(c.Analysis.WindowDays >= 2 && c.Analysis.WindowDays <= 366,
+        "analysis.window_days %d must be between 2 and 366", c.Analysis.WindowDays)
+    check(c.Analysis.MaxSeriesDays >= c.Analysis.WindowDays,
+        "analysis.max_series_days %d must be at least analysis.window_days", c.Analysis.MaxSeriesDays)
+
+    if c.Env == EnvProduction {
+        check(!contains(defaultSecrets, c.DB.Password), "db.password is empty or a well-known default, refusing to run in production")
+    }
+
+    return errors.Join(errs...)
 }
 
-func getEnv(key, defaultValue string) string {
-    if value := os.Getenv(key); value != "" {
-        return value
+// SplitList reads a comma-separated setting, dropping empty items.
+func SplitList(s string) []string {
+    var items []string
+    for _, item := range strings.Split(s, ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            items = append(items, item)
+        }
     }
-    return defaultValue
+    return items
 }
 
-func getEnvInt(key string, defaultValue int) int {
-    if value := os.Getenv(key); value != "" {
-        if intValue, err := strconv.Atoi(value); err == nil {
-            return intValue
+func validProxy(s string) bool {
+    if _, err := netip.ParseAddr(s); err == nil {
+        return true
+    }
+    _, err := netip.ParsePrefix(s)
+    return err == nil
+}
+
+func contains(list []string, s string) bool {
+    for _, v := range list {
+        if v == s {
+            return true
         }
     }
-    return defaultValue
-}
\ No newline at end of file
+    return false
+}
@@ -0,0 +1,223 @@
+package config
+
+import (
+    "flag"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+)
+
+func writeFile(t *testing.T, name, body string) string {
+    t.Helper()
+    path := filepath.Join(t.TempDir(), name)
+    if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    return path
+}
+
+// clearEnv unsets every setting's variable for the test, so the developer's
+// environment cannot leak in.
+func clearEnv(t *testing.T) {
+    t.Helper()
+    for _, s := range append(settings, setting{env: "CONFIG_FILE"}) {
+        t.Setenv(s.env, "")
+    }
+}
+
+func TestLoadLayers(t *testing.T) {
+    clearEnv(t)
+    path := writeFile(t, "funcpro.yaml", `
+server:
+  port: 9000
+db:
+  host: db.internal
+  port: 6543
+  connect_timeout: 5s
+analysis:
+  window_days: 60
+`)
+    t.Setenv("DB_HOST", "db.env")
+    t.Setenv("ANALYSIS_WINDOW_DAYS", "90")
+
+    fs := flag.NewFlagSet("test", flag.ContinueOnError)
+    load := Flags(fs)
+    if err := fs.Parse([]string{"-config", path, "-analysis-window-days", "7"}); err != nil {
+        t.Fatal(err)
+    }
+    cfg, err := load()
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    if cfg.Server.Port != 9000 || cfg.DB.Port != 6543 || cfg.DB.ConnectTimeout != 5*time.Second {
+        t.Errorf("file values not applied: %+v", cfg)
+    }
+    if cfg.DB.Host != "db.env" {
+        t.Errorf("db.host = %q, want the environment to override the file", cfg.DB.Host)
+    }
+    if cfg.Analysis.WindowDays != 7 {
+        t.Errorf("analysis.window_days = %d, want the flag to override the environment", cfg.Analysis.WindowDays)
+    }
+    if cfg.DB.User != "postgres" || cfg.DB.MaxOpenConns != 25 {
+        t.Errorf("defaults lost: %+v", cfg.DB)
+    }
+}
+
+func TestLoadTOML(t *testing.T) {
+    clearEnv(t)
+    path := writeFile(t, "funcpro.toml", `
+env = "staging"
+
+[db]
+sslmode = "require"
+max_open_conns = 40
+`)
+    cfg, err := load(path, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if cfg.Env != "staging" || cfg.DB.SSLMode != "require" || cfg.DB.MaxOpenConns != 40 {
+        t.Errorf("toml values not applied: %+v", cfg)
+    }
+}
+
+func TestLoadReportsEveryError(t *testing.T) {
+    clearEnv(t)
+    path := writeFile(t, "funcpro.yaml", `
+db:
+  hots: typo
+  port: lots
+`)
+    t.Setenv("DB_CONNECT_TIMEOUT", "10")
+
+    _, err := load(path, map[string]string{"port": "http"})
+    if err == nil {
+        t.Fatal("loaded a broken config")
+    }
+    for _, want := range []string{`unknown setting "db.hots"`, "db.port", "DB_CONNECT_TIMEOUT", "-port"} {
+        if !strings.Contains(err.Error(), want) {
+            t.Errorf("error does not mention %s:\n%v", want, err)
+        }
+    }
+}
+
+func TestValidate(t *testing.T) {
+    tests := []struct {
+        name   string
+        modify func(c *Config)
+        want   []string
+    }{
+        {"defaults", func(c *Config) {}, nil},
+        {"several problems", func(c *Config) {
+            c.Server.Port = 0
+            c.DB.SSLMode = "on"
+            c.DB.MaxOpenConns = 2
+            c.Analysis.WindowDays = 1
+        }, []string{"server.port", "db.sslmode", "db.max_idle_conns", "analysis.window_days"}},
+        {"series cap below window", func(c *Config) { c.Analysis.MaxSeriesDays = 7 }, []string{"analysis.max_series_days"}},
+                {"fractional connect timeout", func(c *Config) { c.DB.ConnectTimeout = 1500 * time.Millisecond }, []string{"db.connect_timeout"}},
+        {"client cert without key", func(c *Config) {
+            c.DB.SSLMode = "verify-full"
+            c.DB.SSLCert = os.Args[0]
+        }, []string{"db.sslcert and db.sslkey"}},
+        {"certificates with sslmode disable", func(c *Config) { c.DB.SSLRootCert = os.Args[0] }, []string{"db.sslmode is disable"}},
+        {"missing CA file", func(c *Config) {
+            c.DB.SSLMode = "verify-ca"
+            c.DB.SSLRootCert = filepath.Join(os.TempDir(), "no-such-ca.pem")
+        }, []string{"db.sslrootcert"}},
+        {"verified TLS", func(c *Config) {
+            c.DB.SSLMode = "verify-full"
+            c.DB.SSLRootCert = os.Args[0]
+        }, nil},
+        {"negative pool ages", func(c *Config) {
+            c.DB.ConnMaxLifetime = -time.Second
+            c.DB.StatementTimeout = -time.Second
+        }, []string{"db.conn_max_lifetime", "db.statement_timeout"}},
+        {"anonymous admin and short JWT secret", func(c *Config) {
+            c.Auth.AnonymousRole = "admin"
+            c.Auth.JWTSecret = "secret"
+        }, []string{"auth.anonymous_role", "auth.jwt_secret"}},
+        {"JWT secret one byte short", func(c *Config) { c.Auth.JWTSecret = strings.Repeat("ab", 16)[:minJWTSecret-1] }, []string{"auth.jwt_secret must be at least"}},
+        {"JWT secret of one repeated character", func(c *Config) { c.Auth.JWTSecret = strings.Repeat("k", minJWTSecret) }, []string{"auth.jwt_secret must not repeat"}},
+                {"missing JWKS file", func(c *Config) { c.Auth.JWKSFile = filepath.Join(os.TempDir(), "no-such-jwks.json") }, []string{"auth.jwks_file"}},
+        {"read-only public access", func(c *Config) {
+            c.Auth.AnonymousRole = "viewer"
+            c.Auth.JWTSecret = "q3Vx9LmT2pRw7ZbK4nYc8HsJ1dGf6AeU"
+        }, nil},
+        {"bad proxies and rate limits", func(c *Config) {
+            c.Server.TrustedProxies = "10.0.0.0/8, proxy.internal"
+            c.Limits.Analysis.Burst = 0
+            c.Limits.MaxBulkItems = -1
+        }, []string{"server.trusted_proxies", "limits.analysis.burst", "limits.max_bulk_items"}},
+        {"limits off", func(c *Config) {
+            c.Server.TrustedProxies = "10.0.0.0/8,192.168.1.7, ::1"
+            c.Limits = LimitsConfig{}
+        }, nil},
+        {"bad origins", func(c *Config) {
+            c.CORS.AllowedOrigins = "https://app.example.com, app.example.com, https://*.example.com/path, http://a.*.example.com"
+        }, []string{`"app.example.com"`, `"https://*.example.com/path"`, `"http://a.*.example.com"`}},
+        {"credentials for any origin", func(c *Config) {
+            c.CORS.AllowedOrigins = "*"
+            c.CORS.AllowCredentials = true
+        }, []string{"cors.allow_credentials"}},
+        {"origin allowlist", func(c *Config) {
+            c.CORS.AllowedOrigins = "https://app.example.com,https://*.example.com,http://localhost:3000"
+            c.CORS.AllowCredentials = true
+        }, nil},
+        {"production without password", func(c *Config) { c.Env = EnvProduction }, []string{"db.password"}},
+        {"production with old default", func(c *Config) {
+            c.Env = EnvProduction
+            c.DB.Password = "Ryuxy27."
+        }, []string{"db.password"}},
+        {"production with real password", func(c *Config) {
+            c.Env = EnvProduction
+            c.DB.Password = "k3Q!v9-long-and-random"
+        }, nil},
+        {"development without password", func(c *Config) { c.DB.Password = "" }, nil},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            cfg := Default()
+            tt.modify(cfg)
+            err := cfg.Validate()
+            if len(tt.want) == 0 {
+                if err != nil {
+                    t.Errorf("unexpected error: %v", err)
+                }
+                return
+            }
+            if err == nil {
+                t.Fatal("expected an error")
+            }
+            for _, want := range tt.want {
+                if !strings.Contains(err.Error(), want) {
+                    t.Errorf("error does not mention %s:\n%v", want, err)
+                }
+            }
+        })
+    }
+}
+
+func TestFlagsSkipSecrets(t *testing.T) {
+    fs := flag.NewFlagSet("test", flag.ContinueOnError)
+    Flags(fs)
+    fs.VisitAll(func(f *flag.Flag) {
+        if strings.Contains(f.Name, "password") {
+            t.Errorf("secret exposed as flag -%s", f.Name)
+        }
+    })
+}
+
+func TestExampleFileLoads(t *testing.T) {
+    clearEnv(t)
+    cfg, err := load(filepath.Join("..", "..", "config.example.yaml"), nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if *cfg != *Default() {
+        t.Errorf("config.example.yaml differs from the defaults:\n%+v\n%+v", cfg, Default())
+    }
+}
@@ -0,0 +1,234 @@
+package config
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/goccy/go-yaml"
+    "github.com/pelletier/go-toml/v2"
+)
+
+// setting ties one configuration field to its file key, environment
+// variable and flag. Every layer goes through apply, so a value parses the
+// same wherever it comes from.
+type setting struct {
+    key    string
+    env    string
+    flag   string
+    usage  string
+    secret bool
+    field  func(c *Config) any
+}
+
+var settings = []setting{
+    {key: "env", env: "ENV", flag: "env", usage: "environment name; production enforces safe secrets",
+        field: func(c *Config) any { return &c.Env }},
+    {key: "log.level", env: "LOG_LEVEL", flag: "log-level", usage: "debug, info, warn or error",
+        field: func(c *Config) any { return &c.Log.Level }},
+    {key: "log.format", env: "LOG_FORMAT", flag: "log-format", usage: "json or text",
+        field: func(c *Config) any { return &c.Log.Format }},
+
+    {key: "tracing.exporter", env: "TRACING_EXPORTER", flag: "tracing-exporter", usage: "none, otlp or stdout",
+        field: func(c *Config) any { return &c.Tracing.Exporter }},
+    {key: "tracing.endpoint", env: "TRACING_ENDPOINT", flag: "tracing-endpoint", usage: "OTLP/HTTP collector host:port",
+        field: func(c *Config) any { return &c.Tracing.Endpoint }},
+    {key: "tracing.insecure", env: "TRACING_INSECURE", flag: "tracing-insecure", usage: "send to the collector without TLS",
+        field: func(c *Config) any { return &c.Tracing.Insecure }},
+    {key: "tracing.service_name", env: "TRACING_SERVICE_NAME", flag: "tracing-service-name", usage: "service.name of the spans",
+        field: func(c *Config) any { return &c.Tracing.ServiceName }},
+    {key: "tracing.sample_ratio", env: "TRACING_SAMPLE_RATIO", flag: "tracing-sample-ratio", usage: "share of new traces recorded, 0 to 1",
+        field: func(c *Config) any { return &c.Tracing.SampleRatio }},
+
+    {key: "server.port", env: "SERVER_PORT", flag: "port", usage: "HTTP port",
+        field: func(c *Config) any { return &c.Server.Port }},
+    {key: "server.read_header_timeout", env: "SERVER_READ_HEADER_TIMEOUT", flag: "read-header-timeout", usage: "time allowed to read request headers",
+        field: func(c *Config) any { return &c.Server.ReadHeaderTimeout }},
+    {key: "server.read_timeout", env: "SERVER_READ_TIMEOUT", flag: "read-timeout", usage: "time allowed to read a whole request, 0 for no limit",
+        field: func(c *Config) any { return &c.Server.ReadTimeout }},
+    {key: "server.write_timeout", env: "SERVER_WRITE_TIMEOUT", flag: "write-timeout", usage: "time allowed to handle a request and write the response, 0 for no limit",
+        field: func(c *Config) any { return &c.Server.WriteTimeout }},
+    {key: "server.idle_timeout", env: "SERVER_IDLE_TIMEOUT", flag: "idle-timeout", usage: "keep-alive connections idle longer are closed, 0 uses read_timeout",
+        field: func(c *Config) any { return &c.Server.IdleTimeout }},
+    {key: "server.shutdown_timeout", env: "SERVER_SHUTDOWN_TIMEOUT", flag: "shutdown-timeout", usage: "time in-flight requests get to finish on shutdown",
+        field: func(c *Config) any { return &c.Server.ShutdownTimeout }},
+    {key: "server.ready_timeout", env: "SERVER_READY_TIMEOUT", flag: "ready-timeout", usage: "time each /readyz dependency check may take",
+        field: func(c *Config) any { return &c.Server.ReadyTimeout }},
+    {key: "server.trusted_proxies", env: "SERVER_TRUSTED_PROXIES", flag: "trusted-proxies", usage: "comma-separated IPs and CIDRs of proxies whose X-Forwarded-For is believed",
+        field: func(c *Config) any { return &c.Server.TrustedProxies }},
+
+    {key: "cors.allowed_origins", env: "CORS_ALLOWED_ORIGINS", flag: "cors-allowed-origins", usage: "comma-separated browser origins allowed, e.g. https://*.example.com",
+        field: func(c *Config) any { return &c.CORS.AllowedOrigins }},
+    {key: "cors.allow_credentials", env: "CORS_ALLOW_CREDENTIALS", flag: "cors-allow-credentials", usage: "let allowed origins send cookies",
+        field: func(c *Config) any { return &c.CORS.AllowCredentials }},
+    {key: "cors.allowed_headers", env: "CORS_ALLOWED_HEADERS", flag: "cors-allowed-headers", usage: "comma-separated request headers pages may send",
+        field: func(c *Config) any { return &c.CORS.AllowedHeaders }},
+    {key: "cors.exposed_headers", env: "CORS_EXPOSED_HEADERS", flag: "cors-exposed-headers", usage: "comma-separated response headers pages may read",
+        field: func(c *Config) any { return &c.CORS.ExposedHeaders }},
+    {key: "cors.max_age", env: "CORS_MAX_AGE", flag: "cors-max-age", usage: "how long browsers cache preflight answers",
+        field: func(c *Config) any { return &c.CORS.MaxAge }},
+
+    {key: "auth.anonymous_role", env: "AUTH_ANONYMOUS_ROLE", flag: "auth-anonymous-role", usage: "role of requests without credentials: empty, viewer or contributor",
+        field: func(c *Config) any { return &c.Auth.AnonymousRole }},
+    {key: "auth.jwt_secret", env: "AUTH_JWT_SECRET", secret: true,
+        field: func(c *Config) any { return &c.Auth.JWTSecret }},
+    {key: "auth.jwks_file", env: "AUTH_JWKS_FILE", flag: "auth-jwks-file", usage: "JSON Web Key Set verifying RS256 bearer tokens",
+        field: func(c *Config) any { return &c.Auth.JWKSFile }},
+    {key: "auth.jwt_issuer", env: "AUTH_JWT_ISSUER", flag: "auth-jwt-issuer", usage: "required iss claim of bearer tokens",
+        field: func(c *Config) any { return &c.Auth.JWTIssuer }},
+    {key: "auth.jwt_audience", env: "AUTH_JWT_AUDIENCE", flag: "auth-jwt-audience", usage: "required aud claim of bearer tokens",
+        field: func(c *Config) any { return &c.Auth.JWTAudience }},
+    {key: "auth.role_claim", env: "AUTH_ROLE_CLAIM", flag: "auth-role-claim", usage: "bearer token claim holding the role",
+        field: func(c *Config) any { return &c.Auth.RoleClaim }},
+
+    {key: "limits.max_body_bytes", env: "LIMITS_MAX_BODY_BYTES", flag: "max-body-bytes", usage: "largest request body accepted, 0 for no limit",
+        field: func(c *Config) any { return &c.Limits.MaxBodyBytes }},
+    {key: "limits.max_bulk_items", env: "LIMITS_MAX_BULK_ITEMS", flag: "max-bulk-items", usage: "most prices in one bulk request, 0 for no limit",
+        field: func(c *Config) any { return &c.Limits.MaxBulkItems }},
+    {key: "limits.read.per_minute", env: "LIMITS_READ_PER_MINUTE", flag: "read-rate-limit", usage: "read requests per minute per client, 0 for no limit",
+        field: func(c *Config) any { return &c.Limits.Read.PerMinute }},
+    {key: "limits.read.burst", env: "LIMITS_READ_BURST", flag: "read-rate-burst", usage: "read requests per client allowed at once",
+        field: func(c *Config) any { return &c.Limits.Read.Burst }},
+    {key: "limits.analysis.per_minute", env: "LIMITS_ANALYSIS_PER_MINUTE", flag: "analysis-rate-limit", usage: "analysis requests per minute per client, 0 for no limit",
+        field: func(c *Config) any { return &c.Limits.Analysis.PerMinute }},
+    {key: "limits.analysis.burst", env: "LIMITS_ANALYSIS_BURST", flag: "analysis-rate-burst", usage: "analysis requests per client allowed at once",
+        field: func(c *Config) any { return &c.Limits.Analysis.Burst }},
+    {key: "limits.write.per_minute", env: "LIMITS_WRITE_PER_MINUTE", flag: "write-rate-limit", usage: "write requests per minute per client, 0 for no limit",
+        field: func(c *Config) any { return &c.Limits.Write.PerMinute }},
+    {key: "limits.write.burst", env: "LIMITS_WRITE_BURST", flag: "write-rate-burst", usage: "write requests per client allowed at once",
+        field: func(c *Config) any { return &c.Limits.Write.Burst }},
+
+    {key: "db.host", env: "DB_HOST", flag: "db-host", usage: "database host",
+        field: func(c *Config) any { return &c.DB.Host }},
+    {key: "db.port", env: "DB_PORT", flag: "db-port", usage: "database port",
+        field: func(c *Config) any { return &c.DB.Port }},
+    {key: "db.user", env: "DB_USER", flag: "db-user", usage: "database user",
+        field: func(c *Config) any { return &c.DB.User }},
+    {key: "db.password", env: "DB_PASSWORD", secret: true,
+        field: func(c *Config) any { return &c.DB.Password }},
+    {key: "db.name", env: "DB_NAME", flag: "db-name", usage: "database name",
+        field: func(c *Config) any { return &c.DB.Name }},
+    {key: "db.sslmode", env: "DB_SSLMODE", flag: "db-sslmode", usage: "libpq sslmode",
+        field: func(c *Config) any { return &c.DB.SSLMode }},
+    {key: "db.sslrootcert", env: "DB_SSLROOTCERT", flag: "db-sslrootcert", usage: "CA certificate the server is verified against",
+        field: func(c *Config) any { return &c.DB.SSLRootCert }},
+    {key: "db.sslcert", env: "DB_SSLCERT", flag: "db-sslcert", usage: "client certificate",
+        field: func(c *Config) any { return &c.DB.SSLCert }},
+    {key: "db.sslkey", env: "DB_SSLKEY", flag: "db-sslkey", usage: "client certificate key",
+        field: func(c *Config) any { return &c.DB.SSLKey }},
+    {key: "db.application_name", env: "DB_APPLICATION_NAME", flag: "db-application-name", usage: "application_name reported to the server",
+        field: func(c *Config) any { return &c.DB.ApplicationName }},
+    {key: "db.connect_timeout", env: "DB_CONNECT_TIMEOUT", flag: "db-connect-timeout", usage: "time allowed to connect, such as 10s",
+        field: func(c *Config) any { return &c.DB.ConnectTimeout }},
+    {key: "db.statement_timeout", env: "DB_STATEMENT_TIMEOUT", flag: "db-statement-timeout", usage: "cancel queries running longer, 0 for never",
+        field: func(c *Config) any { return &c.DB.StatementTimeout }},
+    {key: "db.slow_query", env: "DB_SLOW_QUERY", flag: "db-slow-query", usage: "log queries taking longer as warnings, 0 for never",
+        field: func(c *Config) any { return &c.DB.SlowQuery }},
+    {key: "db.max_open_conns", env: "DB_MAX_OPEN_CONNS", flag: "db-max-open-conns", usage: "connection pool size, 0 for unbounded",
+        field: func(c *Config) any { return &c.DB.MaxOpenConns }},
+    {key: "db.max_idle_conns", env: "DB_MAX_IDLE_CONNS", flag: "db-max-idle-conns", usage: "idle connections kept open",
+        field: func(c *Config) any { return &c.DB.MaxIdleConns }},
+    {key: "db.conn_max_lifetime", env: "DB_CONN_MAX_LIFETIME", flag: "db-conn-max-lifetime", usage: "close connections older than this, 0 for never",
+        field: func(c *Config) any { return &c.DB.ConnMaxLifetime }},
+    {key: "db.conn_max_idle_time", env: "DB_CONN_MAX_IDLE_TIME", flag: "db-conn-max-idle-time", usage: "close connections idle longer than this, 0 for never",
+        field: func(c *Config) any { return &c.DB.ConnMaxIdleTime }},
+
+    {key: "analysis.window_days", env: "ANALYSIS_WINDOW_DAYS", flag: "analysis-window-days", usage: "days of history behind analyses",
+        field: func(c *Config) any { return &c.Analysis.WindowDays }},
+    {key: "analysis.max_series_days", env: "ANALYSIS_MAX_SERIES_DAYS", flag: "analysis-max-series-days", usage: "most days one price series may span",
+        field: func(c *Config) any { return &c.Analysis.MaxSeriesDays }},
+}
+
+var byKey = func() map[string]setting {
+    m := make(map[string]setting, len(settings))
+    for _, s := range settings {
+        m[s.key] = s
+    }
+    return m
+}()
+
+// apply parses raw into the setting's field. File values arrive as decoded
+// YAML or TOML scalars and are formatted back to text first.
+func (s setting) apply(c *Config, raw any) error {
+    text := strings.TrimSpace(fmt.Sprint(raw))
+    switch field := s.field(c).(type) {
+    case *string:
+        *field = text
+    case *int:
+        n, err := strconv.Atoi(text)
+        if err != nil {
+            return fmt.Errorf("%q is not a whole number", text)
+        }
+        *field = n
+    case *float64:
+        f, err := strconv.ParseFloat(text, 64)
+        if err != nil {
+            return fmt.Errorf("%q is not a number", text)
+        }
+        *field = f
+    case *bool:
+        b, err := strconv.ParseBool(text)
+        if err != nil {
+            return fmt.Errorf("%q is not true or false", text)
+        }
+        *field = b
+    case *time.Duration:
+        d, err := time.ParseDuration(text)
+        if err != nil {
+            return fmt.Errorf("%q is not a duration such as 5s or 1m30s", text)
+        }
+        *field = d
+    default:
+        return fmt.Errorf("setting %s has an unsupported type %T", s.key, field)
+    }
+    return nil
+}
+
+// readFile decodes a YAML or TOML file, chosen by extension, into dotted
+// keys such as "db.host".
+func readFile(path string) (map[string]any, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("reading config file: %w", err)
+    }
+
+    var tree map[string]any
+    switch strings.ToLower(filepath.Ext(path)) {
+    case ".yaml", ".yml":
+        err = yaml.Unmarshal(data, &tree)
+    case ".toml":
+        err = toml.Unmarshal(data, &tree)
+    default:
+        return nil, fmt.Errorf("config file %s must end in .yaml, .yml or .toml", path)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+    }
+
+    values := make(map[string]any)
+    flatten("", tree, values)
+    return values, nil
+}
+
+func flatten(prefix string, tree map[string]any, out map[string]any) {
+    keys := make([]string, 0, len(tree))
+    for k := range tree {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    for _, k := range keys {
+        key := k
+        if prefix != "" {
+            key = prefix + "." + k
+        }
+        if sub, ok := tree[k].(map[string]any); ok {
+            flatten(key, sub, out)
+            continue
+        }
+        out[key] = tree[k]
+    }
+}
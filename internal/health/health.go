@@ -0,0 +1,142 @@
+package health
+
+import (
+    "context"
+    "net/http"
+    "runtime/debug"
+    "sync"
+    "time"
+
+    "github.com/gin-gonic/gin"
+)
+
+// Version is the build version reported by the probes. Release builds set
+// it with -ldflags "-X github.com/ryuzxy/FuncPro/internal/health.Version=v1.2.3";
+// otherwise it falls back to the VCS revision Go stamped into the binary.
+var Version = ""
+
+// Check is one dependency readiness depends on. Run returns nil when the
+// dependency can serve.
+type Check struct {
+    Name string
+    Run  func(ctx context.Context) error
+}
+
+// CheckResult is the outcome of one check.
+type CheckResult struct {
+    Status    string  `json:"status"`
+    LatencyMs float64 `json:"latency_ms"`
+    Error     string  `json:"error,omitempty"`
+}
+
+// Report is the body of both probes.
+type Report struct {
+    Status  string                 `json:"status"`
+    Version string                 `json:"version"`
+    Checks  map[string]CheckResult `json:"checks,omitempty"`
+}
+
+const (
+    StatusOK          = "ok"
+    StatusUnavailable = "unavailable"
+)
+
+type Handler struct {
+    checks  []Check
+    timeout time.Duration
+    version string
+}
+
+// NewHandler serves the probes. Every readiness check gets timeout to
+// answer; they run concurrently, so the probe takes as long as the slowest.
+func NewHandler(timeout time.Duration, checks ...Check) *Handler {
+    return &Handler{checks: checks, timeout: timeout, version: BuildVersion()}
+}
+
+// Live reports that the process is up and serving HTTP. It checks no
+// dependencies, so a database outage never gets the replica restarted.
+func (h *Handler) Live(c *gin.Context) {
+    c.JSON(http.StatusOK, Report{Status: StatusOK, Version: h.version})
+}
+
+// Ready runs every check and answers 503 when one fails, so the
+// orchestrator routes traffic to replicas that can serve it.
+func (h *Handler) Ready(c *gin.Context) {
+    report := h.Check(c.Request.Context())
+    status := http.StatusOK
+    if report.Status != StatusOK {
+        status = http.StatusServiceUnavailable
+    }
+    c.JSON(status, report)
+}
+
+// Check runs the readiness checks.
+func (h *Handler) Check(ctx context.Context) Report {
+    report := Report{Status: StatusOK, Version: h.version, Checks: make(map[string]CheckResult, len(h.checks))}
+
+    var mu sync.Mutex
+    var wg sync.WaitGroup
+    for _, check := range h.checks {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            result := h.run(ctx, check)
+            mu.Lock()
+            defer mu.Unlock()
+            report.Checks[check.Name] = result
+            if result.Status != StatusOK {
+                report.Status = StatusUnavailable
+            }
+        }()
+    }
+    wg.Wait()
+    return report
+}
+
+func (h *Handler) run(ctx context.Context, check Check) CheckResult {
+    ctx, cancel := context.WithTimeout(ctx, h.timeout)
+    defer cancel()
+
+    // A check that ignores ctx still cannot hold up the probe.
+    start := time.Now()
+    done := make(chan error, 1)
+    go func() {
+        done <- check.Run(ctx)
+    }()
+    var err error
+    select {
+    case err = <-done:
+    case <-ctx.Done():
+        err = ctx.Err()
+    }
+    result := CheckResult{Status: StatusOK, LatencyMs: float64(time.Since(start).Microseconds()) / 1000}
+    if err != nil {
+        result.Status = StatusUnavailable
+        result.Error = err.Error()
+    }
+    return result
+}
+
+// BuildVersion is Version, or the VCS revision when the build did not set it.
+func BuildVersion() string {
+    if Version != "" {
+        return Version
+    }
+    info, ok := debug.ReadBuildInfo()
+    if !ok {
+        return "unknown"
+    }
+    version, dirty := "dev", false
+    for _, s := range info.Settings {
+        switch s.Key {
+        case "vcs.revision":
+            version = s.Value
+        case "vcs.modified":
+            dirty = s.Value == "true"
+        }
+    }
+    if dirty {
+        version += "-dirty"
+    }
+    return version
+}
@@ -0,0 +1,73 @@
+package health
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+)
+
+func serve(h *Handler, path string) (int, Report) {
+    gin.SetMode(gin.TestMode)
+    r := gin.New()
+    r.GET("/livez", h.Live)
+    r.GET("/readyz", h.Ready)
+
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+    var report Report
+    json.Unmarshal(w.Body.Bytes(), &report)
+    return w.Code, report
+}
+
+func TestReady(t *testing.T) {
+    ok := Check{Name: "database", Run: func(context.Context) error { return nil }}
+    down := Check{Name: "migrations", Run: func(context.Context) error { return errors.New("schema is behind") }}
+    hung := Check{Name: "cache", Run: func(context.Context) error {
+        select {} // ignores its context
+    }}
+
+    tests := []struct {
+        name   string
+        checks []Check
+        code   int
+        failed map[string]string
+    }{
+        {"all up", []Check{ok}, http.StatusOK, nil},
+        {"one down", []Check{ok, down}, http.StatusServiceUnavailable, map[string]string{"migrations": "schema is behind"}},
+        {"timed out", []Check{ok, hung}, http.StatusServiceUnavailable, map[string]string{"cache": context.DeadlineExceeded.Error()}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            code, report := serve(NewHandler(20*time.Millisecond, tt.checks...), "/readyz")
+            if code != tt.code {
+                t.Errorf("status = %d, want %d", code, tt.code)
+            }
+            if report.Version == "" {
+                t.Error("report has no version")
+            }
+            if len(report.Checks) != len(tt.checks) {
+                t.Fatalf("got %d check results, want %d", len(report.Checks), len(tt.checks))
+            }
+            for name, result := range report.Checks {
+                want, failed := tt.failed[name]
+                if failed != (result.Status == StatusUnavailable) || result.Error != want {
+                    t.Errorf("%s = %+v", name, result)
+                }
+            }
+        })
+    }
+}
+
+func TestLiveChecksNothing(t *testing.T) {
+    down := Check{Name: "database", Run: func(context.Context) error { return errors.New("down") }}
+    code, report := serve(NewHandler(time.Second, down), "/livez")
+    if code != http.StatusOK || report.Status != StatusOK || len(report.Checks) != 0 {
+        t.Errorf("livez = %d %+v", code, report)
+    }
+}
@@ -0,0 +1,81 @@
+package logging
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "log/slog"
+    "strings"
+
+    "go.opentelemetry.io/otel/trace"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID returns ctx carrying the id of the request it serves.
+// Records logged with the context get a request_id attribute.
+func WithRequestID(ctx context.Context, id string) context.Context {
+    return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID is the request id carried by ctx, "" outside a request.
+func RequestID(ctx context.Context) string {
+    id, _ := ctx.Value(requestIDKey{}).(string)
+    return id
+}
+
+// ParseLevel reads debug, info, warn or error.
+func ParseLevel(s string) (slog.Level, error) {
+    var level slog.Level
+    if err := level.UnmarshalText([]byte(s)); err != nil {
+        return 0, fmt.Errorf("unknown log level %q", s)
+    }
+    return level, nil
+}
+
+// New returns a logger writing JSON, or logfmt-style text for format
+// "text", that adds the request id and trace ids of the context passed to
+// the *Context logging methods.
+func New(w io.Writer, level slog.Level, format string) *slog.Logger {
+    opts := &slog.HandlerOptions{Level: level}
+    var handler slog.Handler
+    if strings.EqualFold(format, "text") {
+        handler = slog.NewTextHandler(w, opts)
+    } else {
+        handler = slog.NewJSONHandler(w, opts)
+    }
+    return slog.New(contextHandler{handler})
+}
+
+// Setup makes the logger from New the default for slog and the standard
+// log package.
+func Setup(w io.Writer, level, format string) error {
+    l, err := ParseLevel(level)
+    if err != nil {
+        return err
+    }
+    slog.SetDefault(New(w, l, format))
+    return nil
+}
+
+type contextHandler struct {
+    slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+    if id := RequestID(ctx); id != "" {
+        r.AddAttrs(slog.String("request_id", id))
+    }
+    if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+        r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+    }
+    return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+    return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+    return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,69 @@
+package logging
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "log/slog"
+    "testing"
+
+    "go.opentelemetry.io/otel/trace"
+)
+
+func TestRequestIDAttribute(t *testing.T) {
+    var buf bytes.Buffer
+    logger := New(&buf, slog.LevelInfo, "json").With("component", "test")
+
+    ctx := WithRequestID(context.Background(), "abc-123")
+    logger.InfoContext(ctx, "with id")
+    logger.Info("without id")
+    logger.DebugContext(ctx, "below level")
+
+    var records []map[string]any
+    dec := json.NewDecoder(&buf)
+    for dec.More() {
+        var r map[string]any
+        if err := dec.Decode(&r); err != nil {
+            t.Fatal(err)
+        }
+        records = append(records, r)
+    }
+    if len(records) != 2 {
+        t.Fatalf("got %d records, want 2", len(records))
+    }
+    if records[0]["request_id"] != "abc-123" || records[0]["component"] != "test" {
+        t.Errorf("first record = %v", records[0])
+    }
+    if _, ok := records[1]["request_id"]; ok {
+        t.Errorf("record without a request has request_id: %v", records[1])
+    }
+}
+
+func TestParseLevel(t *testing.T) {
+    for _, s := range []string{"debug", "INFO", "warn", "error"} {
+        if _, err := ParseLevel(s); err != nil {
+            t.Errorf("ParseLevel(%q) = %v", s, err)
+        }
+    }
+    if _, err := ParseLevel("verbose"); err == nil {
+        t.Error("ParseLevel accepted verbose")
+    }
+}
+
+func TestTraceIDAttribute(t *testing.T) {
+    var buf bytes.Buffer
+    logger := New(&buf, slog.LevelInfo, "json")
+
+    traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+    spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+    sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID})
+    logger.InfoContext(trace.ContextWithSpanContext(context.Background(), sc), "traced")
+
+    var rec map[string]any
+    if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+        t.Fatal(err)
+    }
+    if rec["trace_id"] != traceID.String() || rec["span_id"] != spanID.String() {
+        t.Errorf("record = %v", rec)
+    }
+}
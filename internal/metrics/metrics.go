@@ -0,0 +1,203 @@
+package metrics
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "runtime"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/collectors"
+    "github.com/prometheus/client_golang/prometheus/promhttp"
+    "gorm.io/gorm"
+
+    "github.com/ryuzxy/FuncPro/pkg/alert"
+    "github.com/ryuzxy/FuncPro/pkg/price"
+)
+
+const namespace = "funcpro"
+
+// Metrics owns the registry served on /metrics and the collectors the
+// middleware, the database callbacks and the domain listeners update.
+type Metrics struct {
+    registry *prometheus.Registry
+
+    requests *prometheus.CounterVec
+    latency  *prometheus.HistogramVec
+    queries  *prometheus.HistogramVec
+
+    pricesIngested   prometheus.Counter
+    bulkRowsRejected prometheus.Counter
+    alertsFired      *prometheus.CounterVec
+}
+
+func New() *Metrics {
+    m := &Metrics{
+        registry: prometheus.NewRegistry(),
+        requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+            Namespace: namespace,
+            Name:      "http_requests_total",
+            Help:      "HTTP requests by method, route and status.",
+        }, []string{"method", "route", "status"}),
+        latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+            Namespace: namespace,
+            Name:      "http_request_duration_seconds",
+            Help:      "HTTP request latency by method, route and status.",
+            Buckets:   prometheus.DefBuckets,
+        }, []string{"method", "route", "status"}),
+        queries: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+            Namespace: namespace,
+            Name:      "repository_call_duration_seconds",
+            Help:      "Duration of the SQL statements each repository method runs.",
+            Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+        }, []string{"repository", "method", "operation"}),
+        pricesIngested: prometheus.NewCounter(prometheus.CounterOpts{
+            Namespace: namespace,
+            Name:      "prices_ingested_total",
+            Help:      "Prices stored through the API.",
+        }),
+        bulkRowsRejected: prometheus.NewCounter(prometheus.CounterOpts{
+            Namespace: namespace,
+            Name:      "bulk_rows_rejected_total",
+            Help:      "Rows of bulk price requests rejected as a whole.",
+        }),
+        alertsFired: prometheus.NewCounterVec(prometheus.CounterOpts{
+            Namespace: namespace,
+            Name:      "alerts_fired_total",
+            Help:      "Alert rules fired, by condition.",
+        }, []string{"condition"}),
+    }
+    m.registry.MustRegister(
+        collectors.NewGoCollector(),
+        collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+        m.requests, m.latency, m.queries,
+        m.pricesIngested, m.bulkRowsRejected, m.alertsFired,
+    )
+    return m
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *Metrics) Handler() gin.HandlerFunc {
+    h := promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+    return gin.WrapH(h)
+}
+
+// Middleware counts and times requests. Paths no route matched share one
+// label, so scanners cannot blow up the number of series.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        start := time.Now()
+        c.Next()
+
+        route := c.FullPath()
+        if route == "" {
+            route = "unmatched"
+        }
+        labels := prometheus.Labels{
+            "method": c.Request.Method,
+            "route":  route,
+            "status": strconv.Itoa(c.Writer.Status()),
+        }
+        m.requests.With(labels).Inc()
+        m.latency.With(labels).Observe(time.Since(start).Seconds())
+    }
+}
+
+// WatchPool exports the sql.DB pool counters as gauges and counters.
+func (m *Metrics) WatchPool(db *sql.DB) {
+    m.registry.MustRegister(collectors.NewDBStatsCollector(db, namespace))
+}
+
+// WatchPrices counts stored prices and rejected bulk rows.
+func (m *Metrics) WatchPrices(svc price.Service) {
+    svc.OnCreated(func(_ context.Context, prices []price.Price) {
+        m.pricesIngested.Add(float64(len(prices)))
+    })
+    svc.OnBulkRejected(func(_ context.Context, rows int, _ error) {
+        m.bulkRowsRejected.Add(float64(rows))
+    })
+}
+
+// WatchAlerts counts fired alerts.
+func (m *Metrics) WatchAlerts(svc alert.Service) {
+    svc.OnFired(func(rule alert.AlertRule) {
+        m.alertsFired.WithLabelValues(rule.Condition).Inc()
+    })
+}
+
+const startKey = "metrics:start"
+
+// WatchQueries times every statement db runs and attributes it to the
+// repository method that ran it.
+func (m *Metrics) WatchQueries(db *gorm.DB) error {
+    before := func(tx *gorm.DB) {
+        tx.InstanceSet(startKey, time.Now())
+    }
+    after := func(operation string) func(*gorm.DB) {
+        return func(tx *gorm.DB) {
+            start, ok := tx.InstanceGet(startKey)
+            if !ok {
+                return
+            }
+            repository, method := caller()
+            m.queries.WithLabelValues(repository, method, operation).Observe(time.Since(start.(time.Time)).Seconds())
+        }
+    }
+
+    cb := db.Callback()
+    return errors.Join(
+        cb.Create().Before("gorm:create").Register("metrics:before_create", before),
+        cb.Create().After("gorm:create").Register("metrics:after_create", after("create")),
+        cb.Query().Before("gorm:query").Register("metrics:before_query", before),
+        cb.Query().After("gorm:query").Register("metrics:after_query", after("query")),
+        cb.Update().Before("gorm:update").Register("metrics:before_update", before),
+        cb.Update().After("gorm:update").Register("metrics:after_update", after("update")),
+        cb.Delete().Before("gorm:delete").Register("metrics:before_delete", before),
+        cb.Delete().After("gorm:delete").Register("metrics:after_delete", after("delete")),
+        cb.Row().Before("gorm:row").Register("metrics:before_row", before),
+        cb.Row().After("gorm:row").Register("metrics:after_row", after("row")),
+        cb.Raw().Before("gorm:raw").Register("metrics:before_raw", before),
+        cb.Raw().After("gorm:raw").Register("metrics:after_raw", after("raw")),
+    )
+}
+
+// caller finds the repository method on the stack, turning
+// "github.com/ryuzxy/FuncPro/pkg/price.(*priceRepository).BulkCreate.func1"
+// into ("price", "BulkCreate"). Statements run from elsewhere, such as the
+// migrator, are labelled "other".
+func caller() (string, string) {
+    var pcs [64]uintptr
+    n := runtime.Callers(3, pcs[:])
+    frames := runtime.CallersFrames(pcs[:n])
+    for {
+        frame, more := frames.Next()
+        if repository, method, ok := repositoryMethod(frame.Function); ok {
+            return repository, method
+        }
+        if !more {
+            return "other", "other"
+        }
+    }
+}
+
+func repositoryMethod(function string) (string, string, bool) {
+    const prefix = "github.com/ryuzxy/FuncPro/pkg/"
+    rest, ok := strings.CutPrefix(function, prefix)
+    if !ok {
+        return "", "", false
+    }
+    pkg, rest, ok := strings.Cut(rest, ".")
+    if !ok || !strings.HasPrefix(rest, "(*") {
+        return "", "", false
+    }
+    receiver, rest, ok := strings.Cut(strings.TrimPrefix(rest, "(*"), ").")
+    if !ok || !strings.HasSuffix(strings.ToLower(receiver), "repository") {
+        return "", "", false
+    }
+    method, _, _ := strings.Cut(rest, ".")
+    return pkg, method, true
+}
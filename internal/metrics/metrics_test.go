@@ -0,0 +1,97 @@
+package metrics
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+
+    "github.com/gin-gonic/gin"
+    "github.com/prometheus/client_golang/prometheus/testutil"
+
+    "github.com/ryuzxy/FuncPro/pkg/price"
+)
+
+func TestMiddleware(t *testing.T) {
+    gin.SetMode(gin.TestMode)
+    m := New()
+    r := gin.New()
+    r.Use(m.Middleware())
+    r.GET("/komoditas/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+    r.GET("/metrics", m.Handler())
+
+    for _, path := range []string{"/komoditas/1", "/komoditas/2", "/wp-admin.php"} {
+        r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+    }
+
+    if got := testutil.ToFloat64(m.requests.WithLabelValues("GET", "/komoditas/:id", "200")); got != 2 {
+        t.Errorf("requests to /komoditas/:id = %v, want 2", got)
+    }
+    if got := testutil.ToFloat64(m.requests.WithLabelValues("GET", "unmatched", "404")); got != 1 {
+        t.Errorf("unmatched requests = %v, want 1", got)
+    }
+
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+    for _, want := range []string{
+        `funcpro_http_request_duration_seconds_count{method="GET",route="/komoditas/:id",status="200"} 2`,
+        "go_goroutines",
+    } {
+        if !strings.Contains(w.Body.String(), want) {
+            t.Errorf("/metrics lacks %s", want)
+        }
+    }
+}
+
+// stubPrices keeps the listeners registered on it.
+type stubPrices struct {
+    price.Service
+    created  []price.CreatedListener
+    rejected []price.RejectedListener
+}
+
+func (s *stubPrices) OnCreated(l price.CreatedListener)       { s.created = append(s.created, l) }
+func (s *stubPrices) OnBulkRejected(l price.RejectedListener) { s.rejected = append(s.rejected, l) }
+
+func TestWatchPrices(t *testing.T) {
+    m := New()
+    svc := &stubPrices{}
+    m.WatchPrices(svc)
+
+    ctx := context.Background()
+    for _, l := range svc.created {
+        l(ctx, make([]price.Price, 3))
+    }
+    for _, l := range svc.rejected {
+        l(ctx, 50, errors.New("value must > 0"))
+    }
+    if got := testutil.ToFloat64(m.pricesIngested); got != 3 {
+        t.Errorf("prices ingested = %v, want 3", got)
+    }
+    if got := testutil.ToFloat64(m.bulkRowsRejected); got != 50 {
+        t.Errorf("bulk rows rejected = %v, want 50", got)
+    }
+}
+
+func TestRepositoryMethod(t *testing.T) {
+    tests := []struct {
+        function   string
+        repository string
+        method     string
+        ok         bool
+    }{
+        {"github.com/ryuzxy/FuncPro/pkg/price.(*priceRepository).GetByKomoditasID", "price", "GetByKomoditasID", true},
+        {"github.com/ryuzxy/FuncPro/pkg/alert.(*repository).ClaimDueDeliveries.func1", "alert", "ClaimDueDeliveries", true},
+        {"github.com/ryuzxy/FuncPro/pkg/price.(*service).CreatePrice", "", "", false},
+        {"github.com/ryuzxy/FuncPro/db.(*Migrator).Up", "", "", false},
+        {"gorm.io/gorm.(*DB).Find", "", "", false},
+    }
+    for _, tt := range tests {
+        repository, method, ok := repositoryMethod(tt.function)
+        if repository != tt.repository || method != tt.method || ok != tt.ok {
+            t.Errorf("repositoryMethod(%s) = %q, %q, %v", tt.function, repository, method, ok)
+        }
+    }
+}
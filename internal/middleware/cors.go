@@ -0,0 +1,186 @@
+package middleware
+
+import (
+    "net/http"
+    "slices"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/gin-gonic/gin"
+)
+
+// CORSPolicy says which browser origins may call the API and what they may
+// send and read.
+type CORSPolicy struct {
+    // AllowedOrigins are exact origins such as "https://app.example.com",
+    // wildcard subdomains such as "https://*.example.com", or "*" for any
+    // origin. Empty allows no cross-origin calls.
+    AllowedOrigins []string
+    // AllowCredentials lets pages send cookies and read responses to
+    // requests that carried them. It cannot be combined with "*".
+    AllowCredentials bool
+    AllowedHeaders   []string
+    ExposedHeaders   []string
+    // MaxAge is how long browsers may cache a preflight answer.
+    MaxAge time.Duration
+}
+
+// Allows reports whether origin is on the allowlist.
+func (p CORSPolicy) Allows(origin string) bool {
+    origin = strings.ToLower(origin)
+    for _, allowed := range p.AllowedOrigins {
+        allowed = strings.ToLower(allowed)
+        if allowed == "*" || allowed == origin {
+            return true
+        }
+        scheme, domain, ok := strings.Cut(allowed, "://*.")
+        if !ok {
+            continue
+        }
+        // Any subdomain at any depth, but not the domain itself.
+        sub, ok := strings.CutPrefix(origin, scheme+"://")
+        if ok && strings.HasSuffix(sub, "."+domain) && len(sub) > len(domain)+1 && !strings.ContainsAny(sub, "/@") {
+            return true
+        }
+    }
+    return false
+}
+
+// CORS applies policy to requests carrying an Origin header. Preflights
+// are answered here, listing only the methods routes registers for the
+// requested path, so a method added to a route is offered without touching
+// the policy; routes is read once, on the first preflight, when every route
+// exists. Origins off the allowlist get no CORS headers, which browsers
+// treat as a refusal, and their preflights get 403. Responses carry
+// Vary: Origin, so caches keep the answers for different origins apart.
+func CORS(policy CORSPolicy, routes func() gin.RoutesInfo) gin.HandlerFunc {
+    var (
+        once    sync.Once
+        methods func(path string) []string
+    )
+    allowHeaders := strings.Join(policy.AllowedHeaders, ", ")
+    exposeHeaders := strings.Join(policy.ExposedHeaders, ", ")
+    maxAge := strconv.Itoa(int(policy.MaxAge.Seconds()))
+    anyOrigin := slices.Contains(policy.AllowedOrigins, "*") && !policy.AllowCredentials
+
+    return func(c *gin.Context) {
+        if len(policy.AllowedOrigins) == 0 {
+            c.Next()
+            return
+        }
+        h := c.Writer.Header()
+        h.Add("Vary", "Origin")
+
+        origin := c.GetHeader("Origin")
+        preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+        if origin == "" {
+            c.Next()
+            return
+        }
+        if !policy.Allows(origin) {
+            if preflight {
+                c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+                    "success": false,
+                    "error":   "origin not allowed",
+                })
+                return
+            }
+            c.Next()
+            return
+        }
+
+        if anyOrigin {
+            h.Set("Access-Control-Allow-Origin", "*")
+        } else {
+            h.Set("Access-Control-Allow-Origin", origin)
+        }
+        if policy.AllowCredentials {
+            h.Set("Access-Control-Allow-Credentials", "true")
+        }
+
+        if !preflight {
+            if exposeHeaders != "" {
+                h.Set("Access-Control-Expose-Headers", exposeHeaders)
+            }
+            c.Next()
+            return
+        }
+
+        once.Do(func() { methods = routeMethods(routes()) })
+        allowed := methods(c.Request.URL.Path)
+        if len(allowed) == 0 {
+            c.AbortWithStatus(http.StatusNotFound)
+            return
+        }
+        h.Add("Vary", "Access-Control-Request-Method")
+        h.Add("Vary", "Access-Control-Request-Headers")
+        h.Set("Access-Control-Allow-Methods", strings.Join(allowed, ", "))
+        if allowHeaders != "" {
+            h.Set("Access-Control-Allow-Headers", allowHeaders)
+        }
+        if policy.MaxAge > 0 {
+            h.Set("Access-Control-Max-Age", maxAge)
+        }
+        c.AbortWithStatus(http.StatusNoContent)
+    }
+}
+
+// routeMethods indexes routes by path pattern and returns a lookup of the
+// methods registered for a concrete path, matching ":param" segments and
+// "*rest" tails the way the router does.
+func routeMethods(routes gin.RoutesInfo) func(path string) []string {
+    type route struct {
+        segments []string
+        methods  []string
+    }
+    byPattern := make(map[string]*route)
+    var patterns []*route
+    for _, r := range routes {
+        rt, ok := byPattern[r.Path]
+        if !ok {
+            rt = &route{segments: strings.Split(strings.Trim(r.Path, "/"), "/")}
+            byPattern[r.Path] = rt
+            patterns = append(patterns, rt)
+        }
+        if !slices.Contains(rt.methods, r.Method) {
+            rt.methods = append(rt.methods, r.Method)
+        }
+    }
+
+    return func(path string) []string {
+        segments := strings.Split(strings.Trim(path, "/"), "/")
+        var methods []string
+        for _, rt := range patterns {
+            if matchSegments(rt.segments, segments) {
+                for _, m := range rt.methods {
+                    if !slices.Contains(methods, m) {
+                        methods = append(methods, m)
+                    }
+                }
+            }
+        }
+        slices.Sort(methods)
+        return methods
+    }
+}
+
+func matchSegments(pattern, path []string) bool {
+    for i, p := range pattern {
+        if strings.HasPrefix(p, "*") {
+            return true
+        }
+        if i >= len(path) {
+            return false
+        }
+        if strings.HasPrefix(p, ":") {
+            if path[i] == "" {
+                return false
+            }
+        } else if p != path[i] {
+            return false
+        }
+    }
+    return len(pattern) == len(path)
+}
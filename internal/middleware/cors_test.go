@@ -0,0 +1,157 @@
+package middleware
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+)
+
+func TestCORSOrigins(t *testing.T) {
+    policy := CORSPolicy{AllowedOrigins: []string{"https://app.example.com", "https://*.funcpro.id", "http://localhost:3000"}}
+    tests := []struct {
+        origin string
+        want   bool
+    }{
+        {"https://app.example.com", true},
+        {"https://APP.example.com", true},
+        {"http://app.example.com", false},
+        {"https://app.example.com:8443", false},
+        {"https://evil.example.com", false},
+        {"https://pasar.funcpro.id", true},
+        {"https://a.b.funcpro.id", true},
+        {"https://funcpro.id", false},
+        {"https://evilfuncpro.id", false},
+        {"https://funcpro.id.evil.com", false},
+        {"http://pasar.funcpro.id", false},
+        {"http://localhost:3000", true},
+        {"http://localhost:3001", false},
+        {"null", false},
+    }
+    for _, tt := range tests {
+        if got := policy.Allows(tt.origin); got != tt.want {
+            t.Errorf("allows(%q) = %v, want %v", tt.origin, got, tt.want)
+        }
+    }
+}
+
+func newCORSRouter(policy CORSPolicy) *gin.Engine {
+    gin.SetMode(gin.TestMode)
+    r := gin.New()
+    r.Use(CORS(policy, r.Routes))
+    ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+    r.GET("/api/v1/komoditas", ok)
+    r.POST("/api/v1/komoditas", ok)
+    r.GET("/api/v1/komoditas/:id", ok)
+    r.PUT("/api/v1/komoditas/:id", ok)
+    r.PATCH("/api/v1/komoditas/:id", ok)
+    r.DELETE("/api/v1/komoditas/:id", ok)
+    r.POST("/api/v1/prices/bulk", ok)
+    return r
+}
+
+func TestCORSPreflight(t *testing.T) {
+    r := newCORSRouter(CORSPolicy{
+        AllowedOrigins:   []string{"https://*.example.com"},
+        AllowCredentials: true,
+        AllowedHeaders:   []string{"Content-Type", "Authorization"},
+        ExposedHeaders:   []string{"X-Request-ID"},
+        MaxAge:           10 * time.Minute,
+    })
+
+    tests := []struct {
+        name    string
+        origin  string
+        path    string
+        status  int
+        methods string
+    }{
+        {"item route", "https://app.example.com", "/api/v1/komoditas/5", http.StatusNoContent, "DELETE, GET, PATCH, PUT"},
+        {"collection route", "https://app.example.com", "/api/v1/komoditas", http.StatusNoContent, "GET, POST"},
+        {"write-only route", "https://app.example.com", "/api/v1/prices/bulk", http.StatusNoContent, "POST"},
+        {"unknown route", "https://app.example.com", "/api/v1/nope", http.StatusNotFound, ""},
+        {"origin not allowed", "https://example.org", "/api/v1/komoditas", http.StatusForbidden, ""},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            req := httptest.NewRequest(http.MethodOptions, tt.path, nil)
+            req.Header.Set("Origin", tt.origin)
+            req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+            w := httptest.NewRecorder()
+            r.ServeHTTP(w, req)
+
+            h := w.Header()
+            if w.Code != tt.status {
+                t.Fatalf("status = %d, want %d", w.Code, tt.status)
+            }
+            if got := h.Get("Access-Control-Allow-Methods"); got != tt.methods {
+                t.Errorf("Allow-Methods = %q, want %q", got, tt.methods)
+            }
+            if !strings.Contains(strings.Join(h.Values("Vary"), ","), "Origin") {
+                t.Errorf("Vary = %q", h.Values("Vary"))
+            }
+            if tt.status != http.StatusNoContent {
+                if tt.status == http.StatusForbidden && h.Get("Access-Control-Allow-Origin") != "" {
+                    t.Errorf("refused origin got Allow-Origin %q", h.Get("Access-Control-Allow-Origin"))
+                }
+                return
+            }
+            if h.Get("Access-Control-Allow-Origin") != tt.origin || h.Get("Access-Control-Allow-Credentials") != "true" {
+                t.Errorf("origin %q, credentials %q", h.Get("Access-Control-Allow-Origin"), h.Get("Access-Control-Allow-Credentials"))
+            }
+            if h.Get("Access-Control-Allow-Headers") != "Content-Type, Authorization" || h.Get("Access-Control-Max-Age") != "600" {
+                t.Errorf("headers %q, max age %q", h.Get("Access-Control-Allow-Headers"), h.Get("Access-Control-Max-Age"))
+            }
+        })
+    }
+}
+
+func TestCORSActualRequest(t *testing.T) {
+    tests := []struct {
+        name        string
+        policy      CORSPolicy
+        origin      string
+        allowOrigin string
+        vary        bool
+    }{
+        {"allowed origin is echoed", CORSPolicy{AllowedOrigins: []string{"https://app.example.com"}, ExposedHeaders: []string{"X-Request-ID"}},
+            "https://app.example.com", "https://app.example.com", true},
+        {"any origin without credentials", CORSPolicy{AllowedOrigins: []string{"*"}, ExposedHeaders: []string{"X-Request-ID"}},
+            "https://whoever.test", "*", true},
+        {"other origin gets nothing", CORSPolicy{AllowedOrigins: []string{"https://app.example.com"}},
+            "https://evil.test", "", true},
+        {"same-origin request", CORSPolicy{AllowedOrigins: []string{"https://app.example.com"}}, "", "", true},
+        {"CORS off", CORSPolicy{}, "https://app.example.com", "", false},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            r := newCORSRouter(tt.policy)
+            req := httptest.NewRequest(http.MethodGet, "/api/v1/komoditas", nil)
+            if tt.origin != "" {
+                req.Header.Set("Origin", tt.origin)
+            }
+            w := httptest.NewRecorder()
+            r.ServeHTTP(w, req)
+
+            h := w.Header()
+            if w.Code != http.StatusOK {
+                t.Errorf("status = %d", w.Code)
+            }
+            if got := h.Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+                t.Errorf("Allow-Origin = %q, want %q", got, tt.allowOrigin)
+            }
+            if h.Get("Access-Control-Allow-Credentials") != "" {
+                t.Error("credentials allowed without AllowCredentials")
+            }
+            if got := h.Get("Access-Control-Expose-Headers"); (got != "") != (tt.allowOrigin != "") {
+                t.Errorf("Expose-Headers = %q", got)
+            }
+            if got := h.Get("Vary") == "Origin"; got != tt.vary {
+                t.Errorf("Vary = %q", h.Get("Vary"))
+            }
+        })
+    }
+}
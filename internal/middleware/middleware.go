@@ -1,44 +1,95 @@
 package middleware
 
 import (
-    "log"
+    "crypto/rand"
+    "encoding/hex"
+    "fmt"
+    "io"
+    "log/slog"
     "net/http"
+    "runtime/debug"
+    "strings"
     "time"
 
     "github.com/gin-gonic/gin"
+
+    "github.com/ryuzxy/FuncPro/internal/logging"
 )
 
 
-func Logger() gin.HandlerFunc {
-    return func(c *gin.Context) {
-        start := time.Now()
+// RequestIDHeader carries the request id in both directions.
+const RequestIDHeader = "X-Request-ID"
 
+// RequestID keeps the caller's X-Request-ID when it is a sane token and
+// generates one otherwise. The id is echoed in the response and carried in
+// the request context, so every log line of the request, down to failed
+// queries, can be found by it.
+func RequestID() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id := c.GetHeader(RequestIDHeader)
+        if !validRequestID(id) {
+            id = newRequestID()
+        }
+        c.Header(RequestIDHeader, id)
+        c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
         c.Next()
+    }
+}
 
-        latency := time.Since(start)
-        status := c.Writer.Status()
-        method := c.Request.Method
-        path := c.Request.URL.Path
-
-        log.Printf("[%d] %s %s (%s)", status, method, path, latency)
+func validRequestID(id string) bool {
+    if id == "" || len(id) > 128 {
+        return false
+    }
+    for _, r := range id {
+        ok := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_.:", r)
+        if !ok {
+            return false
+        }
     }
+    return true
 }
 
-// CORS 
-func CORS() gin.HandlerFunc {
+func newRequestID() string {
+    var b [16]byte
+    rand.Read(b[:])
+    return hex.EncodeToString(b[:])
+}
+
+// Logger writes one record per request: errors for 5xx, warnings for 4xx.
+func Logger() gin.HandlerFunc {
     return func(c *gin.Context) {
-        c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-        c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-        c.Writer.Header().Set("Access-Control-Allow-Headers",
-            "Content-Type, Authorization, Accept, Origin, Cache-Control, X-Requested-With")
-        c.Writer.Header().Set("Access-Control-Allow-Methods",
-            "POST, GET, OPTIONS, PUT, DELETE")
-
-        if c.Request.Method == http.MethodOptions {
-            c.AbortWithStatus(http.StatusNoContent)
-            return
-        }
+        start := time.Now()
 
         c.Next()
+
+        status := c.Writer.Status()
+        level := slog.LevelInfo
+        switch {
+        case status >= 500:
+            level = slog.LevelError
+        case status >= 400:
+            level = slog.LevelWarn
+        }
+        attrs := []slog.Attr{
+            slog.String("method", c.Request.Method),
+            slog.String("path", c.Request.URL.Path),
+            slog.String("route", c.FullPath()),
+            slog.Int("status", status),
+            slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+            slog.Int("bytes", c.Writer.Size()),
+            slog.String("client_ip", c.ClientIP()),
+        }
+        if len(c.Errors) > 0 {
+            attrs = append(attrs, slog.String("error", c.Errors.String()))
+        }
+        slog.LogAttrs(c.Request.Context(), level, "request", attrs...)
     }
 }
+
+// Recovery turns a panic into a 500 and logs it with the request id.
+func Recovery() gin.HandlerFunc {
+    return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, err any) {
+        slog.ErrorContext(c.Request.Context(), "panic", "error", fmt.Sprint(err), "stack", string(debug.Stack()))
+        c.AbortWithStatus(http.StatusInternalServerError)
+    })
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+    "bytes"
+    "encoding/json"
+    "log/slog"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+
+    "github.com/gin-gonic/gin"
+
+    "github.com/ryuzxy/FuncPro/internal/logging"
+)
+
+func TestRequestID(t *testing.T) {
+    gin.SetMode(gin.TestMode)
+    r := gin.New()
+    r.Use(RequestID())
+    r.GET("/", func(c *gin.Context) {
+        c.String(http.StatusOK, logging.RequestID(c.Request.Context()))
+    })
+
+    tests := []struct {
+        name     string
+        incoming string
+        keep     bool
+    }{
+        {"generated", "", false},
+        {"propagated", "req-42.a:b_c", true},
+        {"unsafe replaced", "bad id\nX-Injected: 1", false},
+        {"too long replaced", strings.Repeat("a", 129), false},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            req := httptest.NewRequest(http.MethodGet, "/", nil)
+            if tt.incoming != "" {
+                req.Header.Set(RequestIDHeader, tt.incoming)
+            }
+            w := httptest.NewRecorder()
+            r.ServeHTTP(w, req)
+
+            id := w.Header().Get(RequestIDHeader)
+            if id == "" || w.Body.String() != id {
+                t.Fatalf("header %q, context %q", id, w.Body.String())
+            }
+            if (id == tt.incoming) != tt.keep {
+                t.Errorf("id = %q for incoming %q", id, tt.incoming)
+            }
+        })
+    }
+}
+
+func TestLoggerCarriesRequestID(t *testing.T) {
+    var buf bytes.Buffer
+    defer slog.SetDefault(slog.Default())
+    slog.SetDefault(logging.New(&buf, slog.LevelInfo, "json"))
+
+    gin.SetMode(gin.TestMode)
+    r := gin.New()
+    r.Use(RequestID(), Logger(), Recovery())
+    r.GET("/boom/:id", func(c *gin.Context) { panic("boom") })
+
+    req := httptest.NewRequest(http.MethodGet, "/boom/7", nil)
+    req.Header.Set(RequestIDHeader, "trace-me")
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    if w.Code != http.StatusInternalServerError {
+        t.Fatalf("status = %d", w.Code)
+    }
+
+    var panicked, logged bool
+    for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+        var rec map[string]any
+        if err := json.Unmarshal(line, &rec); err != nil {
+            t.Fatal(err)
+        }
+        if rec["request_id"] != "trace-me" {
+            t.Errorf("record without the request id: %v", rec)
+        }
+        switch rec["msg"] {
+        case "panic":
+            panicked = true
+        case "request":
+            logged = rec["level"] == "ERROR" && rec["route"] == "/boom/:id" && rec["status"] == float64(500)
+        }
+    }
+    if !panicked || !logged {
+        t.Errorf("missing panic or request record:\n%s", buf.String())
+    }
+}
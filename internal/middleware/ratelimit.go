@@ -0,0 +1,152 @@
+package middleware
+
+import (
+    "fmt"
+    "math"
+    "net/http"
+    "strconv"
+    "sync"
+    "time"
+
+    "github.com/gin-gonic/gin"
+
+    "github.com/ryuzxy/FuncPro/pkg/auth"
+)
+
+// RateLimiter keeps a token bucket per client: each request takes a token,
+// and tokens come back at a steady rate up to the burst size.
+type RateLimiter struct {
+    rate  float64 // tokens per second
+    burst float64
+    now   func() time.Time
+
+    mu      sync.Mutex
+    buckets map[string]*bucket
+    swept   time.Time
+}
+
+type bucket struct {
+    tokens float64
+    at     time.Time
+}
+
+// NewRateLimiter allows perMinute requests a minute with bursts of up to
+// burst. A perMinute of 0 allows everything.
+func NewRateLimiter(perMinute, burst int) *RateLimiter {
+    return &RateLimiter{
+        rate:    float64(perMinute) / 60,
+        burst:   float64(burst),
+        now:     time.Now,
+        buckets: make(map[string]*bucket),
+    }
+}
+
+// take spends a token of client's bucket. It returns whether there was one,
+// the whole tokens left, and how long until the next token and until the
+// bucket is full again.
+func (l *RateLimiter) take(client string) (ok bool, remaining int, retry, reset time.Duration) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    now := l.now()
+    if now.Sub(l.swept) >= time.Minute {
+        l.sweep(now)
+    }
+
+    b, found := l.buckets[client]
+    if !found {
+        b = &bucket{tokens: l.burst, at: now}
+        l.buckets[client] = b
+    }
+    b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.at).Seconds()*l.rate)
+    b.at = now
+
+    if b.tokens >= 1 {
+        b.tokens--
+        ok = true
+    } else {
+        retry = l.after(1 - b.tokens)
+    }
+    return ok, int(b.tokens), retry, l.after(l.burst - b.tokens)
+}
+
+// sweep forgets buckets that have refilled, which a new client would get
+// anyway, so the map only holds recently active clients.
+func (l *RateLimiter) sweep(now time.Time) {
+    for client, b := range l.buckets {
+        if b.tokens+now.Sub(b.at).Seconds()*l.rate >= l.burst {
+            delete(l.buckets, client)
+        }
+    }
+    l.swept = now
+}
+
+func (l *RateLimiter) after(tokens float64) time.Duration {
+    return time.Duration(tokens / l.rate * float64(time.Second))
+}
+
+// RateLimit admits requests while the client has tokens and answers 429
+// otherwise. Every response carries X-RateLimit-Limit, -Remaining and
+// -Reset (seconds until the bucket is full); a 429 also carries
+// Retry-After. It must run after authentication, since API keys and token
+// subjects get buckets of their own while everyone else is counted by IP.
+func RateLimit(l *RateLimiter) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if l.rate <= 0 {
+            c.Next()
+            return
+        }
+
+        ok, remaining, retry, reset := l.take(clientKey(c))
+        h := c.Writer.Header()
+        h.Set("X-RateLimit-Limit", strconv.Itoa(int(l.burst)))
+        h.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+        h.Set("X-RateLimit-Reset", strconv.Itoa(seconds(reset)))
+        if !ok {
+            h.Set("Retry-After", strconv.Itoa(seconds(retry)))
+            c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+                "success": false,
+                "error":   fmt.Sprintf("rate limit exceeded, retry in %d s", seconds(retry)),
+            })
+            return
+        }
+        c.Next()
+    }
+}
+
+func clientKey(c *gin.Context) string {
+    p, _ := auth.FromContext(c.Request.Context())
+    switch p.Method {
+    case auth.MethodAPIKey:
+        return "key:" + strconv.FormatUint(uint64(p.KeyID), 10)
+    case auth.MethodJWT:
+        return "sub:" + p.Subject
+    }
+    return "ip:" + c.ClientIP()
+}
+
+// seconds rounds up, so a client waiting that long finds a token.
+func seconds(d time.Duration) int {
+    return int(math.Ceil(d.Seconds()))
+}
+
+// BodyLimit refuses request bodies over max bytes: at once with 413 when
+// Content-Length says so, otherwise by failing the read once max is
+// passed. A max of 0 allows any size.
+func BodyLimit(max int64) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if max <= 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+            c.Next()
+            return
+        }
+        if c.Request.ContentLength > max {
+            c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+                "success": false,
+                "error":   fmt.Sprintf("request body is larger than %d bytes", max),
+            })
+            return
+        }
+        c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, max)
+        c.Next()
+    }
+}
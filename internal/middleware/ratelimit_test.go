@@ -0,0 +1,136 @@
+package middleware
+
+import (
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+
+    "github.com/ryuzxy/FuncPro/pkg/auth"
+)
+
+func TestRateLimit(t *testing.T) {
+    gin.SetMode(gin.TestMode)
+    now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+    limiter := NewRateLimiter(60, 2)
+    limiter.now = func() time.Time { return now }
+
+    r := gin.New()
+    r.Use(func(c *gin.Context) {
+        if key := c.GetHeader("X-Test-Key"); key != "" {
+            id, _ := strconv.Atoi(key)
+            c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(),
+                auth.Principal{Method: auth.MethodAPIKey, KeyID: uint(id)}))
+        }
+        c.Next()
+    })
+    r.GET("/", RateLimit(limiter), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+    send := func(ip, key string) *httptest.ResponseRecorder {
+        req := httptest.NewRequest(http.MethodGet, "/", nil)
+        req.RemoteAddr = ip + ":1234"
+        if key != "" {
+            req.Header.Set("X-Test-Key", key)
+        }
+        w := httptest.NewRecorder()
+        r.ServeHTTP(w, req)
+        return w
+    }
+
+    steps := []struct {
+        name      string
+        advance   time.Duration
+        ip, key   string
+        status    int
+        remaining string
+        retry     string
+    }{
+        {"first", 0, "10.0.0.1", "", http.StatusOK, "1", ""},
+        {"burst", 0, "10.0.0.1", "", http.StatusOK, "0", ""},
+        {"over", 0, "10.0.0.1", "", http.StatusTooManyRequests, "0", "1"},
+        {"other client", 0, "10.0.0.2", "", http.StatusOK, "1", ""},
+        {"API key behind the same IP", 0, "10.0.0.1", "7", http.StatusOK, "1", ""},
+        {"refilled one token", time.Second, "10.0.0.1", "", http.StatusOK, "0", ""},
+        {"empty again", 0, "10.0.0.1", "", http.StatusTooManyRequests, "0", "1"},
+        {"half a token", 1500 * time.Millisecond, "10.0.0.1", "", http.StatusOK, "0", ""},
+        {"retry rounds up", 0, "10.0.0.1", "", http.StatusTooManyRequests, "0", "1"},
+    }
+    for _, step := range steps {
+        now = now.Add(step.advance)
+        w := send(step.ip, step.key)
+        if w.Code != step.status {
+            t.Errorf("%s: status = %d, want %d", step.name, w.Code, step.status)
+        }
+        if got := w.Header().Get("X-RateLimit-Remaining"); got != step.remaining {
+            t.Errorf("%s: remaining = %q, want %q", step.name, got, step.remaining)
+        }
+        if got := w.Header().Get("Retry-After"); got != step.retry {
+            t.Errorf("%s: Retry-After = %q, want %q", step.name, got, step.retry)
+        }
+        if w.Header().Get("X-RateLimit-Limit") != "2" || w.Header().Get("X-RateLimit-Reset") == "" {
+            t.Errorf("%s: headers %v", step.name, w.Header())
+        }
+    }
+
+    // A minute later every bucket is full and is dropped on the next sweep.
+    now = now.Add(time.Minute)
+    send("10.0.0.3", "")
+    if len(limiter.buckets) != 1 {
+        t.Errorf("%d buckets kept after the sweep, want only the new one", len(limiter.buckets))
+    }
+}
+
+func TestRateLimitOff(t *testing.T) {
+    gin.SetMode(gin.TestMode)
+    r := gin.New()
+    r.GET("/", RateLimit(NewRateLimiter(0, 0)), func(c *gin.Context) { c.Status(http.StatusOK) })
+    for i := 0; i < 100; i++ {
+        w := httptest.NewRecorder()
+        r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+        if w.Code != http.StatusOK || w.Header().Get("X-RateLimit-Limit") != "" {
+            t.Fatalf("request %d: status %d, headers %v", i, w.Code, w.Header())
+        }
+    }
+}
+
+func TestBodyLimit(t *testing.T) {
+    gin.SetMode(gin.TestMode)
+    r := gin.New()
+    r.POST("/", BodyLimit(10), func(c *gin.Context) {
+        body, err := io.ReadAll(c.Request.Body)
+        if err != nil {
+            c.String(http.StatusBadRequest, err.Error())
+            return
+        }
+        c.String(http.StatusOK, string(body))
+    })
+
+    tests := []struct {
+        name    string
+        body    string
+        chunked bool
+        want    int
+    }{
+        {"within", "0123456789", false, http.StatusOK},
+        {"declared too large", "0123456789a", false, http.StatusRequestEntityTooLarge},
+        {"streamed too large", "0123456789a", true, http.StatusBadRequest},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+            if tt.chunked {
+                req.ContentLength = -1
+            }
+            w := httptest.NewRecorder()
+            r.ServeHTTP(w, req)
+            if w.Code != tt.want {
+                t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+            }
+        })
+    }
+}
@@ -0,0 +1,62 @@
+package telemetry
+
+import (
+    "context"
+    "fmt"
+    "os"
+
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+    "go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+    "go.opentelemetry.io/otel/propagation"
+    "go.opentelemetry.io/otel/sdk/resource"
+    sdktrace "go.opentelemetry.io/otel/sdk/trace"
+    semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+
+    "github.com/ryuzxy/FuncPro/internal/config"
+    "github.com/ryuzxy/FuncPro/internal/health"
+)
+
+// Setup installs the global tracer provider and the W3C traceparent and
+// baggage propagators. The returned function flushes spans still buffered
+// and must run before the process exits. With the none exporter, incoming
+// trace context is still propagated but nothing is recorded.
+func Setup(ctx context.Context, cfg config.TracingConfig) (func(context.Context) error, error) {
+    otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+        propagation.TraceContext{}, propagation.Baggage{},
+    ))
+
+    var exporter sdktrace.SpanExporter
+    var err error
+    switch cfg.Exporter {
+    case "otlp":
+        opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+        if cfg.Insecure {
+            opts = append(opts, otlptracehttp.WithInsecure())
+        }
+        exporter, err = otlptracehttp.New(ctx, opts...)
+    case "stdout":
+        exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+    default:
+        return func(context.Context) error { return nil }, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("creating %s trace exporter: %w", cfg.Exporter, err)
+    }
+
+    res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+        semconv.ServiceName(cfg.ServiceName),
+        semconv.ServiceVersion(health.BuildVersion()),
+    ))
+    if err != nil {
+        return nil, fmt.Errorf("describing trace resource: %w", err)
+    }
+
+    provider := sdktrace.NewTracerProvider(
+        sdktrace.WithBatcher(exporter),
+        sdktrace.WithResource(res),
+        sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+    )
+    otel.SetTracerProvider(provider)
+    return provider.Shutdown, nil
+}
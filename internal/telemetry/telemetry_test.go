@@ -0,0 +1,77 @@
+package telemetry
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gin-gonic/gin"
+    "go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+    "go.opentelemetry.io/otel"
+    sdktrace "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+    "github.com/ryuzxy/FuncPro/internal/config"
+)
+
+func TestIncomingTraceparentIsContinued(t *testing.T) {
+    shutdown, err := Setup(context.Background(), config.Default().Tracing)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer shutdown(context.Background())
+
+    recorder := tracetest.NewSpanRecorder()
+    provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+    defer otel.SetTracerProvider(otel.GetTracerProvider())
+    otel.SetTracerProvider(provider)
+
+    gin.SetMode(gin.TestMode)
+    r := gin.New()
+    r.Use(otelgin.Middleware("funcpro"))
+    r.GET("/komoditas/:id", func(c *gin.Context) {
+        _, span := otel.Tracer("test").Start(c.Request.Context(), "komoditas.GetKomoditasByID")
+        span.End()
+        c.Status(http.StatusOK)
+    })
+
+    const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+    req := httptest.NewRequest(http.MethodGet, "/komoditas/1", nil)
+    req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+    r.ServeHTTP(httptest.NewRecorder(), req)
+
+    spans := recorder.Ended()
+    if len(spans) != 2 {
+        t.Fatalf("recorded %d spans, want the request and the service span", len(spans))
+    }
+    service, request := spans[0], spans[1]
+    for _, s := range spans {
+        if got := s.SpanContext().TraceID().String(); got != traceID {
+            t.Errorf("span %s is in trace %s, want the caller's %s", s.Name(), got, traceID)
+        }
+    }
+    if service.Parent().SpanID() != request.SpanContext().SpanID() {
+        t.Errorf("service span is not a child of the request span")
+    }
+    if request.Name() != "GET /komoditas/:id" {
+        t.Errorf("request span is named %q", request.Name())
+    }
+}
+
+func TestSetupExporters(t *testing.T) {
+    for _, exporter := range []string{"none", "stdout", "otlp"} {
+        t.Run(exporter, func(t *testing.T) {
+            defer otel.SetTracerProvider(otel.GetTracerProvider())
+            cfg := config.Default().Tracing
+            cfg.Exporter = exporter
+            shutdown, err := Setup(context.Background(), cfg)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if err := shutdown(context.Background()); err != nil {
+                t.Errorf("shutdown: %v", err)
+            }
+        })
+    }
+}
@@ -0,0 +1,127 @@
+package alert
+
+import (
+	"time"
+
+	"github.com/ryuzxy/FuncPro/pkg/money"
+)
+
+// CreateRuleRequest DTO for creating alert rule. A secret is generated when
+// none is given, and the currency defaults to IDR.
+type CreateRuleRequest struct {
+	Name        string      `json:"name" binding:"required,min=1,max=100"`
+	KomoditasID uint        `json:"komoditas_id" binding:"required"`
+	MarketID    *uint       `json:"market_id"`
+	Condition   string      `json:"condition" binding:"required,oneof=above below change_pct trend_flip"`
+	Threshold   money.Money `json:"threshold" binding:"omitempty,gt=0"`
+	Currency    string      `json:"currency" binding:"omitempty,len=3"`
+	Percent     float64     `json:"percent"`
+	Days        int         `json:"days" binding:"omitempty,min=1,max=365"`
+	WebhookURL  string      `json:"webhook_url" binding:"required,url,max=500"`
+	Secret      string      `json:"secret" binding:"omitempty,min=16,max=100"`
+	Active      *bool       `json:"active"`
+}
+
+// UpdateRuleRequest DTO for updating alert rule. MarketID 0 clears the
+// rule's market.
+type UpdateRuleRequest struct {
+	Name       string      `json:"name" binding:"omitempty,min=1,max=100"`
+	MarketID   *uint       `json:"market_id"`
+	Condition  string      `json:"condition" binding:"omitempty,oneof=above below change_pct trend_flip"`
+	Threshold  money.Money `json:"threshold" binding:"omitempty,gt=0"`
+	Currency   string      `json:"currency" binding:"omitempty,len=3"`
+	Percent    float64     `json:"percent"`
+	Days       int         `json:"days" binding:"omitempty,min=1,max=365"`
+	WebhookURL string      `json:"webhook_url" binding:"omitempty,url,max=500"`
+	Secret     string      `json:"secret" binding:"omitempty,min=16,max=100"`
+	Active     *bool       `json:"active"`
+}
+
+// ListRulesQuery query parameters for listing alert rules
+type ListRulesQuery struct {
+	KomoditasID uint  `form:"komoditas_id"`
+	Active      *bool `form:"active"`
+}
+
+// RuleResponse DTO for alert rule response. Secret is only filled in right
+// after creation.
+type RuleResponse struct {
+	ID          uint        `json:"id"`
+	Name        string      `json:"name"`
+	KomoditasID uint        `json:"komoditas_id"`
+	MarketID    *uint       `json:"market_id"`
+	Condition   string      `json:"condition"`
+	Threshold   money.Money `json:"threshold"`
+	Currency    string      `json:"currency"`
+	Percent     float64     `json:"percent"`
+	Days        int         `json:"days"`
+	WebhookURL  string      `json:"webhook_url"`
+	Secret      string      `json:"secret,omitempty"`
+	Active      bool        `json:"active"`
+	Firing      bool        `json:"firing"`
+	LastTrend   string      `json:"last_trend"`
+	LastFiredAt *time.Time  `json:"last_fired_at"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// DeliveryResponse DTO for alert delivery response
+type DeliveryResponse struct {
+	ID            uint       `json:"id"`
+	RuleID        uint       `json:"rule_id"`
+	PriceID       uint       `json:"price_id"`
+	URL           string     `json:"url"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	ResponseCode  int        `json:"response_code"`
+	Error         string     `json:"error"`
+	NextAttemptAt *time.Time `json:"next_attempt_at"`
+	Payload       string     `json:"payload"`
+	DeliveredAt   *time.Time `json:"delivered_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// ToResponse converts AlertRule to response DTO
+func ToResponse(r AlertRule) RuleResponse {
+	return RuleResponse{
+		ID:          r.ID,
+		Name:        r.Name,
+		KomoditasID: r.KomoditasID,
+		MarketID:    r.MarketID,
+		Condition:   r.Condition,
+		Threshold:   r.Threshold,
+		Currency:    r.Currency,
+		Percent:     r.Percent,
+		Days:        r.Days,
+		WebhookURL:  r.WebhookURL,
+		Active:      r.Active,
+		Firing:      r.Firing,
+		LastTrend:   r.LastTrend,
+		LastFiredAt: r.LastFiredAt,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
+
+// ToDeliveryResponse converts AlertDelivery to response DTO
+func ToDeliveryResponse(d AlertDelivery) DeliveryResponse {
+	return DeliveryResponse{
+		ID:            d.ID,
+		RuleID:        d.RuleID,
+		PriceID:       d.PriceID,
+		URL:           d.URL,
+		Status:        d.Status,
+		Attempts:      d.Attempts,
+		ResponseCode:  d.ResponseCode,
+		Error:         d.Error,
+		NextAttemptAt: d.NextAttemptAt,
+		Payload:       d.Payload,
+		DeliveredAt:   d.DeliveredAt,
+		CreatedAt:     d.CreatedAt,
+	}
+}
+
+// ToFilter converts query parameters to a repository filter
+func (q ListRulesQuery) ToFilter() Filter {
+	return Filter{KomoditasID: q.KomoditasID, Active: q.Active}
+}
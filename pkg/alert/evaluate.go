@@ -0,0 +1,81 @@
+package alert
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ryuzxy/FuncPro/pkg/price"
+)
+
+// outcome is the result of evaluating a rule against its newest price.
+// Firing and Trend are the rule state to store afterwards.
+type outcome struct {
+	Fire    bool
+	Firing  bool
+	Trend   string
+	Message string
+}
+
+// lookback is how many days of history a rule needs before its latest price.
+// change_pct gets a week of slack to find a reference price when nothing was
+// reported exactly Days days earlier; trend_flip uses trendDays, the window
+// GetPriceAnalysis uses for its trend.
+func lookback(rule AlertRule, trendDays int) int {
+	switch rule.Condition {
+	case ConditionChangePct:
+		return rule.Days + 7
+	case ConditionTrendFlip:
+		return trendDays
+	}
+	return 0
+}
+
+// evaluate checks a rule against history, the rule's prices in the rule's
+// currency, ordered by date and ending with the newest one. Level conditions fire only when they turn
+// true; a trend flip fires whenever the trend differs from the last one seen.
+func evaluate(rule AlertRule, history []price.Price) outcome {
+	out := outcome{Firing: rule.Firing, Trend: rule.LastTrend}
+	if len(history) == 0 {
+		return out
+	}
+	latest := history[len(history)-1]
+
+	var holds bool
+	switch rule.Condition {
+	case ConditionAbove:
+		holds = latest.Value > rule.Threshold
+		out.Message = fmt.Sprintf("price %s is above %s %s", latest.Value, rule.Threshold, rule.Currency)
+	case ConditionBelow:
+		holds = latest.Value < rule.Threshold
+		out.Message = fmt.Sprintf("price %s is below %s %s", latest.Value, rule.Threshold, rule.Currency)
+	case ConditionChangePct:
+		reference, ok := referencePrice(history, latest.Date.AddDate(0, 0, -rule.Days))
+		if !ok {
+			return out
+		}
+		change := latest.Value.Sub(reference.Value).Ratio(reference.Value) * 100
+		holds = (rule.Percent > 0 && change >= rule.Percent) || (rule.Percent < 0 && change <= rule.Percent)
+		out.Message = fmt.Sprintf("price changed %.2f%% over %d days (%s to %s)",
+			change, rule.Days, reference.Value, latest.Value)
+	case ConditionTrendFlip:
+		trend := price.AnalyzePrices(history).Trend
+		out.Trend = trend
+		out.Fire = rule.LastTrend != "" && trend != rule.LastTrend
+		out.Message = fmt.Sprintf("trend flipped from %s to %s", rule.LastTrend, trend)
+		return out
+	}
+
+	out.Fire = holds && !rule.Firing
+	out.Firing = holds
+	return out
+}
+
+// referencePrice is the latest price on or before cutoff.
+func referencePrice(history []price.Price, cutoff time.Time) (price.Price, bool) {
+	for i := len(history) - 1; i >= 0; i-- {
+		if !history[i].Date.After(cutoff) {
+			return history[i], true
+		}
+	}
+	return price.Price{}, false
+}
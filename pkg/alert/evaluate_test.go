@@ -0,0 +1,135 @@
+package alert
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryuzxy/FuncPro/pkg/fx"
+	"github.com/ryuzxy/FuncPro/pkg/money"
+	"github.com/ryuzxy/FuncPro/pkg/price"
+)
+
+func day(n int) time.Time {
+	return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, n)
+}
+
+func series(values ...string) []price.Price {
+	out := make([]price.Price, len(values))
+	for i, v := range values {
+		out[i] = price.Price{Value: money.MustParse(v), Currency: "IDR", Date: day(i)}
+	}
+	return out
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name       string
+		rule       AlertRule
+		history    []price.Price
+		fire       bool
+		firing     bool
+		wantInText string
+	}{
+		{"above crosses", AlertRule{Condition: ConditionAbove, Threshold: money.FromInt(100), Currency: "IDR"},
+			series("90", "110"), true, true, "above 100 IDR"},
+		{"above already firing", AlertRule{Condition: ConditionAbove, Threshold: money.FromInt(100), Currency: "IDR", Firing: true},
+			series("110", "120"), false, true, ""},
+		{"above clears", AlertRule{Condition: ConditionAbove, Threshold: money.FromInt(100), Currency: "IDR", Firing: true},
+			series("110", "90"), false, false, ""},
+		{"below crosses", AlertRule{Condition: ConditionBelow, Threshold: money.FromInt(100), Currency: "USD"},
+			series("95"), true, true, "below 100 USD"},
+		{"rise over days", AlertRule{Condition: ConditionChangePct, Percent: 10, Days: 2},
+			series("100", "105", "111"), true, true, "11.00%"},
+		{"rise too small", AlertRule{Condition: ConditionChangePct, Percent: 20, Days: 2},
+			series("100", "105", "111"), false, false, ""},
+		{"fall over days", AlertRule{Condition: ConditionChangePct, Percent: -10, Days: 1},
+			series("100", "85"), true, true, "-15.00%"},
+		{"no reference price", AlertRule{Condition: ConditionChangePct, Percent: 10, Days: 5},
+			series("100", "200"), false, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := evaluate(tt.rule, tt.history)
+			if out.Fire != tt.fire || out.Firing != tt.firing {
+				t.Errorf("fire, firing = %v, %v, want %v, %v", out.Fire, out.Firing, tt.fire, tt.firing)
+			}
+			if tt.wantInText != "" && !strings.Contains(out.Message, tt.wantInText) {
+				t.Errorf("message %q lacks %q", out.Message, tt.wantInText)
+			}
+		})
+	}
+}
+
+// stubPrices serves prices stored in IDR and converts them at a fixed rate
+// when read in USD, like the real repository's WithValues.
+type stubPrices struct {
+	price.PriceRepository
+	values price.ValueOptions
+	prices []price.Price
+}
+
+const idrPerUSD = 16000
+
+func (r stubPrices) WithValues(opts price.ValueOptions) price.PriceRepository {
+	r.values = opts
+	return r
+}
+
+func (r stubPrices) GetLatestByKomoditasID(context.Context, uint) fx.Result[price.Price] {
+	return fx.Ok(r.prices[len(r.prices)-1])
+}
+
+func (r stubPrices) GetByKomoditasIDAndDateRange(context.Context, uint, time.Time, time.Time) fx.Result[[]price.Price] {
+	out := make([]price.Price, len(r.prices))
+	for i, p := range r.prices {
+		if r.values.Currency == "USD" {
+			p.Value = p.Value.Div(idrPerUSD)
+			p.Currency = "USD"
+		}
+		out[i] = p
+	}
+	return fx.Ok(out)
+}
+
+// stubRules records the state saved for a rule.
+type stubRules struct {
+	Repository
+	firing *bool
+}
+
+func (r stubRules) SaveState(_ context.Context, _ uint, firing bool, _ string, _ *time.Time) fx.Result[bool] {
+	*r.firing = firing
+	return fx.Ok(true)
+}
+
+func TestEvaluateRuleUsesRuleCurrency(t *testing.T) {
+	prices := stubPrices{prices: series("1500000", "1700000")}
+	tests := []struct {
+		name      string
+		currency  string
+		threshold money.Money
+		want      bool
+	}{
+		// 1,700,000 IDR is 106.25 USD.
+		{"USD threshold crossed", "USD", money.FromInt(100), true},
+		{"USD threshold not crossed", "USD", money.FromInt(110), false},
+		{"IDR threshold", "IDR", money.FromInt(1600000), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var firing bool
+			s := &service{repo: stubRules{firing: &firing}, prices: prices}
+			// Firing is already set so a crossing is recorded without a
+			// webhook being sent.
+			rule := AlertRule{ID: 1, Condition: ConditionAbove, Threshold: tt.threshold, Currency: tt.currency, Firing: true}
+			if err := s.evaluateRule(context.Background(), rule); err != nil {
+				t.Fatal(err)
+			}
+			if firing != tt.want {
+				t.Errorf("firing = %v, want %v", firing, tt.want)
+			}
+		})
+	}
+}
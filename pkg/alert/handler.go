@@ -0,0 +1,217 @@
+package alert
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ryuzxy/FuncPro/pkg/fx"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) GetAllRules(c *gin.Context) {
+	var q ListRulesQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	result := h.service.GetAllRules(c.Request.Context(), q.ToFilter())
+
+	fx.Match(
+		result,
+		func(data []AlertRule) any {
+			responses := fx.Map(data, ToResponse)
+			c.JSON(http.StatusOK, gin.H{
+				"success": true,
+				"data":    responses,
+				"count":   len(responses),
+			})
+			return nil
+		},
+		func(err error) any {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return nil
+		},
+	)
+}
+
+func (h *Handler) GetRuleByID(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	result := h.service.GetRuleByID(c.Request.Context(), id)
+
+	fx.Match(
+		result,
+		func(data *AlertRule) any {
+			c.JSON(http.StatusOK, gin.H{
+				"success": true,
+				"data":    ToResponse(*data),
+			})
+			return nil
+		},
+		func(err error) any {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return nil
+		},
+	)
+}
+
+func (h *Handler) CreateRule(c *gin.Context) {
+	var req CreateRuleRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result := h.service.CreateRule(c.Request.Context(), req)
+
+	fx.Match(
+		result,
+		func(data *AlertRule) any {
+			resp := ToResponse(*data)
+			resp.Secret = data.Secret
+			c.JSON(http.StatusCreated, gin.H{
+				"success": true,
+				"data":    resp,
+			})
+			return nil
+		},
+		func(err error) any {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return nil
+		},
+	)
+}
+
+func (h *Handler) UpdateRule(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateRuleRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result := h.service.UpdateRule(c.Request.Context(), id, req)
+
+	fx.Match(
+		result,
+		func(data *AlertRule) any {
+			c.JSON(http.StatusOK, gin.H{
+				"success": true,
+				"data":    ToResponse(*data),
+			})
+			return nil
+		},
+		func(err error) any {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return nil
+		},
+	)
+}
+
+func (h *Handler) DeleteRule(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	result := h.service.DeleteRule(c.Request.Context(), id)
+
+	fx.Match(
+		result,
+		func(success bool) any {
+			c.JSON(http.StatusOK, gin.H{
+				"success": true,
+				"message": "Alert rule deleted successfully",
+			})
+			return nil
+		},
+		func(err error) any {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return nil
+		},
+	)
+}
+
+func (h *Handler) GetDeliveries(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	result := h.service.GetDeliveries(c.Request.Context(), id)
+
+	fx.Match(
+		result,
+		func(data []AlertDelivery) any {
+			responses := fx.Map(data, ToDeliveryResponse)
+			c.JSON(http.StatusOK, gin.H{
+				"success": true,
+				"data":    responses,
+				"count":   len(responses),
+			})
+			return nil
+		},
+		func(err error) any {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return nil
+		},
+	)
+}
+
+func parseID(c *gin.Context) (uint, bool) {
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid ID format",
+		})
+		return 0, false
+	}
+	return uint(id64), true
+}
+
+func bindJSON[T any](c *gin.Context, target *T) bool {
+	if err := c.ShouldBindJSON(target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+package alert
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ryuzxy/FuncPro/pkg/money"
+)
+
+const (
+	ConditionAbove     = "above"
+	ConditionBelow     = "below"
+	ConditionChangePct = "change_pct"
+	ConditionTrendFlip = "trend_flip"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// AlertRule watches new prices of one komoditas, optionally in one market.
+// Prices are converted to Currency before a rule looks at them, so a rule
+// sees one series even when a komoditas is reported in several currencies.
+// Threshold applies to above/below, Percent and Days to change_pct: a
+// positive Percent fires on a rise of at least that much over Days days, a
+// negative one on a fall. Firing and LastTrend carry state between
+// evaluations so a rule fires once per crossing rather than on every price.
+type AlertRule struct {
+	ID          uint           `gorm:"primarykey" json:"id"`
+	Name        string         `gorm:"size:100;not null" json:"name"`
+	KomoditasID uint           `gorm:"not null;index" json:"komoditas_id"`
+	MarketID    *uint          `gorm:"index" json:"market_id"`
+	Condition   string         `gorm:"size:20;not null" json:"condition"`
+	Threshold   money.Money    `gorm:"type:numeric(18,4)" json:"threshold"`
+	Currency    string         `gorm:"size:3;not null;default:IDR" json:"currency"`
+	Percent     float64        `json:"percent"`
+	Days        int            `json:"days"`
+	WebhookURL  string         `gorm:"size:500;not null" json:"webhook_url"`
+	Secret      string         `gorm:"size:100;not null" json:"-"`
+	Active      bool           `gorm:"not null;default:true" json:"active"`
+	Firing      bool           `gorm:"not null;default:false" json:"firing"`
+	LastTrend   string         `gorm:"size:20" json:"last_trend"`
+	LastFiredAt *time.Time     `json:"last_fired_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// AlertDelivery is one webhook call for a fired rule, kept as a delivery
+// log with the exact payload that was signed. While it is pending,
+// NextAttemptAt is when it may be sent next; a dispatcher that claims it
+// pushes that time out by a lease so no other dispatcher sends it meanwhile.
+type AlertDelivery struct {
+	ID            uint       `gorm:"primarykey" json:"id"`
+	RuleID        uint       `gorm:"not null;index" json:"rule_id"`
+	PriceID       uint       `json:"price_id"`
+	URL           string     `gorm:"size:500;not null" json:"url"`
+	Payload       string     `gorm:"type:text;not null" json:"payload"`
+	Status        string     `gorm:"size:20;not null;index" json:"status"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	ResponseCode  int        `json:"response_code"`
+	Error         string     `gorm:"size:500" json:"error"`
+	NextAttemptAt *time.Time `gorm:"index" json:"next_attempt_at"`
+	DeliveredAt   *time.Time `json:"delivered_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
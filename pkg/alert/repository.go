@@ -0,0 +1,190 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ryuzxy/FuncPro/pkg/fx"
+)
+
+type Repository interface {
+	GetAll(ctx context.Context, filter Filter) fx.Result[[]AlertRule]
+	GetByID(ctx context.Context, id uint) fx.Result[*AlertRule]
+	GetActiveByKomoditas(ctx context.Context, komoditasIDs []uint) fx.Result[[]AlertRule]
+	Create(ctx context.Context, rule *AlertRule) fx.Result[*AlertRule]
+	Update(ctx context.Context, id uint, rule *AlertRule) fx.Result[*AlertRule]
+	Delete(ctx context.Context, id uint) fx.Result[bool]
+	SaveState(ctx context.Context, id uint, firing bool, trend string, firedAt *time.Time) fx.Result[bool]
+	GetDeliveries(ctx context.Context, ruleID uint) fx.Result[[]AlertDelivery]
+	CreateDelivery(ctx context.Context, delivery *AlertDelivery) fx.Result[*AlertDelivery]
+	UpdateDelivery(ctx context.Context, delivery *AlertDelivery) fx.Result[*AlertDelivery]
+	ClaimDueDeliveries(ctx context.Context, now, until time.Time, limit int) fx.Result[[]AlertDelivery]
+}
+
+type Filter struct {
+	KomoditasID uint
+	Active      *bool
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) GetAll(ctx context.Context, filter Filter) fx.Result[[]AlertRule] {
+	q := r.db.WithContext(ctx)
+	if filter.KomoditasID != 0 {
+		q = q.Where("komoditas_id = ?", filter.KomoditasID)
+	}
+	if filter.Active != nil {
+		q = q.Where("active = ?", *filter.Active)
+	}
+
+	var rules []AlertRule
+	if err := q.Order("id asc").Find(&rules).Error; err != nil {
+		return fx.Err[[]AlertRule](fmt.Errorf("failed to get alert rules: %w", err))
+	}
+	return fx.Ok(rules)
+}
+
+func (r *repository) GetByID(ctx context.Context, id uint) fx.Result[*AlertRule] {
+	var rule AlertRule
+	err := r.db.WithContext(ctx).First(&rule, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fx.Err[*AlertRule](fmt.Errorf("alert rule not found"))
+		}
+		return fx.Err[*AlertRule](fmt.Errorf("failed to get alert rule: %w", err))
+	}
+	return fx.Ok(&rule)
+}
+
+func (r *repository) GetActiveByKomoditas(ctx context.Context, komoditasIDs []uint) fx.Result[[]AlertRule] {
+	var rules []AlertRule
+	err := r.db.WithContext(ctx).
+		Where("active = ? AND komoditas_id IN ?", true, komoditasIDs).
+		Order("id asc").
+		Find(&rules).Error
+	if err != nil {
+		return fx.Err[[]AlertRule](fmt.Errorf("failed to get alert rules: %w", err))
+	}
+	return fx.Ok(rules)
+}
+
+func (r *repository) Create(ctx context.Context, rule *AlertRule) fx.Result[*AlertRule] {
+	if err := r.db.WithContext(ctx).Create(rule).Error; err != nil {
+		return fx.Err[*AlertRule](fmt.Errorf("failed to create alert rule: %w", err))
+	}
+	return fx.Ok(rule)
+}
+
+// Update saves every editable field, so switching a rule off or clearing its
+// market is not lost to Updates skipping zero values.
+func (r *repository) Update(ctx context.Context, id uint, rule *AlertRule) fx.Result[*AlertRule] {
+	err := r.db.WithContext(ctx).
+		Model(&AlertRule{}).
+		Where("id = ?", id).
+		Select("name", "market_id", "condition", "threshold", "currency", "percent", "days",
+			"webhook_url", "secret", "active", "firing", "last_trend").
+		Updates(rule).Error
+	if err != nil {
+		return fx.Err[*AlertRule](fmt.Errorf("failed to update alert rule: %w", err))
+	}
+	return r.GetByID(ctx, id)
+}
+
+// Delete also gives up the rule's pending deliveries, which would otherwise
+// wait for a retry that is never made.
+func (r *repository) Delete(ctx context.Context, id uint) fx.Result[bool] {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&AlertRule{}, id).Error; err != nil {
+			return err
+		}
+		return tx.Model(&AlertDelivery{}).
+			Where("rule_id = ? AND status = ?", id, DeliveryPending).
+			Updates(map[string]any{
+				"status":          DeliveryFailed,
+				"error":           "alert rule deleted",
+				"next_attempt_at": nil,
+			}).Error
+	})
+	if err != nil {
+		return fx.Err[bool](fmt.Errorf("failed to delete alert rule: %w", err))
+	}
+	return fx.Ok(true)
+}
+
+func (r *repository) SaveState(ctx context.Context, id uint, firing bool, trend string, firedAt *time.Time) fx.Result[bool] {
+	updates := map[string]any{"firing": firing, "last_trend": trend}
+	if firedAt != nil {
+		updates["last_fired_at"] = *firedAt
+	}
+	if err := r.db.WithContext(ctx).Model(&AlertRule{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return fx.Err[bool](fmt.Errorf("failed to save alert rule state: %w", err))
+	}
+	return fx.Ok(true)
+}
+
+func (r *repository) GetDeliveries(ctx context.Context, ruleID uint) fx.Result[[]AlertDelivery] {
+	var deliveries []AlertDelivery
+	err := r.db.WithContext(ctx).
+		Where("rule_id = ?", ruleID).
+		Order("created_at desc").
+		Limit(100).
+		Find(&deliveries).Error
+	if err != nil {
+		return fx.Err[[]AlertDelivery](fmt.Errorf("failed to get alert deliveries: %w", err))
+	}
+	return fx.Ok(deliveries)
+}
+
+func (r *repository) CreateDelivery(ctx context.Context, delivery *AlertDelivery) fx.Result[*AlertDelivery] {
+	if err := r.db.WithContext(ctx).Create(delivery).Error; err != nil {
+		return fx.Err[*AlertDelivery](fmt.Errorf("failed to create alert delivery: %w", err))
+	}
+	return fx.Ok(delivery)
+}
+
+func (r *repository) UpdateDelivery(ctx context.Context, delivery *AlertDelivery) fx.Result[*AlertDelivery] {
+	if err := r.db.WithContext(ctx).Save(delivery).Error; err != nil {
+		return fx.Err[*AlertDelivery](fmt.Errorf("failed to update alert delivery: %w", err))
+	}
+	return fx.Ok(delivery)
+}
+
+// ClaimDueDeliveries takes up to limit pending deliveries of live rules whose
+// next attempt is due and moves that attempt to until. Pending deliveries
+// without a next attempt predate scheduled retries and are due. Rows another
+// dispatcher is claiming at the same moment are skipped.
+func (r *repository) ClaimDueDeliveries(ctx context.Context, now, until time.Time, limit int) fx.Result[[]AlertDelivery] {
+	var deliveries []AlertDelivery
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND (next_attempt_at IS NULL OR next_attempt_at <= ?)", DeliveryPending, now).
+			Where("rule_id IN (SELECT id FROM alert_rules WHERE deleted_at IS NULL)").
+			Order("next_attempt_at asc NULLS FIRST").
+			Limit(limit).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(deliveries))
+		for i := range deliveries {
+			ids[i] = deliveries[i].ID
+			deliveries[i].NextAttemptAt = &until
+		}
+		return tx.Model(&AlertDelivery{}).Where("id IN ?", ids).Update("next_attempt_at", until).Error
+	})
+	if err != nil {
+		return fx.Err[[]AlertDelivery](fmt.Errorf("failed to claim alert deliveries: %w", err))
+	}
+	return fx.Ok(deliveries)
+}
@@ -0,0 +1,478 @@
+package alert
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/ryuzxy/FuncPro/pkg/currency"
+	"github.com/ryuzxy/FuncPro/pkg/fx"
+	"github.com/ryuzxy/FuncPro/pkg/komoditas"
+	"github.com/ryuzxy/FuncPro/pkg/money"
+	"github.com/ryuzxy/FuncPro/pkg/price"
+)
+
+var tracer = otel.Tracer("github.com/ryuzxy/FuncPro/pkg/alert")
+
+const eventPriceAlert = "price.alert"
+
+type Service interface {
+	GetAllRules(ctx context.Context, filter Filter) fx.Result[[]AlertRule]
+	GetRuleByID(ctx context.Context, id uint) fx.Result[*AlertRule]
+	CreateRule(ctx context.Context, req CreateRuleRequest) fx.Result[*AlertRule]
+	UpdateRule(ctx context.Context, id uint, req UpdateRuleRequest) fx.Result[*AlertRule]
+	DeleteRule(ctx context.Context, id uint) fx.Result[bool]
+	GetDeliveries(ctx context.Context, ruleID uint) fx.Result[[]AlertDelivery]
+	// PricesCreated is a price.CreatedListener. It only notes which
+	// komoditas and markets got new prices; rules are evaluated in the
+	// background, and batches arriving meanwhile are merged, never dropped.
+	PricesCreated(ctx context.Context, prices []price.Price)
+	// OnFired registers a listener told about every alert fired. Like
+	// price.Service.OnCreated, it is meant for wiring, before prices arrive.
+	OnFired(listener FiredListener)
+	Close()
+}
+
+// FiredListener is told about a rule that fired, after its webhook delivery
+// is queued. It runs on the evaluator, so it must not block.
+type FiredListener func(rule AlertRule)
+
+type service struct {
+	repo       Repository
+	prices     price.PriceRepository
+	komoditas  komoditas.Repository
+	dispatcher *Dispatcher
+	trendDays  int
+	fired      []FiredListener
+
+	// pending holds the newest new price per komoditas and market (0 for
+	// none) until the evaluator takes it; wake tells it there is some.
+	mu      sync.Mutex
+	pending map[uint]map[uint]price.Price
+	wake    chan struct{}
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewService starts the background evaluator. trendDays is the window trend
+// flips are judged over and should match the price service's analysis
+// window; 0 uses price.DefaultWindowDays.
+func NewService(repo Repository, prices price.PriceRepository, komoditas komoditas.Repository, dispatcher *Dispatcher, trendDays int) Service {
+	if trendDays <= 0 {
+		trendDays = price.DefaultWindowDays
+	}
+	s := &service{
+		repo:       repo,
+		prices:     prices,
+		komoditas:  komoditas,
+		dispatcher: dispatcher,
+		trendDays:  trendDays,
+		pending:    make(map[uint]map[uint]price.Price),
+		wake:       make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.run()
+	return s
+}
+
+// Payload is the JSON body posted to a rule's webhook.
+type Payload struct {
+	Event       string       `json:"event"`
+	Rule        PayloadRule  `json:"rule"`
+	KomoditasID uint         `json:"komoditas_id"`
+	MarketID    *uint        `json:"market_id"`
+	Price       PayloadPrice `json:"price"`
+	Message     string       `json:"message"`
+	FiredAt     time.Time    `json:"fired_at"`
+}
+
+type PayloadRule struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	Condition string `json:"condition"`
+}
+
+type PayloadPrice struct {
+	ID       uint        `json:"id"`
+	Value    money.Money `json:"value"`
+	Currency string      `json:"currency"`
+	Date     time.Time   `json:"date"`
+	MarketID *uint       `json:"market_id"`
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating webhook secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func validateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("webhook_url must be an http or https URL")
+	}
+	return nil
+}
+
+func validateRule(rule *AlertRule) error {
+	code, err := currency.NormalizeCode(rule.Currency)
+	if err != nil {
+		return err
+	}
+	rule.Currency = code
+
+	switch rule.Condition {
+	case ConditionAbove, ConditionBelow:
+		if rule.Threshold <= 0 {
+			return fmt.Errorf("threshold must be > 0 for %s rules", rule.Condition)
+		}
+	case ConditionChangePct:
+		if rule.Percent == 0 {
+			return fmt.Errorf("percent must be non-zero for change_pct rules")
+		}
+		if rule.Days < 1 {
+			return fmt.Errorf("days must be at least 1 for change_pct rules")
+		}
+	case ConditionTrendFlip:
+	default:
+		return fmt.Errorf("invalid condition %q", rule.Condition)
+	}
+	return validateWebhookURL(rule.WebhookURL)
+}
+
+func (s *service) GetAllRules(ctx context.Context, filter Filter) fx.Result[[]AlertRule] {
+	ctx, span := tracer.Start(ctx, "alert.GetAllRules")
+	defer span.End()
+
+	return s.repo.GetAll(ctx, filter)
+}
+
+func (s *service) GetRuleByID(ctx context.Context, id uint) fx.Result[*AlertRule] {
+	ctx, span := tracer.Start(ctx, "alert.GetRuleByID")
+	defer span.End()
+
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *service) CreateRule(ctx context.Context, req CreateRuleRequest) fx.Result[*AlertRule] {
+	ctx, span := tracer.Start(ctx, "alert.CreateRule")
+	defer span.End()
+
+	if s.komoditas.GetByID(ctx, req.KomoditasID).IsErr() {
+		return fx.Err[*AlertRule](fmt.Errorf("komoditas %d not found", req.KomoditasID))
+	}
+
+	rule := &AlertRule{
+		Name:        req.Name,
+		KomoditasID: req.KomoditasID,
+		MarketID:    req.MarketID,
+		Condition:   req.Condition,
+		Threshold:   req.Threshold,
+		Currency:    req.Currency,
+		Percent:     req.Percent,
+		Days:        req.Days,
+		WebhookURL:  req.WebhookURL,
+		Secret:      req.Secret,
+		Active:      true,
+	}
+	if req.Active != nil {
+		rule.Active = *req.Active
+	}
+	if rule.Currency == "" {
+		rule.Currency = currency.Default
+	}
+	if err := validateRule(rule); err != nil {
+		return fx.Err[*AlertRule](err)
+	}
+	if rule.Secret == "" {
+		secret, err := newSecret()
+		if err != nil {
+			return fx.Err[*AlertRule](err)
+		}
+		rule.Secret = secret
+	}
+
+	return s.repo.Create(ctx, rule)
+}
+
+// UpdateRule resets the rule's firing state whenever what it watches
+// changes, so the next price is judged afresh. A market_id of 0 widens the
+// rule back to every market.
+func (s *service) UpdateRule(ctx context.Context, id uint, req UpdateRuleRequest) fx.Result[*AlertRule] {
+	ctx, span := tracer.Start(ctx, "alert.UpdateRule")
+	defer span.End()
+
+	existing, err := s.repo.GetByID(ctx, id).Unwrap()
+	if err != nil {
+		return fx.Err[*AlertRule](fmt.Errorf("alert rule not found: %w", err))
+	}
+
+	reset := false
+	if req.Name != "" {
+		existing.Name = req.Name
+	}
+	if req.MarketID != nil {
+		existing.MarketID = req.MarketID
+		if *req.MarketID == 0 {
+			existing.MarketID = nil
+		}
+		reset = true
+	}
+	if req.Condition != "" {
+		existing.Condition = req.Condition
+		reset = true
+	}
+	if req.Threshold != 0 {
+		existing.Threshold = req.Threshold
+		reset = true
+	}
+	if req.Currency != "" {
+		existing.Currency = req.Currency
+		reset = true
+	}
+	if req.Percent != 0 {
+		existing.Percent = req.Percent
+		reset = true
+	}
+	if req.Days != 0 {
+		existing.Days = req.Days
+		reset = true
+	}
+	if req.WebhookURL != "" {
+		existing.WebhookURL = req.WebhookURL
+	}
+	if req.Secret != "" {
+		existing.Secret = req.Secret
+	}
+	if req.Active != nil {
+		existing.Active = *req.Active
+	}
+	if reset {
+		existing.Firing = false
+		existing.LastTrend = ""
+	}
+	if err := validateRule(existing); err != nil {
+		return fx.Err[*AlertRule](err)
+	}
+
+	return s.repo.Update(ctx, id, existing)
+}
+
+func (s *service) DeleteRule(ctx context.Context, id uint) fx.Result[bool] {
+	ctx, span := tracer.Start(ctx, "alert.DeleteRule")
+	defer span.End()
+
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *service) GetDeliveries(ctx context.Context, ruleID uint) fx.Result[[]AlertDelivery] {
+	ctx, span := tracer.Start(ctx, "alert.GetDeliveries")
+	defer span.End()
+
+	if _, err := s.repo.GetByID(ctx, ruleID).Unwrap(); err != nil {
+		return fx.Err[[]AlertDelivery](err)
+	}
+	return s.repo.GetDeliveries(ctx, ruleID)
+}
+
+// OnFired adds listener to those told about every alert fired.
+func (s *service) OnFired(listener FiredListener) {
+	s.fired = append(s.fired, listener)
+}
+
+// PricesCreated keeps one price per komoditas and market, as rules read
+// their history from the repository and only need to know where to look.
+func (s *service) PricesCreated(ctx context.Context, prices []price.Price) {
+	if len(prices) == 0 {
+		return
+	}
+	s.mu.Lock()
+	for _, p := range prices {
+		byMarket := s.pending[p.KomoditasID]
+		if byMarket == nil {
+			byMarket = make(map[uint]price.Price)
+			s.pending[p.KomoditasID] = byMarket
+		}
+		var market uint
+		if p.MarketID != nil {
+			market = *p.MarketID
+		}
+		byMarket[market] = p
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// takePending empties the pending set.
+func (s *service) takePending() []price.Price {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var prices []price.Price
+	for _, byMarket := range s.pending {
+		for _, p := range byMarket {
+			prices = append(prices, p)
+		}
+	}
+	s.pending = make(map[uint]map[uint]price.Price)
+	return prices
+}
+
+// Close stops evaluating new prices and then the webhook dispatcher.
+func (s *service) Close() {
+	close(s.done)
+	s.wg.Wait()
+	s.dispatcher.Close()
+}
+
+func (s *service) run() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.wake:
+			if prices := s.takePending(); len(prices) > 0 {
+				s.evaluateBatch(context.Background(), prices)
+			}
+		}
+	}
+}
+
+// evaluateBatch checks every active rule on the komoditas in the batch.
+// Rules are evaluated one after another so a rule's state is never updated
+// by two batches at once.
+func (s *service) evaluateBatch(ctx context.Context, prices []price.Price) {
+	seen := make(map[uint]bool)
+	var ids []uint
+	for _, p := range prices {
+		if !seen[p.KomoditasID] {
+			seen[p.KomoditasID] = true
+			ids = append(ids, p.KomoditasID)
+		}
+	}
+
+	rules, err := s.repo.GetActiveByKomoditas(ctx, ids).Unwrap()
+	if err != nil {
+		slog.ErrorContext(ctx, "alert: reading rules", "error", err)
+		return
+	}
+	for _, rule := range rules {
+		if !touches(rule, prices) {
+			continue
+		}
+		if err := s.evaluateRule(ctx, rule); err != nil {
+			slog.ErrorContext(ctx, "alert: evaluating rule", "rule_id", rule.ID, "error", err)
+		}
+	}
+}
+
+func touches(rule AlertRule, prices []price.Price) bool {
+	for _, p := range prices {
+		if matches(rule, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func matches(rule AlertRule, p price.Price) bool {
+	if p.KomoditasID != rule.KomoditasID {
+		return false
+	}
+	return rule.MarketID == nil || (p.MarketID != nil && *p.MarketID == *rule.MarketID)
+}
+
+// evaluateRule reads the rule's history converted to the rule's currency,
+// so thresholds and percentage changes never compare amounts in different
+// currencies.
+func (s *service) evaluateRule(ctx context.Context, rule AlertRule) error {
+	latest, err := s.prices.GetLatestByKomoditasID(ctx, rule.KomoditasID).Unwrap()
+	if err != nil {
+		return err
+	}
+	end := latest.Date
+	start := end.AddDate(0, 0, -lookback(rule, s.trendDays))
+
+	converted := s.prices.WithValues(price.ValueOptions{Currency: rule.Currency})
+	all, err := converted.GetByKomoditasIDAndDateRange(ctx, rule.KomoditasID, start, end).Unwrap()
+	if err != nil {
+		return err
+	}
+	history := make([]price.Price, 0, len(all))
+	for _, p := range all {
+		if matches(rule, p) {
+			history = append(history, p)
+		}
+	}
+	if len(history) == 0 {
+		return nil
+	}
+	sort.SliceStable(history, func(i, j int) bool { return history[i].Date.Before(history[j].Date) })
+
+	out := evaluate(rule, history)
+	var firedAt *time.Time
+	if out.Fire {
+		now := time.Now()
+		firedAt = &now
+		if err := s.fire(ctx, rule, history[len(history)-1], out.Message, now); err != nil {
+			return err
+		}
+		for _, listener := range s.fired {
+			listener(rule)
+		}
+	}
+	_, err = s.repo.SaveState(ctx, rule.ID, out.Firing, out.Trend, firedAt).Unwrap()
+	return err
+}
+
+func (s *service) fire(ctx context.Context, rule AlertRule, p price.Price, message string, at time.Time) error {
+	body, err := json.Marshal(Payload{
+		Event:       eventPriceAlert,
+		Rule:        PayloadRule{ID: rule.ID, Name: rule.Name, Condition: rule.Condition},
+		KomoditasID: rule.KomoditasID,
+		MarketID:    rule.MarketID,
+		Price: PayloadPrice{
+			ID:       p.ID,
+			Value:    p.Value,
+			Currency: p.Currency,
+			Date:     p.Date,
+			MarketID: p.MarketID,
+		},
+		Message: message,
+		FiredAt: at,
+	})
+	if err != nil {
+		return err
+	}
+
+	delivery, err := s.repo.CreateDelivery(ctx, &AlertDelivery{
+		RuleID:  rule.ID,
+		PriceID: p.ID,
+		URL:     rule.WebhookURL,
+		Payload: string(body),
+		Status:  DeliveryPending,
+		// Claimed right away, as it is handed straight to the dispatcher.
+		NextAttemptAt: leaseFrom(at),
+	}).Unwrap()
+	if err != nil {
+		return err
+	}
+	s.dispatcher.Enqueue(rule.Secret, delivery)
+	return nil
+}
@@ -0,0 +1,123 @@
+package alert
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ryuzxy/FuncPro/pkg/fx"
+	"github.com/ryuzxy/FuncPro/pkg/money"
+	"github.com/ryuzxy/FuncPro/pkg/price"
+)
+
+func TestPricesCreatedMergesBatches(t *testing.T) {
+	// No evaluator runs, so every batch piles up behind the first.
+	s := &service{pending: make(map[uint]map[uint]price.Price), wake: make(chan struct{}, 1)}
+	market := uint(3)
+	for i := 1; i <= 1000; i++ {
+		s.PricesCreated(context.Background(), []price.Price{
+			{ID: uint(i), KomoditasID: 1, Value: money.FromInt(int64(i))},
+			{ID: uint(i), KomoditasID: 1, MarketID: &market, Value: money.FromInt(int64(i))},
+			{ID: uint(i), KomoditasID: uint(2 + i%2), Value: money.FromInt(int64(i))},
+		})
+	}
+	if len(s.wake) != 1 {
+		t.Errorf("wake holds %d signals, want 1", len(s.wake))
+	}
+
+	prices := s.takePending()
+	type key struct{ komoditas, market uint }
+	got := make(map[key]uint)
+	for _, p := range prices {
+		k := key{komoditas: p.KomoditasID}
+		if p.MarketID != nil {
+			k.market = *p.MarketID
+		}
+		got[k] = p.ID
+	}
+	want := map[key]uint{{1, 0}: 1000, {1, 3}: 1000, {2, 0}: 1000, {3, 0}: 999}
+	if len(prices) != len(want) {
+		t.Errorf("took %d prices, want %d", len(prices), len(want))
+	}
+	for k, id := range want {
+		if got[k] != id {
+			t.Errorf("komoditas %d market %d kept price %d, want %d", k.komoditas, k.market, got[k], id)
+		}
+	}
+
+	if rest := s.takePending(); len(rest) != 0 {
+		t.Errorf("pending not emptied, %d left", len(rest))
+	}
+}
+
+func TestTouches(t *testing.T) {
+	market, other := uint(1), uint(2)
+	anyMarket := AlertRule{KomoditasID: 1}
+	oneMarket := AlertRule{KomoditasID: 1, MarketID: &market}
+	tests := []struct {
+		name  string
+		rule  AlertRule
+		price price.Price
+		want  bool
+	}{
+		{"any market", anyMarket, price.Price{KomoditasID: 1, MarketID: &other}, true},
+		{"other komoditas", anyMarket, price.Price{KomoditasID: 2}, false},
+		{"same market", oneMarket, price.Price{KomoditasID: 1, MarketID: &market}, true},
+		{"other market", oneMarket, price.Price{KomoditasID: 1, MarketID: &other}, false},
+		{"no market", oneMarket, price.Price{KomoditasID: 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := touches(tt.rule, []price.Price{tt.price}); got != tt.want {
+				t.Errorf("touches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// ruleStore serves one rule and keeps what Update stores.
+type ruleStore struct {
+	Repository
+	rule AlertRule
+}
+
+func (r *ruleStore) GetByID(_ context.Context, _ uint) fx.Result[*AlertRule] {
+	rule := r.rule
+	return fx.Ok(&rule)
+}
+
+func (r *ruleStore) Update(_ context.Context, _ uint, rule *AlertRule) fx.Result[*AlertRule] {
+	r.rule = *rule
+	return fx.Ok(rule)
+}
+
+func TestUpdateRuleMarket(t *testing.T) {
+	market, other, zero := uint(4), uint(5), uint(0)
+	tests := []struct {
+		name string
+		req  *uint
+		want *uint
+	}{
+		{"left alone", nil, &market},
+		{"moved", &other, &other},
+		{"cleared", &zero, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &ruleStore{rule: AlertRule{
+				ID: 1, Condition: ConditionTrendFlip, Currency: "IDR", MarketID: &market,
+				WebhookURL: "https://example.com/hook", Firing: true,
+			}}
+			s := &service{repo: repo}
+			if _, err := s.UpdateRule(context.Background(), 1, UpdateRuleRequest{MarketID: tt.req}).Unwrap(); err != nil {
+				t.Fatal(err)
+			}
+			got := repo.rule.MarketID
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("market = %v, want %v", got, tt.want)
+			}
+			if repo.rule.Firing != (tt.req == nil) {
+				t.Errorf("firing = %v after market change %v", repo.rule.Firing, tt.req != nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-FuncPro-Signature"
+	TimestampHeader = "X-FuncPro-Timestamp"
+	EventHeader     = "X-FuncPro-Event"
+	DeliveryHeader  = "X-FuncPro-Delivery"
+)
+
+const (
+	maxAttempts     = 5
+	firstBackoff    = time.Second
+	deliveryTimeout = 10 * time.Second
+	deliveryQueue   = 256
+	deliveryWorkers = 4
+	pollInterval    = time.Second
+	// deliveryLease outlasts the longest a claimed delivery can wait in a
+	// full queue plus its own attempt, so it is not claimed again while a
+	// worker still holds it.
+	deliveryLease = (deliveryQueue/deliveryWorkers + 1) * deliveryTimeout
+)
+
+// Sign returns the signature header value for a payload: an HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the rule secret. Including the timestamp
+// lets receivers reject replayed deliveries.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type deliveryJob struct {
+	secret   string
+	delivery *AlertDelivery
+}
+
+// Dispatcher posts fired alerts to their webhooks from a small worker pool
+// and records every attempt in the delivery log. Workers make one attempt
+// per job: a delivery that should be retried is stored with the time of its
+// next attempt, and a poller hands deliveries back to the workers once they
+// are due, so waiting out a backoff never holds a worker.
+type Dispatcher struct {
+	repo   Repository
+	client *http.Client
+	jobs   chan deliveryJob
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewDispatcher(repo Repository) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		repo:   repo,
+		client: &http.Client{Timeout: deliveryTimeout},
+		jobs:   make(chan deliveryJob, deliveryQueue),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	for i := 0; i < deliveryWorkers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+	d.wg.Add(1)
+	go d.poll()
+	return d
+}
+
+// leaseFrom is the next attempt time of a delivery claimed at now.
+func leaseFrom(now time.Time) *time.Time {
+	until := now.Add(deliveryLease)
+	return &until
+}
+
+// backoff is the wait before the attempt following the given one.
+func backoff(attempts int) time.Duration {
+	return firstBackoff << (attempts - 1)
+}
+
+// Enqueue hands a delivery claimed with leaseFrom to the workers without
+// blocking. When the queue is full the delivery is made due instead, and the
+// poller picks it up once there is room.
+func (d *Dispatcher) Enqueue(secret string, delivery *AlertDelivery) {
+	select {
+	case d.jobs <- deliveryJob{secret: secret, delivery: delivery}:
+	default:
+		now := time.Now()
+		delivery.NextAttemptAt = &now
+		d.save(context.WithoutCancel(d.ctx), delivery)
+	}
+}
+
+// save writes a delivery's state. A failed write leaves the row as it was,
+// claimed until its lease runs out, so it is logged rather than dropped.
+func (d *Dispatcher) save(ctx context.Context, delivery *AlertDelivery) {
+	if _, err := d.repo.UpdateDelivery(ctx, delivery).Unwrap(); err != nil {
+		slog.Error("alert: saving delivery", "delivery_id", delivery.ID, "status", delivery.Status,
+			"attempts", delivery.Attempts, "error", err)
+	}
+}
+
+// Close stops the workers and the poller. Deliveries still queued are made
+// due again, so the next start sends them at once instead of after their
+// lease runs out.
+func (d *Dispatcher) Close() {
+	d.cancel()
+	d.wg.Wait()
+	now := time.Now()
+	for {
+		select {
+		case job := <-d.jobs:
+			job.delivery.NextAttemptAt = &now
+			d.save(context.Background(), job.delivery)
+		default:
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case job := <-d.jobs:
+			d.deliver(job)
+		}
+	}
+}
+
+// poll starts with a claim of its own, so deliveries that were pending when
+// the process last stopped are sent as soon as it is back.
+func (d *Dispatcher) poll() {
+	defer d.wg.Done()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		d.enqueueDue()
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// enqueueDue claims as many due deliveries as the queue has room for.
+// Enqueue may take that room meanwhile, so sending can still block; on
+// Close the deliveries not yet queued are made due again.
+func (d *Dispatcher) enqueueDue() {
+	free := cap(d.jobs) - len(d.jobs)
+	if free == 0 {
+		return
+	}
+	now := time.Now()
+	deliveries, err := d.repo.ClaimDueDeliveries(d.ctx, now, *leaseFrom(now), free).Unwrap()
+	if err != nil {
+		if d.ctx.Err() == nil {
+			slog.Error("alert: claiming due deliveries", "error", err)
+		}
+		return
+	}
+
+	secrets := make(map[uint]string)
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		secret, ok := secrets[delivery.RuleID]
+		if !ok {
+			// A delivery whose rule cannot be read stays claimed and is
+			// tried again once its lease runs out.
+			rule, err := d.repo.GetByID(d.ctx, delivery.RuleID).Unwrap()
+			if err != nil {
+				slog.Error("alert: reading rule of delivery", "delivery_id", delivery.ID, "rule_id", delivery.RuleID, "error", err)
+				continue
+			}
+			secret = rule.Secret
+			secrets[delivery.RuleID] = secret
+		}
+		select {
+		case d.jobs <- deliveryJob{secret: secret, delivery: delivery}:
+		case <-d.ctx.Done():
+			now := time.Now()
+			for j := i; j < len(deliveries); j++ {
+				deliveries[j].NextAttemptAt = &now
+				d.save(context.WithoutCancel(d.ctx), &deliveries[j])
+			}
+			return
+		}
+	}
+}
+
+// deliver makes one attempt and stores its outcome: delivered, failed for
+// good, or pending with the time of the next attempt.
+func (d *Dispatcher) deliver(job deliveryJob) {
+	delivery := job.delivery
+	// The log outlives shutdown, so it is written with a fresh context.
+	store := context.WithoutCancel(d.ctx)
+
+	delivery.Attempts++
+	code, err := d.post(job.secret, delivery)
+	now := time.Now()
+	if err != nil && d.ctx.Err() != nil {
+		// Cut short by Close: the attempt does not count and the delivery
+		// is due as soon as a dispatcher runs again.
+		delivery.Attempts--
+		delivery.NextAttemptAt = &now
+		d.save(store, delivery)
+		return
+	}
+	delivery.ResponseCode = code
+	delivery.NextAttemptAt = nil
+	switch {
+	case err != nil:
+		delivery.Error = truncate(err.Error(), 500)
+	case code < 200 || code >= 300:
+		delivery.Error = fmt.Sprintf("webhook responded %d", code)
+	default:
+		delivery.Status = DeliveryDelivered
+		delivery.Error = ""
+		delivery.DeliveredAt = &now
+		d.save(store, delivery)
+		return
+	}
+
+	switch {
+	// Other client errors will not go away by retrying.
+	case err == nil && code != http.StatusTooManyRequests && code < 500:
+		delivery.Status = DeliveryFailed
+	case delivery.Attempts >= maxAttempts:
+		delivery.Status = DeliveryFailed
+		slog.Warn("alert: delivery failed", "delivery_id", delivery.ID, "url", delivery.URL,
+			"attempts", delivery.Attempts, "error", delivery.Error)
+	default:
+		next := now.Add(backoff(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+	}
+	d.save(store, delivery)
+}
+
+func (d *Dispatcher) post(secret string, delivery *AlertDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, eventPriceAlert)
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
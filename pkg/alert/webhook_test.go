@@ -0,0 +1,235 @@
+package alert
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ryuzxy/FuncPro/pkg/fx"
+)
+
+// The expected signatures were computed independently with
+// HMAC-SHA256(secret, "<timestamp>.<body>").
+func TestSign(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      string
+		want      string
+	}{
+		{"payload", "secret", 1700000000, `{"a":1}`, "sha256=49f24e537407743fa4a0242bb63b94b9a47ee99cbbe071ccd8a22550ae411686"},
+		{"empty", "", 0, "", "sha256=b849d5a581847b281957065739df36df2463d1977ea8d6e1e4e6cf33fadc68c3"},
+		{"negative timestamp", "k", -5, "body", "sha256=847a3f8fceb3fcd69ec27bee7c961ad113e58d226450827ca68470b53c67d17c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sign(tt.secret, tt.timestamp, []byte(tt.body)); got != tt.want {
+				t.Errorf("Sign() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if Sign("a", 1, []byte("x")) == Sign("b", 1, []byte("x")) {
+		t.Error("signature does not depend on the secret")
+	}
+	if Sign("a", 1, []byte("x")) == Sign("a", 2, []byte("x")) {
+		t.Error("signature does not depend on the timestamp")
+	}
+}
+
+// deliveryLog keeps the last state stored for each delivery and hands out
+// due deliveries once.
+type deliveryLog struct {
+	Repository
+	mu    sync.Mutex
+	saved map[uint]AlertDelivery
+	due   []AlertDelivery
+}
+
+func (r *deliveryLog) UpdateDelivery(_ context.Context, d *AlertDelivery) fx.Result[*AlertDelivery] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.saved[d.ID] = *d
+	return fx.Ok(d)
+}
+
+func (r *deliveryLog) ClaimDueDeliveries(_ context.Context, _, until time.Time, limit int) fx.Result[[]AlertDelivery] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := min(limit, len(r.due))
+	claimed := r.due[:n]
+	r.due = r.due[n:]
+	for i := range claimed {
+		claimed[i].NextAttemptAt = &until
+	}
+	return fx.Ok(claimed)
+}
+
+func (r *deliveryLog) GetByID(_ context.Context, id uint) fx.Result[*AlertRule] {
+	return fx.Ok(&AlertRule{ID: id, Secret: "s"})
+}
+
+func (r *deliveryLog) get(id uint) AlertDelivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.saved[id]
+}
+
+func TestDeliverMakesOneAttempt(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     int
+		attempts int
+		status   string
+		retry    bool
+	}{
+		{"delivered", http.StatusNoContent, 0, DeliveryDelivered, false},
+		{"server error is retried", http.StatusBadGateway, 0, DeliveryPending, true},
+		{"rate limit is retried", http.StatusTooManyRequests, 2, DeliveryPending, true},
+		{"client error fails", http.StatusBadRequest, 0, DeliveryFailed, false},
+		{"last attempt fails", http.StatusInternalServerError, maxAttempts - 1, DeliveryFailed, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var signature string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				signature = r.Header.Get(SignatureHeader)
+				w.WriteHeader(tt.code)
+			}))
+			defer srv.Close()
+
+			repo := &deliveryLog{saved: make(map[uint]AlertDelivery)}
+			d := &Dispatcher{repo: repo, client: srv.Client(), ctx: context.Background()}
+			before := time.Now()
+			d.deliver(deliveryJob{secret: "s", delivery: &AlertDelivery{
+				ID: 1, URL: srv.URL, Payload: `{}`, Status: DeliveryPending, Attempts: tt.attempts,
+			}})
+
+			got := repo.get(1)
+			if signature == "" {
+				t.Error("request was not signed")
+			}
+			if got.Status != tt.status || got.Attempts != tt.attempts+1 || got.ResponseCode != tt.code {
+				t.Errorf("stored %s after %d attempts (code %d), want %s after %d (code %d)",
+					got.Status, got.Attempts, got.ResponseCode, tt.status, tt.attempts+1, tt.code)
+			}
+			if tt.retry != (got.NextAttemptAt != nil) {
+				t.Fatalf("next attempt = %v, want a retry %v", got.NextAttemptAt, tt.retry)
+			}
+			if tt.retry && got.NextAttemptAt.Before(before.Add(backoff(tt.attempts+1))) {
+				t.Errorf("next attempt %s is sooner than the backoff", got.NextAttemptAt)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second}
+	for i, w := range want {
+		if got := backoff(i + 1); got != w {
+			t.Errorf("backoff(%d) = %s, want %s", i+1, got, w)
+		}
+	}
+}
+
+func TestDispatcherSendsPendingDeliveriesOnStart(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	repo := &deliveryLog{saved: make(map[uint]AlertDelivery), due: []AlertDelivery{
+		{ID: 7, RuleID: 1, URL: srv.URL, Payload: `{}`, Status: DeliveryPending, Attempts: 2},
+	}}
+	d := NewDispatcher(repo)
+	defer d.Close()
+
+	// Well inside the first tick, so it was the claim at start that sent it.
+	deadline := time.Now().Add(pollInterval / 2)
+	for repo.get(7).Status != DeliveryDelivered {
+		if time.Now().After(deadline) {
+			t.Fatal("pending delivery not sent on start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := repo.get(7); got.Attempts != 3 {
+		t.Errorf("delivered after %d attempts, want 3", got.Attempts)
+	}
+}
+
+func TestCloseReleasesQueuedDeliveries(t *testing.T) {
+	repo := &deliveryLog{saved: make(map[uint]AlertDelivery)}
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{repo: repo, jobs: make(chan deliveryJob, 2), ctx: ctx, cancel: cancel}
+	d.jobs <- deliveryJob{delivery: &AlertDelivery{ID: 1, Status: DeliveryPending, NextAttemptAt: leaseFrom(time.Now())}}
+
+	before := time.Now()
+	d.Close()
+	got := repo.get(1)
+	if got.NextAttemptAt == nil || got.NextAttemptAt.After(before.Add(time.Second)) {
+		t.Errorf("queued delivery next attempt = %v, want due now", got.NextAttemptAt)
+	}
+}
+
+func TestDeliverCutShortByClose(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	repo := &deliveryLog{saved: make(map[uint]AlertDelivery)}
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{repo: repo, client: srv.Client(), ctx: ctx}
+	time.AfterFunc(50*time.Millisecond, cancel)
+	d.deliver(deliveryJob{secret: "s", delivery: &AlertDelivery{ID: 1, URL: srv.URL, Payload: `{}`, Status: DeliveryPending, Attempts: 1}})
+
+	got := repo.get(1)
+	if got.Status != DeliveryPending || got.Attempts != 1 || got.NextAttemptAt == nil {
+		t.Errorf("stored %s after %d attempts, next %v; want pending after 1, due again", got.Status, got.Attempts, got.NextAttemptAt)
+	}
+}
+
+// crowdedLog queues a job of its own while the rule is read, leaving the
+// dispatcher less room than it claimed for.
+type crowdedLog struct {
+	*deliveryLog
+	jobs chan deliveryJob
+}
+
+func (r crowdedLog) GetByID(ctx context.Context, id uint) fx.Result[*AlertRule] {
+	r.jobs <- deliveryJob{delivery: &AlertDelivery{ID: 99}}
+	return r.deliveryLog.GetByID(ctx, id)
+}
+
+func TestEnqueueDueReleasesClaimsOnClose(t *testing.T) {
+	log := &deliveryLog{saved: make(map[uint]AlertDelivery), due: []AlertDelivery{
+		{ID: 1, RuleID: 1, Status: DeliveryPending},
+		{ID: 2, RuleID: 1, Status: DeliveryPending},
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	jobs := make(chan deliveryJob, 2)
+	d := &Dispatcher{repo: crowdedLog{log, jobs}, jobs: jobs, ctx: ctx, cancel: cancel}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.enqueueDue()
+	}()
+	time.Sleep(50 * time.Millisecond)
+	before := time.Now()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("enqueueDue still blocked after close")
+	}
+
+	got := log.get(2)
+	if got.NextAttemptAt == nil || got.NextAttemptAt.After(before.Add(time.Second)) {
+		t.Errorf("unqueued delivery next attempt = %v, want due now", got.NextAttemptAt)
+	}
+}
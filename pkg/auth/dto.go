@@ -0,0 +1,40 @@
+package auth
+
+import "time"
+
+// CreateKeyRequest DTO for creating an API key
+type CreateKeyRequest struct {
+	Name      string     `json:"name" binding:"required,min=1,max=100"`
+	Role      string     `json:"role" binding:"required,oneof=viewer contributor admin"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// KeyResponse DTO for API key response. The key itself is never listed.
+type KeyResponse struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Role       string     `json:"role"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreatedKeyResponse DTO for a new API key, the only response carrying it
+type CreatedKeyResponse struct {
+	KeyResponse
+	Key string `json:"key"`
+}
+
+// ToResponse converts APIKey to response DTO
+func ToResponse(k APIKey) KeyResponse {
+	return KeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Role:       k.Role,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		CreatedAt:  k.CreatedAt,
+	}
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ryuzxy/FuncPro/pkg/fx"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) GetAllKeys(c *gin.Context) {
+	result := h.service.GetAllKeys(c.Request.Context())
+
+	fx.Match(
+		result,
+		func(data []APIKey) any {
+			responses := fx.Map(data, ToResponse)
+			c.JSON(http.StatusOK, gin.H{
+				"success": true,
+				"data":    responses,
+				"count":   len(responses),
+			})
+			return nil
+		},
+		func(err error) any {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return nil
+		},
+	)
+}
+
+// CreateKey answers with the key itself, which no later request shows.
+func (h *Handler) CreateKey(c *gin.Context) {
+	var req CreateKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	result := h.service.CreateKey(c.Request.Context(), req)
+
+	fx.Match(
+		result,
+		func(data *CreatedKey) any {
+			c.JSON(http.StatusCreated, gin.H{
+				"success": true,
+				"data":    CreatedKeyResponse{KeyResponse: ToResponse(data.APIKey), Key: data.Key},
+			})
+			return nil
+		},
+		func(err error) any {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return nil
+		},
+	)
+}
+
+func (h *Handler) RevokeKey(c *gin.Context) {
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid ID format",
+		})
+		return
+	}
+
+	result := h.service.RevokeKey(c.Request.Context(), uint(id64))
+
+	fx.Match(
+		result,
+		func(success bool) any {
+			c.JSON(http.StatusOK, gin.H{
+				"success": true,
+				"message": "API key revoked successfully",
+			})
+			return nil
+		},
+		func(err error) any {
+			status := http.StatusInternalServerError
+			if errors.Is(err, ErrKeyNotFound) {
+				status = http.StatusNotFound
+			}
+			c.JSON(status, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return nil
+		},
+	)
+}
@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTConfig says which bearer tokens are accepted. Secret verifies HS256
+// tokens and the RSA keys in the JWKSFile verify RS256 ones; with neither,
+// only API keys are accepted.
+type JWTConfig struct {
+	Secret   string
+	JWKSFile string
+	// Issuer and Audience, when set, must match the iss and aud claims.
+	Issuer   string
+	Audience string
+	// RoleClaim names the claim holding viewer, contributor or admin.
+	RoleClaim string
+}
+
+// leeway absorbs clock skew between the issuer and this server.
+const leeway = 30 * time.Second
+
+// Verifier checks bearer tokens against the configured keys.
+type Verifier struct {
+	secret    []byte
+	keys      map[string]*rsa.PublicKey
+	parser    *jwt.Parser
+	roleClaim string
+}
+
+// NewVerifier loads the JWKS file, if any. It returns nil when neither a
+// secret nor a JWKS file is configured.
+func NewVerifier(cfg JWTConfig) (*Verifier, error) {
+	if cfg.Secret == "" && cfg.JWKSFile == "" {
+		return nil, nil
+	}
+
+	v := &Verifier{roleClaim: cfg.RoleClaim}
+	var methods []string
+	if cfg.Secret != "" {
+		v.secret = []byte(cfg.Secret)
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if cfg.JWKSFile != "" {
+		keys, err := LoadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		v.keys = keys
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	if v.roleClaim == "" {
+		v.roleClaim = "role"
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(leeway),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	v.parser = jwt.NewParser(opts...)
+	return v, nil
+}
+
+// Verify checks the signature, expiry, issuer and audience of token and
+// reads the caller from its sub and role claims. Every failure wraps
+// ErrUnauthenticated.
+func (v *Verifier) Verify(token string) (Principal, error) {
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(token, claims, v.key); err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+
+	subject, _ := claims.GetSubject()
+	if subject == "" {
+		return Principal{}, fmt.Errorf("%w: token has no sub claim", ErrUnauthenticated)
+	}
+	claim, _ := claims[v.roleClaim].(string)
+	role, err := ParseRole(claim)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %s claim: %v", ErrUnauthenticated, v.roleClaim, err)
+	}
+	return Principal{Subject: subject, Role: role, Method: MethodJWT}, nil
+}
+
+// key picks the verification key for the token's algorithm. WithValidMethods
+// has already refused algorithms without a configured key.
+func (v *Verifier) key(token *jwt.Token) (any, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		return v.secret, nil
+	}
+	kid, _ := token.Header["kid"].(string)
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("no key with kid %q", kid)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// LoadJWKS reads the RSA signing keys of a JSON Web Key Set, by kid.
+// Encryption keys and keys of other types are skipped.
+func LoadJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading JWKS: %w", err)
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for i, k := range set.Keys {
+		if k.Kty != "RSA" || k.Use == "enc" || (k.Alg != "" && k.Alg != "RS256") {
+			continue
+		}
+		key, err := rsaKey(k)
+		if err != nil {
+			return nil, fmt.Errorf("%s: key %d: %w", path, i, err)
+		}
+		if _, dup := keys[k.Kid]; dup {
+			return nil, fmt.Errorf("%s: kid %q appears twice", path, k.Kid)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s: no RS256 signing keys", path)
+	}
+	return keys, nil
+}
+
+func rsaKey(k jwk) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("exponent: %w", err)
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if len(n) < 256 || !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("not a usable RSA key of at least 2048 bits")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func writeJWKS(t *testing.T, kid string, key *rsa.PublicKey) string {
+	t.Helper()
+	set := map[string]any{"keys": []map[string]string{
+		{"kty": "EC", "kid": "ec", "crv": "P-256"},
+		{
+			"kty": "RSA", "kid": kid, "use": "sig", "alg": "RS256",
+			"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		},
+	}}
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	s, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestVerifier(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewVerifier(JWTConfig{
+		Secret:    testSecret,
+		JWKSFile:  writeJWKS(t, "k1", &rsaKey.PublicKey),
+		Issuer:    "https://id.example.com",
+		RoleClaim: "funcpro_role",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := func(modify func(jwt.MapClaims)) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"sub":          "user-1",
+			"iss":          "https://id.example.com",
+			"exp":          time.Now().Add(time.Hour).Unix(),
+			"funcpro_role": "contributor",
+		}
+		if modify != nil {
+			modify(c)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"HS256", sign(t, jwt.SigningMethodHS256, "", []byte(testSecret), claims(nil)), true},
+		{"RS256", sign(t, jwt.SigningMethodRS256, "k1", rsaKey, claims(nil)), true},
+		{"wrong secret", sign(t, jwt.SigningMethodHS256, "", []byte("another secret of thirty-two bytes"), claims(nil)), false},
+		{"unknown RSA key", sign(t, jwt.SigningMethodRS256, "k1", otherKey, claims(nil)), false},
+		{"unknown kid", sign(t, jwt.SigningMethodRS256, "k2", rsaKey, claims(nil)), false},
+		{"HS512", sign(t, jwt.SigningMethodHS512, "", []byte(testSecret), claims(nil)), false},
+		{"unsigned", sign(t, jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType, claims(nil)), false},
+		{"expired", sign(t, jwt.SigningMethodHS256, "", []byte(testSecret), claims(func(c jwt.MapClaims) {
+			c["exp"] = time.Now().Add(-time.Hour).Unix()
+		})), false},
+		{"no expiry", sign(t, jwt.SigningMethodHS256, "", []byte(testSecret), claims(func(c jwt.MapClaims) { delete(c, "exp") })), false},
+		{"other issuer", sign(t, jwt.SigningMethodHS256, "", []byte(testSecret), claims(func(c jwt.MapClaims) {
+			c["iss"] = "https://evil.example.com"
+		})), false},
+		{"no subject", sign(t, jwt.SigningMethodHS256, "", []byte(testSecret), claims(func(c jwt.MapClaims) { delete(c, "sub") })), false},
+		{"unknown role", sign(t, jwt.SigningMethodHS256, "", []byte(testSecret), claims(func(c jwt.MapClaims) {
+			c["funcpro_role"] = "root"
+		})), false},
+		{"garbage", "not.a.token", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := v.Verify(tt.token)
+			if !tt.valid {
+				if !errors.Is(err, ErrUnauthenticated) {
+					t.Errorf("err = %v, want ErrUnauthenticated", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Subject != "user-1" || p.Role != RoleContributor || p.Method != MethodJWT {
+				t.Errorf("principal = %+v", p)
+			}
+		})
+	}
+}
+
+// A verifier holding only RSA keys must not accept an HS256 token signed
+// with the public key as the HMAC secret.
+func TestVerifierRefusesAlgorithmSwitch(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewVerifier(JWTConfig{JWKSFile: writeJWKS(t, "", &rsaKey.PublicKey)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := jwt.MapClaims{"sub": "u", "role": "admin", "exp": time.Now().Add(time.Hour).Unix()}
+
+	if _, err := v.Verify(sign(t, jwt.SigningMethodRS256, "", rsaKey, claims)); err != nil {
+		t.Fatalf("RS256 without kid refused: %v", err)
+	}
+	forged := sign(t, jwt.SigningMethodHS256, "", rsaKey.PublicKey.N.Bytes(), claims)
+	if _, err := v.Verify(forged); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("HS256 token accepted by an RS256-only verifier: %v", err)
+	}
+}
+
+func TestNewVerifierWithoutKeys(t *testing.T) {
+	v, err := NewVerifier(JWTConfig{RoleClaim: "role"})
+	if v != nil || err != nil {
+		t.Errorf("NewVerifier = %v, %v; want nil, nil", v, err)
+	}
+}
@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader carries an API key. Keys are also accepted as bearer tokens.
+const APIKeyHeader = "X-API-Key"
+
+// AccessTokenParam carries a bearer token in the query string, for clients
+// such as EventSource and browser WebSockets that cannot set headers.
+const AccessTokenParam = "access_token"
+
+// Authenticator resolves the caller of each request from its API key or
+// bearer token.
+type Authenticator struct {
+	keys      Service
+	verifier  *Verifier
+	anonymous Role
+}
+
+// NewAuthenticator accepts API keys through keys and JWTs through verifier,
+// which may be nil. Requests without credentials get the anonymous role, or
+// none when it is empty.
+func NewAuthenticator(keys Service, verifier *Verifier, anonymous Role) *Authenticator {
+	return &Authenticator{keys: keys, verifier: verifier, anonymous: anonymous}
+}
+
+// Middleware puts the caller into the request context. Credentials that
+// are present but wrong are refused with 401 right away, rather than
+// falling back to the anonymous role; what the caller may do is left to
+// Require on each route.
+func (a *Authenticator) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok, err := a.authenticate(c)
+		if err != nil {
+			if errors.Is(err, ErrUnauthenticated) {
+				c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+				abort(c, http.StatusUnauthorized, err.Error())
+				return
+			}
+			_ = c.Error(err)
+			abort(c, http.StatusInternalServerError, "failed to check credentials")
+			return
+		}
+		if ok {
+			c.Request = c.Request.WithContext(WithPrincipal(c.Request.Context(), principal))
+		}
+		c.Next()
+	}
+}
+
+func (a *Authenticator) authenticate(c *gin.Context) (Principal, bool, error) {
+	ctx := c.Request.Context()
+	if key := c.GetHeader(APIKeyHeader); key != "" {
+		p, err := a.keys.Authenticate(ctx, key).Unwrap()
+		return p, err == nil, err
+	}
+
+	if header := c.GetHeader("Authorization"); header != "" {
+		scheme, token, _ := strings.Cut(header, " ")
+		token = strings.TrimSpace(token)
+		switch {
+		case !strings.EqualFold(scheme, "Bearer") || token == "":
+			return Principal{}, false, fmt.Errorf("%w: authorization must be a bearer token", ErrUnauthenticated)
+		case strings.HasPrefix(token, KeyPrefix):
+			p, err := a.keys.Authenticate(ctx, token).Unwrap()
+			return p, err == nil, err
+		case a.verifier == nil:
+			return Principal{}, false, fmt.Errorf("%w: bearer tokens are not accepted, use an API key", ErrUnauthenticated)
+		default:
+			p, err := a.verifier.Verify(token)
+			return p, err == nil, err
+		}
+	}
+
+	if a.anonymous != "" {
+		return Principal{Role: a.anonymous, Method: MethodAnonymous}, true, nil
+	}
+	return Principal{}, false, nil
+}
+
+// TokenFromQuery moves the AccessTokenParam query parameter into the
+// Authorization header when the request carries no credentials of its own,
+// and must run before Middleware. The parameter is removed from the URL so
+// the token is not passed on. Query strings end up in proxy and browser
+// logs, so this belongs only on routes that cannot be reached otherwise and
+// is meant for short-lived tokens.
+func TokenFromQuery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := c.Request.URL.Query()
+		token := query.Get(AccessTokenParam)
+		if token == "" {
+			c.Next()
+			return
+		}
+		if c.GetHeader(APIKeyHeader) == "" && c.GetHeader("Authorization") == "" {
+			c.Request.Header.Set("Authorization", "Bearer "+token)
+		}
+		query.Del(AccessTokenParam)
+		c.Request.URL.RawQuery = query.Encode()
+		c.Next()
+	}
+}
+
+// Require lets the request through when its caller has role or a role
+// above it: 401 without a caller, 403 with too small a role.
+func Require(role Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, ok := FromContext(c.Request.Context())
+		if !ok {
+			c.Header("WWW-Authenticate", "Bearer")
+			abort(c, http.StatusUnauthorized, "authentication required")
+			return
+		}
+		if !p.Role.Allows(role) {
+			abort(c, http.StatusForbidden, string(role)+" role required")
+			return
+		}
+		c.Next()
+	}
+}
+
+func abort(c *gin.Context, status int, message string) {
+	c.AbortWithStatusJSON(status, gin.H{
+		"success": false,
+		"error":   message,
+	})
+}
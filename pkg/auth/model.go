@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Role is what a caller may do. Each role includes the ones below it:
+// viewers read, contributors also submit prices, admins also manage
+// komoditas, markets, rates, baskets, alert rules and API keys.
+type Role string
+
+const (
+	RoleViewer      Role = "viewer"
+	RoleContributor Role = "contributor"
+	RoleAdmin       Role = "admin"
+)
+
+var roleRank = map[Role]int{RoleViewer: 1, RoleContributor: 2, RoleAdmin: 3}
+
+func ParseRole(s string) (Role, error) {
+	role := Role(s)
+	if _, ok := roleRank[role]; !ok {
+		return "", fmt.Errorf("unknown role %q, want viewer, contributor or admin", s)
+	}
+	return role, nil
+}
+
+// Allows reports whether r includes required.
+func (r Role) Allows(required Role) bool {
+	return roleRank[r] > 0 && roleRank[r] >= roleRank[required]
+}
+
+const (
+	MethodAPIKey    = "api_key"
+	MethodJWT       = "jwt"
+	MethodAnonymous = "anonymous"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// Subject is "apikey:<id>" for API keys and the sub claim for JWTs.
+	Subject string
+	Role    Role
+	Method  string
+	KeyID   uint
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext is the caller the request was authenticated as, if any.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// APIKey is a long-lived credential for scripts and integrations. Only the
+// SHA-256 of the key is stored: the key itself is shown once, when it is
+// created, and Prefix is kept so it can be recognised in listings. Revoked
+// keys are soft-deleted, so their history stays.
+type APIKey struct {
+	ID         uint           `gorm:"primarykey" json:"id"`
+	Name       string         `gorm:"size:100;not null" json:"name"`
+	Prefix     string         `gorm:"size:16;not null" json:"prefix"`
+	Hash       string         `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Role       string         `gorm:"size:20;not null" json:"role"`
+	ExpiresAt  *time.Time     `json:"expires_at"`
+	LastUsedAt *time.Time     `json:"last_used_at"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ryuzxy/FuncPro/pkg/fx"
+)
+
+// ErrKeyNotFound is returned for keys that do not exist or were revoked.
+var ErrKeyNotFound = errors.New("API key not found")
+
+type Repository interface {
+	GetAll(ctx context.Context) fx.Result[[]APIKey]
+	GetByHash(ctx context.Context, hash string) fx.Result[*APIKey]
+	Create(ctx context.Context, key *APIKey) fx.Result[*APIKey]
+	Delete(ctx context.Context, id uint) fx.Result[bool]
+	Touch(ctx context.Context, id uint, at time.Time) fx.Result[bool]
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) GetAll(ctx context.Context) fx.Result[[]APIKey] {
+	var keys []APIKey
+	if err := r.db.WithContext(ctx).Order("id asc").Find(&keys).Error; err != nil {
+		return fx.Err[[]APIKey](fmt.Errorf("failed to get API keys: %w", err))
+	}
+	return fx.Ok(keys)
+}
+
+func (r *repository) GetByHash(ctx context.Context, hash string) fx.Result[*APIKey] {
+	var key APIKey
+	err := r.db.WithContext(ctx).Where("hash = ?", hash).First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fx.Err[*APIKey](ErrKeyNotFound)
+		}
+		return fx.Err[*APIKey](fmt.Errorf("failed to get API key: %w", err))
+	}
+	return fx.Ok(&key)
+}
+
+func (r *repository) Create(ctx context.Context, key *APIKey) fx.Result[*APIKey] {
+	if err := r.db.WithContext(ctx).Create(key).Error; err != nil {
+		return fx.Err[*APIKey](fmt.Errorf("failed to create API key: %w", err))
+	}
+	return fx.Ok(key)
+}
+
+func (r *repository) Delete(ctx context.Context, id uint) fx.Result[bool] {
+	res := r.db.WithContext(ctx).Delete(&APIKey{}, id)
+	if res.Error != nil {
+		return fx.Err[bool](fmt.Errorf("failed to revoke API key: %w", res.Error))
+	}
+	if res.RowsAffected == 0 {
+		return fx.Err[bool](ErrKeyNotFound)
+	}
+	return fx.Ok(true)
+}
+
+// Touch records when a key was last used, without bumping updated_at.
+func (r *repository) Touch(ctx context.Context, id uint, at time.Time) fx.Result[bool] {
+	err := r.db.WithContext(ctx).Model(&APIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", at).Error
+	if err != nil {
+		return fx.Err[bool](fmt.Errorf("failed to record API key use: %w", err))
+	}
+	return fx.Ok(true)
+}
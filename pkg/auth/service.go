@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/ryuzxy/FuncPro/pkg/fx"
+)
+
+var tracer = otel.Tracer("github.com/ryuzxy/FuncPro/pkg/auth")
+
+// ErrUnauthenticated wraps every reason a credential is refused, so callers
+// can tell a bad key from a failing database.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// KeyPrefix starts every API key, so a key sent as a bearer token is not
+// mistaken for a JWT and a leaked one is easy to grep for.
+const KeyPrefix = "fpk_"
+
+// touchInterval is how stale last_used_at may get, so a busy key is not
+// written on every request.
+const touchInterval = time.Minute
+
+type Service interface {
+	GetAllKeys(ctx context.Context) fx.Result[[]APIKey]
+	CreateKey(ctx context.Context, req CreateKeyRequest) fx.Result[*CreatedKey]
+	RevokeKey(ctx context.Context, id uint) fx.Result[bool]
+	Authenticate(ctx context.Context, key string) fx.Result[Principal]
+}
+
+// CreatedKey is a stored key together with the key itself, which is not
+// kept and cannot be shown again.
+type CreatedKey struct {
+	APIKey
+	Key string
+}
+
+type service struct {
+	repo Repository
+	now  func() time.Time
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo, now: time.Now}
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func newKey() (string, error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return KeyPrefix + base64.RawURLEncoding.EncodeToString(b[:]), nil
+}
+
+func (s *service) GetAllKeys(ctx context.Context) fx.Result[[]APIKey] {
+	ctx, span := tracer.Start(ctx, "auth.GetAllKeys")
+	defer span.End()
+
+	return s.repo.GetAll(ctx)
+}
+
+func (s *service) CreateKey(ctx context.Context, req CreateKeyRequest) fx.Result[*CreatedKey] {
+	ctx, span := tracer.Start(ctx, "auth.CreateKey")
+	defer span.End()
+
+	role, err := ParseRole(req.Role)
+	if err != nil {
+		return fx.Err[*CreatedKey](err)
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(s.now()) {
+		return fx.Err[*CreatedKey](fmt.Errorf("expires_at must be in the future"))
+	}
+
+	key, err := newKey()
+	if err != nil {
+		return fx.Err[*CreatedKey](fmt.Errorf("failed to generate API key: %w", err))
+	}
+	stored, err := s.repo.Create(ctx, &APIKey{
+		Name:      req.Name,
+		Prefix:    key[:len(KeyPrefix)+8],
+		Hash:      hashKey(key),
+		Role:      string(role),
+		ExpiresAt: req.ExpiresAt,
+	}).Unwrap()
+	if err != nil {
+		return fx.Err[*CreatedKey](err)
+	}
+	return fx.Ok(&CreatedKey{APIKey: *stored, Key: key})
+}
+
+func (s *service) RevokeKey(ctx context.Context, id uint) fx.Result[bool] {
+	ctx, span := tracer.Start(ctx, "auth.RevokeKey")
+	defer span.End()
+
+	return s.repo.Delete(ctx, id)
+}
+
+// Authenticate finds the live key and the role it grants. Unknown, revoked
+// and expired keys fail with ErrUnauthenticated.
+func (s *service) Authenticate(ctx context.Context, key string) fx.Result[Principal] {
+	ctx, span := tracer.Start(ctx, "auth.Authenticate")
+	defer span.End()
+
+	stored, err := s.repo.GetByHash(ctx, hashKey(key)).Unwrap()
+	if err != nil {
+		if errors.Is(err, ErrKeyNotFound) {
+			return fx.Err[Principal](fmt.Errorf("%w: invalid API key", ErrUnauthenticated))
+		}
+		return fx.Err[Principal](err)
+	}
+
+	now := s.now()
+	if stored.ExpiresAt != nil && !now.Before(*stored.ExpiresAt) {
+		return fx.Err[Principal](fmt.Errorf("%w: API key expired", ErrUnauthenticated))
+	}
+	role, err := ParseRole(stored.Role)
+	if err != nil {
+		return fx.Err[Principal](fmt.Errorf("%w: API key %d: %v", ErrUnauthenticated, stored.ID, err))
+	}
+
+	if stored.LastUsedAt == nil || now.Sub(*stored.LastUsedAt) >= touchInterval {
+		if _, err := s.repo.Touch(ctx, stored.ID, now).Unwrap(); err != nil {
+			slog.WarnContext(ctx, "recording API key use", "key_id", stored.ID, "error", err)
+		}
+	}
+
+	return fx.Ok(Principal{
+		Subject: "apikey:" + strconv.FormatUint(uint64(stored.ID), 10),
+		Role:    role,
+		Method:  MethodAPIKey,
+		KeyID:   stored.ID,
+	})
+}
@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryuzxy/FuncPro/pkg/fx"
+)
+
+type stubRepository struct {
+	Repository
+	keys    map[string]*APIKey
+	created *APIKey
+	touched []uint
+}
+
+func (r *stubRepository) GetByHash(_ context.Context, hash string) fx.Result[*APIKey] {
+	if k, ok := r.keys[hash]; ok {
+		return fx.Ok(k)
+	}
+	return fx.Err[*APIKey](ErrKeyNotFound)
+}
+
+func (r *stubRepository) Create(_ context.Context, key *APIKey) fx.Result[*APIKey] {
+	key.ID = 7
+	r.created = key
+	return fx.Ok(key)
+}
+
+func (r *stubRepository) Touch(_ context.Context, id uint, _ time.Time) fx.Result[bool] {
+	r.touched = append(r.touched, id)
+	return fx.Ok(true)
+}
+
+func TestCreateKeyStoresOnlyTheHash(t *testing.T) {
+	repo := &stubRepository{}
+	s := NewService(repo)
+
+	created, err := s.CreateKey(context.Background(), CreateKeyRequest{Name: "importer", Role: "contributor"}).Unwrap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(created.Key, KeyPrefix) || len(created.Key) < 40 {
+		t.Errorf("key %q", created.Key)
+	}
+	if repo.created.Hash != hashKey(created.Key) || strings.Contains(repo.created.Hash, created.Key) {
+		t.Errorf("stored hash %q does not match the key", repo.created.Hash)
+	}
+	if !strings.HasPrefix(created.Key, repo.created.Prefix) || repo.created.Prefix == created.Key {
+		t.Errorf("prefix %q of key %q", repo.created.Prefix, created.Key)
+	}
+
+	past := time.Now().Add(-time.Hour)
+	if _, err := s.CreateKey(context.Background(), CreateKeyRequest{Name: "old", Role: "viewer", ExpiresAt: &past}).Unwrap(); err == nil {
+		t.Error("created a key that has already expired")
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	expired := now.Add(-time.Second)
+	later := now.Add(time.Hour)
+	recent := now.Add(-10 * time.Second)
+	repo := &stubRepository{keys: map[string]*APIKey{
+		hashKey("fpk_live"):    {ID: 1, Role: "admin", ExpiresAt: &later},
+		hashKey("fpk_recent"):  {ID: 2, Role: "viewer", LastUsedAt: &recent},
+		hashKey("fpk_expired"): {ID: 3, Role: "viewer", ExpiresAt: &expired},
+	}}
+	s := &service{repo: repo, now: func() time.Time { return now }}
+
+	tests := []struct {
+		key   string
+		role  Role
+		valid bool
+	}{
+		{"fpk_live", RoleAdmin, true},
+		{"fpk_recent", RoleViewer, true},
+		{"fpk_expired", "", false},
+		{"fpk_unknown", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			p, err := s.Authenticate(context.Background(), tt.key).Unwrap()
+			if !tt.valid {
+				if !errors.Is(err, ErrUnauthenticated) {
+					t.Errorf("err = %v, want ErrUnauthenticated", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Role != tt.role || p.Method != MethodAPIKey || p.KeyID == 0 {
+				t.Errorf("principal = %+v", p)
+			}
+		})
+	}
+
+	// Key 2 was used seconds ago, so only key 1 is written.
+	if len(repo.touched) != 1 || repo.touched[0] != 1 {
+		t.Errorf("touched %v, want [1]", repo.touched)
+	}
+}
+
+func TestRoleAllows(t *testing.T) {
+	tests := []struct {
+		role, required Role
+		want           bool
+	}{
+		{RoleAdmin, RoleViewer, true},
+		{RoleAdmin, RoleAdmin, true},
+		{RoleContributor, RoleViewer, true},
+		{RoleContributor, RoleAdmin, false},
+		{RoleViewer, RoleContributor, false},
+		{Role("root"), RoleViewer, false},
+	}
+	for _, tt := range tests {
+		if got := tt.role.Allows(tt.required); got != tt.want {
+			t.Errorf("%s.Allows(%s) = %v", tt.role, tt.required, got)
+		}
+	}
+}
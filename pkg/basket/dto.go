@@ -0,0 +1,138 @@
+package basket
+
+import (
+	"time"
+
+	"github.com/ryuzxy/FuncPro/pkg/money"
+	"github.com/ryuzxy/FuncPro/pkg/price"
+)
+
+// BasketItemRequest DTO for one basket item
+type BasketItemRequest struct {
+	KomoditasID uint        `json:"komoditas_id" binding:"required"`
+	Quantity    money.Money `json:"quantity" binding:"required,gt=0"`
+}
+
+// CreateBasketRequest DTO for creating basket
+type CreateBasketRequest struct {
+	Name        string              `json:"name" binding:"required,min=1,max=100"`
+	Description string              `json:"description" binding:"max=255"`
+	Items       []BasketItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+// UpdateBasketRequest DTO for updating basket. Items, when given, replace
+// the whole item list.
+type UpdateBasketRequest struct {
+	Name        string              `json:"name" binding:"omitempty,min=1,max=100"`
+	Description string              `json:"description" binding:"max=255"`
+	Items       []BasketItemRequest `json:"items" binding:"omitempty,min=1,dive"`
+}
+
+// IndexQuery query parameters for the basket index
+type IndexQuery struct {
+	price.ValueQuery
+	Interval   string    `form:"interval" binding:"omitempty,oneof=day week month quarter"`
+	MarketID   *uint     `form:"market_id"`
+	BasePeriod time.Time `form:"base_period" time_format:"2006-01-02"`
+	From       time.Time `form:"from" time_format:"2006-01-02"`
+	To         time.Time `form:"to" time_format:"2006-01-02"`
+}
+
+// BasketItemResponse DTO for basket item response
+type BasketItemResponse struct {
+	KomoditasID uint        `json:"komoditas_id"`
+	Quantity    money.Money `json:"quantity"`
+}
+
+// BasketResponse DTO for basket response
+type BasketResponse struct {
+	ID          uint                 `json:"id"`
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	Items       []BasketItemResponse `json:"items"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+}
+
+// IndexPointResponse DTO for one period of the basket index
+type IndexPointResponse struct {
+	Period    time.Time   `json:"period"`
+	Cost      money.Money `json:"cost"`
+	Laspeyres float64     `json:"laspeyres"`
+	Harmonic  float64     `json:"harmonic"`
+}
+
+// IndexResponse DTO for basket index response
+type IndexResponse struct {
+	Basket     BasketResponse       `json:"basket"`
+	Interval   price.Interval       `json:"interval"`
+	MarketID   *uint                `json:"market_id"`
+	BasePeriod *time.Time           `json:"base_period"`
+	BaseCost   money.Money          `json:"base_cost"`
+	Points     []IndexPointResponse `json:"points"`
+	Incomplete []time.Time          `json:"incomplete_periods"`
+}
+
+// ToResponse converts Basket to response DTO
+func ToResponse(b Basket) BasketResponse {
+	items := make([]BasketItemResponse, 0, len(b.Items))
+	for _, item := range b.Items {
+		items = append(items, BasketItemResponse{KomoditasID: item.KomoditasID, Quantity: item.Quantity})
+	}
+	return BasketResponse{
+		ID:          b.ID,
+		Name:        b.Name,
+		Description: b.Description,
+		Items:       items,
+		CreatedAt:   b.CreatedAt,
+		UpdatedAt:   b.UpdatedAt,
+	}
+}
+
+// ToIndexResponse converts Index to response DTO
+func ToIndexResponse(idx Index) IndexResponse {
+	r := IndexResponse{
+		Basket:     ToResponse(idx.Basket),
+		Interval:   idx.Interval,
+		MarketID:   idx.MarketID,
+		BaseCost:   idx.BaseCost,
+		Points:     make([]IndexPointResponse, 0, len(idx.Points)),
+		Incomplete: idx.Incomplete,
+	}
+	if !idx.Base.IsZero() {
+		base := idx.Base
+		r.BasePeriod = &base
+	}
+	for _, p := range idx.Points {
+		r.Points = append(r.Points, IndexPointResponse{
+			Period:    p.Period,
+			Cost:      p.Cost,
+			Laspeyres: p.Laspeyres,
+			Harmonic:  p.Harmonic,
+		})
+	}
+	return r
+}
+
+// ToOptions converts query parameters to index options
+func (q IndexQuery) ToOptions() (IndexOptions, error) {
+	var interval price.Interval
+	if q.Interval != "" {
+		var err error
+		if interval, err = price.ParseInterval(q.Interval); err != nil {
+			return IndexOptions{}, err
+		}
+	}
+	values, err := q.ToValueOptions()
+	if err != nil {
+		return IndexOptions{}, err
+	}
+	return IndexOptions{
+		Interval: interval,
+		Start:    q.From,
+		End:      q.To,
+		Base:     q.BasePeriod,
+		MarketID: q.MarketID,
+		Values:   values,
+	}, nil
+}
@@ -0,0 +1,221 @@
+package basket
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ryuzxy/FuncPro/pkg/fx"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) GetAllBaskets(c *gin.Context) {
+	result := h.service.GetAllBaskets(c.Request.Context())
+
+	fx.Match(
+		result,
+		func(data []Basket) any {
+			responses := fx.Map(data, ToResponse)
+			c.JSON(http.StatusOK, gin.H{
+				"success": true,
+				"data":    responses,
+				"count":   len(responses),
+			})
+			return nil
+		},
+		func(err error) any {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return nil
+		},
+	)
+}
+
+func (h *Handler) GetBasketByID(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	result := h.service.GetBasketByID(c.Request.Context(), id)
+
+	fx.Match(
+		result,
+		func(data *Basket) any {
+			c.JSON(http.StatusOK, gin.H{
+				"success": true,
+				"data":    ToResponse(*data),
+			})
+			return nil
+		},
+		func(err error) any {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return nil
+		},
+	)
+}
+
+func (h *Handler) CreateBasket(c *gin.Context) {
+	var req CreateBasketRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result := h.service.CreateBasket(c.Request.Context(), req)
+
+	fx.Match(
+		result,
+		func(data *Basket) any {
+			c.JSON(http.StatusCreated, gin.H{
+				"success": true,
+				"data":    ToResponse(*data),
+			})
+			return nil
+		},
+		func(err error) any {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return nil
+		},
+	)
+}
+
+func (h *Handler) UpdateBasket(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateBasketRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result := h.service.UpdateBasket(c.Request.Context(), id, req)
+
+	fx.Match(
+		result,
+		func(data *Basket) any {
+			c.JSON(http.StatusOK, gin.H{
+				"success": true,
+				"data":    ToResponse(*data),
+			})
+			return nil
+		},
+		func(err error) any {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return nil
+		},
+	)
+}
+
+func (h *Handler) DeleteBasket(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	result := h.service.DeleteBasket(c.Request.Context(), id)
+
+	fx.Match(
+		result,
+		func(success bool) any {
+			c.JSON(http.StatusOK, gin.H{
+				"success": true,
+				"message": "Basket deleted successfully",
+			})
+			return nil
+		},
+		func(err error) any {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return nil
+		},
+	)
+}
+
+func (h *Handler) GetBasketIndex(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	var q IndexQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	opts, err := q.ToOptions()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	result := h.service.GetBasketIndex(c.Request.Context(), id, opts)
+
+	fx.Match(
+		result,
+		func(data Index) any {
+			c.JSON(http.StatusOK, gin.H{
+				"success": true,
+				"data":    ToIndexResponse(data),
+			})
+			return nil
+		},
+		func(err error) any {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return nil
+		},
+	)
+}
+
+func parseID(c *gin.Context) (uint, bool) {
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid ID format",
+		})
+		return 0, false
+	}
+	return uint(id64), true
+}
+
+func bindJSON[T any](c *gin.Context, target *T) bool {
+	if err := c.ShouldBindJSON(target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return false
+	}
+	return true
+}
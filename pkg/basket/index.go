@@ -0,0 +1,134 @@
+package basket
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ryuzxy/FuncPro/pkg/money"
+	"github.com/ryuzxy/FuncPro/pkg/price"
+)
+
+// IndexOptions controls how a basket is priced over time. A nil MarketID
+// prices the basket nationally from the mean of all reported prices.
+type IndexOptions struct {
+	Interval price.Interval
+	Start    time.Time
+	End      time.Time
+	// Base is the reference period, where both indices are 100. Zero picks
+	// the first period in which every item has a price.
+	Base     time.Time
+	MarketID *uint
+	Values   price.ValueOptions
+}
+
+type IndexPoint struct {
+	Period    time.Time
+	Cost      money.Money
+	Laspeyres float64
+	Harmonic  float64
+}
+
+type Index struct {
+	Basket   Basket
+	Interval price.Interval
+	MarketID *uint
+	Base     time.Time
+	BaseCost money.Money
+	Points   []IndexPoint
+	// Incomplete lists periods left out because at least one item had no
+	// price in them.
+	Incomplete []time.Time
+}
+
+// periodPrices is the mean price of each komoditas per period.
+type periodPrices map[uint]map[time.Time]money.Money
+
+// computeIndex prices the basket in every period where all items have a
+// price. The Laspeyres index is the cost of the fixed quantities relative to
+// the base period. The harmonic index is the base-cost-weighted harmonic
+// mean of the price relatives (a harmonic Laspeyres index), which reacts
+// less to items whose price shot up. It is not a Paasche index: baskets only
+// have base quantities, never current ones.
+func computeIndex(items []BasketItem, prices periodPrices, base time.Time) (Index, error) {
+	seen := make(map[time.Time]bool)
+	for _, item := range items {
+		for period := range prices[item.KomoditasID] {
+			seen[period] = true
+		}
+	}
+	periods := make([]time.Time, 0, len(seen))
+	for period := range seen {
+		periods = append(periods, period)
+	}
+	sort.Slice(periods, func(i, j int) bool { return periods[i].Before(periods[j]) })
+
+	complete := func(period time.Time) bool {
+		for _, item := range items {
+			if _, ok := prices[item.KomoditasID][period]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+
+	idx := Index{Points: []IndexPoint{}, Incomplete: []time.Time{}}
+	var full []time.Time
+	for _, period := range periods {
+		if complete(period) {
+			full = append(full, period)
+		} else {
+			idx.Incomplete = append(idx.Incomplete, period)
+		}
+	}
+	if len(full) == 0 {
+		return idx, nil
+	}
+
+	if base.IsZero() {
+		base = full[0]
+	} else if !complete(base) {
+		return Index{}, fmt.Errorf("base period %s does not have a price for every item", base.Format("2006-01-02"))
+	}
+	idx.Base = base
+
+	// costs are the price times quantity of each item, in item order.
+	costs := func(period time.Time) ([]money.Money, error) {
+		out := make([]money.Money, len(items))
+		for i, item := range items {
+			c, err := prices[item.KomoditasID][period].Times(item.Quantity)
+			if err != nil {
+				return nil, fmt.Errorf("cost of komoditas %d in %s: %w", item.KomoditasID, period.Format("2006-01-02"), err)
+			}
+			out[i] = c
+		}
+		return out, nil
+	}
+	baseCosts, err := costs(base)
+	if err != nil {
+		return Index{}, err
+	}
+	idx.BaseCost = money.Sum(baseCosts)
+
+	for _, period := range full {
+		periodCosts, err := costs(period)
+		if err != nil {
+			return Index{}, err
+		}
+		point := IndexPoint{Period: period, Cost: money.Sum(periodCosts)}
+		point.Laspeyres = 100 * point.Cost.Ratio(idx.BaseCost)
+
+		var relatives float64
+		for i, item := range items {
+			p0, pt := prices[item.KomoditasID][base], prices[item.KomoditasID][period]
+			share := baseCosts[i].Ratio(idx.BaseCost)
+			relatives += share * p0.Ratio(pt)
+		}
+		if relatives != 0 {
+			point.Harmonic = 100 / relatives
+		}
+		idx.Points = append(idx.Points, point)
+	}
+
+	return idx, nil
+}
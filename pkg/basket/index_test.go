@@ -0,0 +1,107 @@
+package basket
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ryuzxy/FuncPro/pkg/money"
+)
+
+func month(m time.Month) time.Time {
+	return time.Date(2024, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func TestComputeIndex(t *testing.T) {
+	items := []BasketItem{
+		{KomoditasID: 1, Quantity: money.MustParse("10")},
+		{KomoditasID: 2, Quantity: money.MustParse("2")},
+	}
+	prices := periodPrices{
+		1: {
+			month(1): money.MustParse("10000"),
+			month(2): money.MustParse("12000"),
+			month(3): money.MustParse("11000"),
+			month(4): money.MustParse("9000"),
+		},
+		2: {
+			month(1): money.MustParse("15000"),
+			month(2): money.MustParse("15000"),
+			month(4): money.MustParse("18000"),
+		},
+	}
+
+	type wantPoint struct {
+		period    time.Time
+		cost      string
+		laspeyres float64
+		harmonic  float64
+	}
+	tests := []struct {
+		name     string
+		base     time.Time
+		baseCost string
+		want     []wantPoint
+	}{
+		{"first complete period as base", time.Time{}, "130000", []wantPoint{
+			{month(1), "130000", 100, 100},
+			{month(2), "150000", 115.3846, 114.7059},
+			{month(4), "126000", 96.9231, 95.5102},
+		}},
+		{"explicit base", month(2), "150000", []wantPoint{
+			{month(1), "130000", 86.6667, 86.2069},
+			{month(2), "150000", 100, 100},
+			{month(4), "126000", 84, 81.0811},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx, err := computeIndex(items, prices, tt.base)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if idx.BaseCost != money.MustParse(tt.baseCost) {
+				t.Errorf("base cost = %s, want %s", idx.BaseCost, tt.baseCost)
+			}
+			if len(idx.Incomplete) != 1 || !idx.Incomplete[0].Equal(month(3)) {
+				t.Errorf("incomplete = %v, want [March]", idx.Incomplete)
+			}
+			if len(idx.Points) != len(tt.want) {
+				t.Fatalf("got %d points, want %d", len(idx.Points), len(tt.want))
+			}
+			for i, w := range tt.want {
+				p := idx.Points[i]
+				if !p.Period.Equal(w.period) || p.Cost != money.MustParse(w.cost) {
+					t.Errorf("point %d = %s %s, want %s %s", i, p.Period, p.Cost, w.period, w.cost)
+				}
+				if math.Abs(p.Laspeyres-w.laspeyres) > 1e-4 {
+					t.Errorf("point %d laspeyres = %.4f, want %.4f", i, p.Laspeyres, w.laspeyres)
+				}
+				if math.Abs(p.Harmonic-w.harmonic) > 1e-4 {
+					t.Errorf("point %d harmonic = %.4f, want %.4f", i, p.Harmonic, w.harmonic)
+				}
+			}
+		})
+	}
+}
+
+func TestComputeIndexErrors(t *testing.T) {
+	items := []BasketItem{{KomoditasID: 1, Quantity: money.MustParse("1")}, {KomoditasID: 2, Quantity: money.MustParse("1")}}
+	prices := periodPrices{
+		1: {month(1): money.MustParse("1"), month(2): money.MustParse("1")},
+		2: {month(2): money.MustParse("1")},
+	}
+	if _, err := computeIndex(items, prices, month(1)); err == nil {
+		t.Error("incomplete base period accepted")
+	}
+
+	huge := periodPrices{1: {month(1): money.MustParse("900000000000000")}}
+	if _, err := computeIndex([]BasketItem{{KomoditasID: 1, Quantity: money.MustParse("1000")}}, huge, time.Time{}); err == nil {
+		t.Error("overflowing cost accepted")
+	}
+
+	idx, err := computeIndex(items, periodPrices{}, time.Time{})
+	if err != nil || len(idx.Points) != 0 {
+		t.Errorf("no prices gave %d points, %v", len(idx.Points), err)
+	}
+}
@@ -0,0 +1,32 @@
+package basket
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ryuzxy/FuncPro/pkg/money"
+)
+
+// Basket is a named bundle of komoditas, such as the staple-food "sembako"
+// basket, priced as a whole.
+type Basket struct {
+	ID          uint           `gorm:"primarykey" json:"id"`
+	Name        string         `gorm:"size:100;not null;uniqueIndex" json:"name"`
+	Description string         `gorm:"size:255" json:"description"`
+	Items       []BasketItem   `gorm:"constraint:OnDelete:CASCADE" json:"items"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// BasketItem is the fixed quantity of one komoditas in a basket, in the
+// komoditas base unit. Quantities double as the index weights.
+type BasketItem struct {
+	ID          uint        `gorm:"primarykey" json:"id"`
+	BasketID    uint        `gorm:"not null;uniqueIndex:idx_basket_item" json:"basket_id"`
+	KomoditasID uint        `gorm:"not null;uniqueIndex:idx_basket_item" json:"komoditas_id"`
+	Quantity    money.Money `gorm:"type:numeric(18,4);not null" json:"quantity"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
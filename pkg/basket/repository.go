@@ -0,0 +1,109 @@
+package basket
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/ryuzxy/FuncPro/pkg/fx"
+)
+
+type Repository interface {
+	GetAll(ctx context.Context) fx.Result[[]Basket]
+	GetByID(ctx context.Context, id uint) fx.Result[*Basket]
+	GetByName(ctx context.Context, name string) fx.Result[*Basket]
+	Create(ctx context.Context, basket *Basket) fx.Result[*Basket]
+	Update(ctx context.Context, id uint, basket *Basket) fx.Result[*Basket]
+	Delete(ctx context.Context, id uint) fx.Result[bool]
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func withItems(db *gorm.DB) *gorm.DB {
+	return db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("basket_items.komoditas_id asc")
+	})
+}
+
+func (r *repository) GetAll(ctx context.Context) fx.Result[[]Basket] {
+	var baskets []Basket
+	if err := r.db.WithContext(ctx).Scopes(withItems).Order("name asc").Find(&baskets).Error; err != nil {
+		return fx.Err[[]Basket](fmt.Errorf("failed to get baskets: %w", err))
+	}
+	return fx.Ok(baskets)
+}
+
+func (r *repository) GetByID(ctx context.Context, id uint) fx.Result[*Basket] {
+	var basket Basket
+	err := r.db.WithContext(ctx).Scopes(withItems).First(&basket, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fx.Err[*Basket](fmt.Errorf("basket not found"))
+		}
+		return fx.Err[*Basket](fmt.Errorf("failed to get basket: %w", err))
+	}
+	return fx.Ok(&basket)
+}
+
+func (r *repository) GetByName(ctx context.Context, name string) fx.Result[*Basket] {
+	var basket Basket
+	err := r.db.WithContext(ctx).Scopes(withItems).Where("name = ?", name).First(&basket).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fx.Err[*Basket](fmt.Errorf("basket not found"))
+		}
+		return fx.Err[*Basket](fmt.Errorf("failed to get basket: %w", err))
+	}
+	return fx.Ok(&basket)
+}
+
+func (r *repository) Create(ctx context.Context, basket *Basket) fx.Result[*Basket] {
+	if err := r.db.WithContext(ctx).Create(basket).Error; err != nil {
+		return fx.Err[*Basket](fmt.Errorf("failed to create basket: %w", err))
+	}
+	return fx.Ok(basket)
+}
+
+// Update saves the basket fields and, when basket.Items is non-nil, replaces
+// the whole item list in the same transaction.
+func (r *repository) Update(ctx context.Context, id uint, basket *Basket) fx.Result[*Basket] {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Basket{}).Where("id = ?", id).Updates(map[string]any{
+			"name":        basket.Name,
+			"description": basket.Description,
+		}).Error; err != nil {
+			return err
+		}
+		if basket.Items == nil {
+			return nil
+		}
+		if err := tx.Where("basket_id = ?", id).Delete(&BasketItem{}).Error; err != nil {
+			return err
+		}
+		items := make([]BasketItem, len(basket.Items))
+		for i, item := range basket.Items {
+			items[i] = BasketItem{BasketID: id, KomoditasID: item.KomoditasID, Quantity: item.Quantity}
+		}
+		return tx.Create(&items).Error
+	})
+	if err != nil {
+		return fx.Err[*Basket](fmt.Errorf("failed to update basket: %w", err))
+	}
+	return r.GetByID(ctx, id)
+}
+
+// Delete removes the basket for good, so its name can be reused; the
+// foreign key cascades the delete to its items.
+func (r *repository) Delete(ctx context.Context, id uint) fx.Result[bool] {
+	if err := r.db.WithContext(ctx).Unscoped().Delete(&Basket{}, id).Error; err != nil {
+		return fx.Err[bool](fmt.Errorf("failed to delete basket: %w", err))
+	}
+	return fx.Ok(true)
+}
@@ -0,0 +1,232 @@
+package basket
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/ryuzxy/FuncPro/pkg/fx"
+	"github.com/ryuzxy/FuncPro/pkg/komoditas"
+	"github.com/ryuzxy/FuncPro/pkg/money"
+	"github.com/ryuzxy/FuncPro/pkg/price"
+)
+
+var tracer = otel.Tracer("github.com/ryuzxy/FuncPro/pkg/basket")
+
+// indexWorkers caps how many item price queries run at once.
+const indexWorkers = 4
+
+type Service interface {
+	GetAllBaskets(ctx context.Context) fx.Result[[]Basket]
+	GetBasketByID(ctx context.Context, id uint) fx.Result[*Basket]
+	CreateBasket(ctx context.Context, req CreateBasketRequest) fx.Result[*Basket]
+	UpdateBasket(ctx context.Context, id uint, req UpdateBasketRequest) fx.Result[*Basket]
+	DeleteBasket(ctx context.Context, id uint) fx.Result[bool]
+	GetBasketIndex(ctx context.Context, id uint, opts IndexOptions) fx.Result[Index]
+}
+
+type service struct {
+	repo      Repository
+	prices    price.Service
+	komoditas komoditas.Repository
+}
+
+func NewService(repo Repository, prices price.Service, komoditas komoditas.Repository) Service {
+	return &service{repo: repo, prices: prices, komoditas: komoditas}
+}
+
+// buildItems checks that every komoditas exists and appears only once.
+func (s *service) buildItems(ctx context.Context, reqs []BasketItemRequest) ([]BasketItem, error) {
+	if len(reqs) == 0 {
+		return nil, fmt.Errorf("basket needs at least one item")
+	}
+	seen := make(map[uint]bool, len(reqs))
+	items := make([]BasketItem, 0, len(reqs))
+	for _, req := range reqs {
+		if seen[req.KomoditasID] {
+			return nil, fmt.Errorf("komoditas %d is listed more than once", req.KomoditasID)
+		}
+		seen[req.KomoditasID] = true
+		if req.Quantity <= 0 {
+			return nil, fmt.Errorf("quantity for komoditas %d must be > 0", req.KomoditasID)
+		}
+		if s.komoditas.GetByID(ctx, req.KomoditasID).IsErr() {
+			return nil, fmt.Errorf("komoditas %d not found", req.KomoditasID)
+		}
+		items = append(items, BasketItem{KomoditasID: req.KomoditasID, Quantity: req.Quantity})
+	}
+	return items, nil
+}
+
+func (s *service) GetAllBaskets(ctx context.Context) fx.Result[[]Basket] {
+	ctx, span := tracer.Start(ctx, "basket.GetAllBaskets")
+	defer span.End()
+
+	return s.repo.GetAll(ctx)
+}
+
+func (s *service) GetBasketByID(ctx context.Context, id uint) fx.Result[*Basket] {
+	ctx, span := tracer.Start(ctx, "basket.GetBasketByID")
+	defer span.End()
+
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *service) CreateBasket(ctx context.Context, req CreateBasketRequest) fx.Result[*Basket] {
+	ctx, span := tracer.Start(ctx, "basket.CreateBasket")
+	defer span.End()
+
+	name := strings.TrimSpace(req.Name)
+	if s.repo.GetByName(ctx, name).IsOk() {
+		return fx.Err[*Basket](fmt.Errorf("basket %s already exists", name))
+	}
+
+	items, err := s.buildItems(ctx, req.Items)
+	if err != nil {
+		return fx.Err[*Basket](err)
+	}
+
+	basket := &Basket{
+		Name:        name,
+		Description: req.Description,
+		Items:       items,
+	}
+	return s.repo.Create(ctx, basket)
+}
+
+func (s *service) UpdateBasket(ctx context.Context, id uint, req UpdateBasketRequest) fx.Result[*Basket] {
+	ctx, span := tracer.Start(ctx, "basket.UpdateBasket")
+	defer span.End()
+
+	existing, err := s.repo.GetByID(ctx, id).Unwrap()
+	if err != nil {
+		return fx.Err[*Basket](fmt.Errorf("basket not found: %w", err))
+	}
+
+	if name := strings.TrimSpace(req.Name); name != "" && name != existing.Name {
+		if s.repo.GetByName(ctx, name).IsOk() {
+			return fx.Err[*Basket](fmt.Errorf("basket %s already exists", name))
+		}
+		existing.Name = name
+	}
+	if req.Description != "" {
+		existing.Description = req.Description
+	}
+	existing.Items = nil
+	if req.Items != nil {
+		items, err := s.buildItems(ctx, req.Items)
+		if err != nil {
+			return fx.Err[*Basket](err)
+		}
+		existing.Items = items
+	}
+
+	return s.repo.Update(ctx, id, existing)
+}
+
+func (s *service) DeleteBasket(ctx context.Context, id uint) fx.Result[bool] {
+	ctx, span := tracer.Start(ctx, "basket.DeleteBasket")
+	defer span.End()
+
+	return s.repo.Delete(ctx, id)
+}
+
+type itemBuckets struct {
+	komoditasID uint
+	buckets     []price.PriceBucket
+}
+
+// GetBasketIndex aggregates each item's prices per interval in parallel and
+// combines them into basket cost and index series. Periods are fetched from
+// the base period onwards even when it lies before the requested window, but
+// only points inside the window are returned.
+func (s *service) GetBasketIndex(ctx context.Context, id uint, opts IndexOptions) fx.Result[Index] {
+	ctx, span := tracer.Start(ctx, "basket.GetBasketIndex")
+	defer span.End()
+
+	if opts.Interval == "" {
+		opts.Interval = price.IntervalMonth
+	}
+	if !opts.Start.IsZero() && !opts.End.IsZero() && opts.Start.After(opts.End) {
+		return fx.Err[Index](fmt.Errorf("from must be before to"))
+	}
+
+	basket, err := s.repo.GetByID(ctx, id).Unwrap()
+	if err != nil {
+		return fx.Err[Index](err)
+	}
+
+	aggregate := price.AggregateOptions{
+		Interval: opts.Interval,
+		MarketID: opts.MarketID,
+		Start:    opts.Start,
+		End:      opts.End,
+		Values:   opts.Values,
+	}
+	if !opts.Base.IsZero() {
+		opts.Base = opts.Interval.Truncate(opts.Base)
+		if !aggregate.Start.IsZero() && opts.Base.Before(aggregate.Start) {
+			aggregate.Start = opts.Base
+		}
+		if !aggregate.End.IsZero() && opts.Base.After(aggregate.End) {
+			aggregate.End = opts.Base
+		}
+	}
+
+	fetched, err := fx.ParallelMap(ctx, basket.Items, func(ctx context.Context, item BasketItem) fx.FxResult[itemBuckets] {
+		buckets, err := s.prices.AggregatePrices(ctx, item.KomoditasID, aggregate).Unwrap()
+		if err != nil {
+			return fx.FxErr[itemBuckets](fmt.Errorf("komoditas %d: %w", item.KomoditasID, err))
+		}
+		return fx.FxOk(itemBuckets{komoditasID: item.KomoditasID, buckets: buckets})
+	}, indexWorkers).Unwrap()
+	if err != nil {
+		return fx.Err[Index](err)
+	}
+
+	prices := make(periodPrices, len(fetched))
+	for _, f := range fetched {
+		means := make(map[time.Time]money.Money, len(f.buckets))
+		for _, b := range f.buckets {
+			means[b.Period] = b.Mean
+		}
+		prices[f.komoditasID] = means
+	}
+
+	idx, err := computeIndex(basket.Items, prices, opts.Base)
+	if err != nil {
+		return fx.Err[Index](err)
+	}
+	idx.Basket = *basket
+	idx.Interval = opts.Interval
+	idx.MarketID = opts.MarketID
+
+	points := make([]IndexPoint, 0, len(idx.Points))
+	for _, p := range idx.Points {
+		if inWindow(p.Period, opts) {
+			points = append(points, p)
+		}
+	}
+	incomplete := make([]time.Time, 0, len(idx.Incomplete))
+	for _, period := range idx.Incomplete {
+		if inWindow(period, opts) {
+			incomplete = append(incomplete, period)
+		}
+	}
+	idx.Points, idx.Incomplete = points, incomplete
+	return fx.Ok(idx)
+}
+
+// inWindow reports whether a period overlaps the requested from/to window.
+func inWindow(period time.Time, opts IndexOptions) bool {
+	if !opts.Start.IsZero() && period.Before(opts.Interval.Truncate(opts.Start)) {
+		return false
+	}
+	if !opts.End.IsZero() && period.After(opts.End) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+package currency
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ryuzxy/FuncPro/pkg/money"
+)
+
+var csvColumns = []string{"date", "from_currency", "to_currency", "rate"}
+
+// parseCSV reads rows of date,from_currency,to_currency,rate with a header
+// line. Errors from all rows are joined so a bad file can be fixed in one
+// pass.
+func parseCSV(r io.Reader) ([]ExchangeRate, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading csv header: %w", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, col := range csvColumns {
+		if _, ok := index[col]; !ok {
+			return nil, fmt.Errorf("csv header is missing column %q", col)
+		}
+	}
+
+	var rates []ExchangeRate
+	var errs []error
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", line, err))
+			continue
+		}
+
+		rate, err := parseRecord(record, index)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", line, err))
+			continue
+		}
+		rates = append(rates, rate)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("csv contains no rates")
+	}
+	return rates, nil
+}
+
+func parseRecord(record []string, index map[string]int) (ExchangeRate, error) {
+	field := func(name string) string {
+		return strings.TrimSpace(record[index[name]])
+	}
+
+	date, err := time.Parse("2006-01-02", field("date"))
+	if err != nil {
+		return ExchangeRate{}, fmt.Errorf("invalid date %q", field("date"))
+	}
+	from, to, err := normalizePair(field("from_currency"), field("to_currency"))
+	if err != nil {
+		return ExchangeRate{}, err
+	}
+	rate, err := money.ParseRate(field("rate"))
+	if err != nil {
+		return ExchangeRate{}, err
+	}
+	if rate <= 0 {
+		return ExchangeRate{}, fmt.Errorf("rate must be > 0")
+	}
+
+	return ExchangeRate{FromCurrency: from, ToCurrency: to, Date: date, Rate: rate}, nil
+}
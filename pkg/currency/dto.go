@@ -0,0 +1,61 @@
+package currency
+
+import (
+	"time"
+
+	"github.com/ryuzxy/FuncPro/pkg/money"
+)
+
+// CreateExchangeRateRequest DTO for creating exchange rate
+type CreateExchangeRateRequest struct {
+	FromCurrency string     `json:"from_currency" binding:"required,len=3"`
+	ToCurrency   string     `json:"to_currency" binding:"required,len=3"`
+	Date         time.Time  `json:"date" binding:"required"`
+	Rate         money.Rate `json:"rate" binding:"required,gt=0"`
+}
+
+// UpdateExchangeRateRequest DTO for updating exchange rate
+type UpdateExchangeRateRequest struct {
+	Date time.Time  `json:"date"`
+	Rate money.Rate `json:"rate" binding:"omitempty,gt=0"`
+}
+
+// ListExchangeRatesQuery query parameters for listing exchange rates
+type ListExchangeRatesQuery struct {
+	FromCurrency string    `form:"from_currency" binding:"omitempty,len=3"`
+	ToCurrency   string    `form:"to_currency" binding:"omitempty,len=3"`
+	From         time.Time `form:"from" time_format:"2006-01-02"`
+	To           time.Time `form:"to" time_format:"2006-01-02"`
+}
+
+// ExchangeRateResponse DTO for exchange rate response
+type ExchangeRateResponse struct {
+	ID           uint       `json:"id"`
+	FromCurrency string     `json:"from_currency"`
+	ToCurrency   string     `json:"to_currency"`
+	Date         time.Time  `json:"date"`
+	Rate         money.Rate `json:"rate"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// ToResponse converts ExchangeRate to response DTO
+func ToResponse(r ExchangeRate) ExchangeRateResponse {
+	return ExchangeRateResponse{
+		ID:           r.ID,
+		FromCurrency: r.FromCurrency,
+		ToCurrency:   r.ToCurrency,
+		Date:         r.Date,
+		Rate:         r.Rate,
+		CreatedAt:    r.CreatedAt,
+		UpdatedAt:    r.UpdatedAt,
+	}
+}
+
+// ToFilter converts query parameters to a repository filter
+func (q ListExchangeRatesQuery) ToFilter() Filter {
+	f := Filter{Start: q.From, End: q.To}
+	f.FromCurrency, _ = NormalizeCode(q.FromCurrency)
+	f.ToCurrency, _ = NormalizeCode(q.ToCurrency)
+	return f
+}
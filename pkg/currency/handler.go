@@ -0,0 +1,234 @@
+package currency
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ryuzxy/FuncPro/pkg/fx"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) GetAllRates(c *gin.Context) {
+	var q ListExchangeRatesQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	result := h.service.GetAllRates(c.Request.Context(), q.ToFilter())
+
+	fx.Match(
+		result,
+		func(data []ExchangeRate) any {
+			responses := fx.Map(data, ToResponse)
+			c.JSON(http.StatusOK, gin.H{
+				"success": true,
+				"data":    responses,
+				"count":   len(responses),
+			})
+			return nil
+		},
+		func(err error) any {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return nil
+		},
+	)
+}
+
+func (h *Handler) GetRateByID(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	result := h.service.GetRateByID(c.Request.Context(), id)
+
+	fx.Match(
+		result,
+		func(data *ExchangeRate) any {
+			c.JSON(http.StatusOK, gin.H{
+				"success": true,
+				"data":    ToResponse(*data),
+			})
+			return nil
+		},
+		func(err error) any {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return nil
+		},
+	)
+}
+
+func (h *Handler) CreateRate(c *gin.Context) {
+	var req CreateExchangeRateRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result := h.service.CreateRate(c.Request.Context(), req)
+
+	fx.Match(
+		result,
+		func(data *ExchangeRate) any {
+			c.JSON(http.StatusCreated, gin.H{
+				"success": true,
+				"data":    ToResponse(*data),
+			})
+			return nil
+		},
+		func(err error) any {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return nil
+		},
+	)
+}
+
+func (h *Handler) UpdateRate(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateExchangeRateRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result := h.service.UpdateRate(c.Request.Context(), id, req)
+
+	fx.Match(
+		result,
+		func(data *ExchangeRate) any {
+			c.JSON(http.StatusOK, gin.H{
+				"success": true,
+				"data":    ToResponse(*data),
+			})
+			return nil
+		},
+		func(err error) any {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return nil
+		},
+	)
+}
+
+func (h *Handler) DeleteRate(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	result := h.service.DeleteRate(c.Request.Context(), id)
+
+	fx.Match(
+		result,
+		func(success bool) any {
+			c.JSON(http.StatusOK, gin.H{
+				"success": true,
+				"message": "Exchange rate deleted successfully",
+			})
+			return nil
+		},
+		func(err error) any {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return nil
+		},
+	)
+}
+
+// ImportRates accepts either a multipart upload in the "file" field or a
+// raw text/csv request body.
+func (h *Handler) ImportRates(c *gin.Context) {
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "missing csv file in field \"file\"",
+			})
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		defer f.Close()
+		body = f
+	}
+
+	result := h.service.ImportCSV(c.Request.Context(), body)
+
+	fx.Match(
+		result,
+		func(count int) any {
+			c.JSON(http.StatusCreated, gin.H{
+				"success": true,
+				"count":   count,
+			})
+			return nil
+		},
+		func(err error) any {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return nil
+		},
+	)
+}
+
+func parseID(c *gin.Context) (uint, bool) {
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid ID format",
+		})
+		return 0, false
+	}
+	return uint(id64), true
+}
+
+func bindJSON[T any](c *gin.Context, target *T) bool {
+	if err := c.ShouldBindJSON(target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return false
+	}
+	return true
+}
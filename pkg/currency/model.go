@@ -0,0 +1,46 @@
+package currency
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ryuzxy/FuncPro/pkg/money"
+)
+
+// Default is the currency prices are reported in unless stated otherwise.
+const Default = "IDR"
+
+// ExchangeRate says that one unit of FromCurrency buys Rate units of
+// ToCurrency from Date until the next rate for the same pair.
+type ExchangeRate struct {
+	ID           uint           `gorm:"primarykey" json:"id"`
+	FromCurrency string         `gorm:"size:3;not null;uniqueIndex:idx_exchange_rate_pair_date" json:"from_currency"`
+	ToCurrency   string         `gorm:"size:3;not null;uniqueIndex:idx_exchange_rate_pair_date" json:"to_currency"`
+	Date         time.Time      `gorm:"type:date;not null;uniqueIndex:idx_exchange_rate_pair_date" json:"date"`
+	Rate         money.Rate     `gorm:"type:numeric(24,10);not null" json:"rate"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+type Filter struct {
+	FromCurrency string
+	ToCurrency   string
+	Start        time.Time
+	End          time.Time
+}
+
+var codePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// NormalizeCode upper-cases an ISO 4217 code and checks its shape.
+func NormalizeCode(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if !codePattern.MatchString(code) {
+		return "", fmt.Errorf("invalid currency code %q", code)
+	}
+	return code, nil
+}
@@ -0,0 +1,102 @@
+package currency
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ryuzxy/FuncPro/pkg/fx"
+)
+
+type Repository interface {
+	GetAll(ctx context.Context, filter Filter) fx.Result[[]ExchangeRate]
+	GetByID(ctx context.Context, id uint) fx.Result[*ExchangeRate]
+	Create(ctx context.Context, rate *ExchangeRate) fx.Result[*ExchangeRate]
+	Update(ctx context.Context, id uint, rate *ExchangeRate) fx.Result[*ExchangeRate]
+	Delete(ctx context.Context, id uint) fx.Result[bool]
+	Upsert(ctx context.Context, rates []ExchangeRate) fx.Result[int]
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) GetAll(ctx context.Context, filter Filter) fx.Result[[]ExchangeRate] {
+	q := r.db.WithContext(ctx)
+	if filter.FromCurrency != "" {
+		q = q.Where("from_currency = ?", filter.FromCurrency)
+	}
+	if filter.ToCurrency != "" {
+		q = q.Where("to_currency = ?", filter.ToCurrency)
+	}
+	if !filter.Start.IsZero() {
+		q = q.Where("date >= ?", filter.Start)
+	}
+	if !filter.End.IsZero() {
+		q = q.Where("date <= ?", filter.End)
+	}
+
+	var rates []ExchangeRate
+	if err := q.Order("from_currency, to_currency, date asc").Find(&rates).Error; err != nil {
+		return fx.Err[[]ExchangeRate](fmt.Errorf("failed to get exchange rates: %w", err))
+	}
+	return fx.Ok(rates)
+}
+
+func (r *repository) GetByID(ctx context.Context, id uint) fx.Result[*ExchangeRate] {
+	var rate ExchangeRate
+	err := r.db.WithContext(ctx).First(&rate, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fx.Err[*ExchangeRate](fmt.Errorf("exchange rate not found"))
+		}
+		return fx.Err[*ExchangeRate](fmt.Errorf("failed to get exchange rate: %w", err))
+	}
+	return fx.Ok(&rate)
+}
+
+func (r *repository) Create(ctx context.Context, rate *ExchangeRate) fx.Result[*ExchangeRate] {
+	if err := r.db.WithContext(ctx).Create(rate).Error; err != nil {
+		return fx.Err[*ExchangeRate](fmt.Errorf("failed to create exchange rate: %w", err))
+	}
+	return fx.Ok(rate)
+}
+
+func (r *repository) Update(ctx context.Context, id uint, rate *ExchangeRate) fx.Result[*ExchangeRate] {
+	if err := r.db.WithContext(ctx).Model(&ExchangeRate{}).Where("id = ?", id).Updates(rate).Error; err != nil {
+		return fx.Err[*ExchangeRate](fmt.Errorf("failed to update exchange rate: %w", err))
+	}
+	return r.GetByID(ctx, id)
+}
+
+// Delete removes the row for good so a rate for the same pair and date can
+// be entered again.
+func (r *repository) Delete(ctx context.Context, id uint) fx.Result[bool] {
+	if err := r.db.WithContext(ctx).Unscoped().Delete(&ExchangeRate{}, id).Error; err != nil {
+		return fx.Err[bool](fmt.Errorf("failed to delete exchange rate: %w", err))
+	}
+	return fx.Ok(true)
+}
+
+// Upsert inserts rates, replacing the rate of an existing pair and date.
+func (r *repository) Upsert(ctx context.Context, rates []ExchangeRate) fx.Result[int] {
+	if len(rates) == 0 {
+		return fx.Ok(0)
+	}
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "from_currency"}, {Name: "to_currency"}, {Name: "date"}},
+			DoUpdates: clause.Assignments(map[string]any{"rate": gorm.Expr("EXCLUDED.rate"), "updated_at": gorm.Expr("EXCLUDED.updated_at"), "deleted_at": nil}),
+		}).
+		CreateInBatches(&rates, 100).Error
+	if err != nil {
+		return fx.Err[int](fmt.Errorf("failed to import exchange rates: %w", err))
+	}
+	return fx.Ok(len(rates))
+}
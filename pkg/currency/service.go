@@ -0,0 +1,116 @@
+package currency
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/ryuzxy/FuncPro/pkg/fx"
+)
+
+var tracer = otel.Tracer("github.com/ryuzxy/FuncPro/pkg/currency")
+
+type Service interface {
+	GetAllRates(ctx context.Context, filter Filter) fx.Result[[]ExchangeRate]
+	GetRateByID(ctx context.Context, id uint) fx.Result[*ExchangeRate]
+	CreateRate(ctx context.Context, req CreateExchangeRateRequest) fx.Result[*ExchangeRate]
+	UpdateRate(ctx context.Context, id uint, req UpdateExchangeRateRequest) fx.Result[*ExchangeRate]
+	DeleteRate(ctx context.Context, id uint) fx.Result[bool]
+	ImportCSV(ctx context.Context, r io.Reader) fx.Result[int]
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+func normalizePair(from, to string) (string, string, error) {
+	from, err := NormalizeCode(from)
+	if err != nil {
+		return "", "", err
+	}
+	to, err = NormalizeCode(to)
+	if err != nil {
+		return "", "", err
+	}
+	if from == to {
+		return "", "", fmt.Errorf("from_currency and to_currency must differ")
+	}
+	return from, to, nil
+}
+
+func (s *service) GetAllRates(ctx context.Context, filter Filter) fx.Result[[]ExchangeRate] {
+	ctx, span := tracer.Start(ctx, "currency.GetAllRates")
+	defer span.End()
+
+	return s.repo.GetAll(ctx, filter)
+}
+
+func (s *service) GetRateByID(ctx context.Context, id uint) fx.Result[*ExchangeRate] {
+	ctx, span := tracer.Start(ctx, "currency.GetRateByID")
+	defer span.End()
+
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *service) CreateRate(ctx context.Context, req CreateExchangeRateRequest) fx.Result[*ExchangeRate] {
+	ctx, span := tracer.Start(ctx, "currency.CreateRate")
+	defer span.End()
+
+	from, to, err := normalizePair(req.FromCurrency, req.ToCurrency)
+	if err != nil {
+		return fx.Err[*ExchangeRate](err)
+	}
+
+	rate := &ExchangeRate{
+		FromCurrency: from,
+		ToCurrency:   to,
+		Date:         req.Date,
+		Rate:         req.Rate,
+	}
+	return s.repo.Create(ctx, rate)
+}
+
+func (s *service) UpdateRate(ctx context.Context, id uint, req UpdateExchangeRateRequest) fx.Result[*ExchangeRate] {
+	ctx, span := tracer.Start(ctx, "currency.UpdateRate")
+	defer span.End()
+
+	existing, err := s.repo.GetByID(ctx, id).Unwrap()
+	if err != nil {
+		return fx.Err[*ExchangeRate](fmt.Errorf("exchange rate not found: %w", err))
+	}
+
+	if !req.Date.IsZero() {
+		existing.Date = req.Date
+	}
+	if req.Rate != 0 {
+		existing.Rate = req.Rate
+	}
+
+	return s.repo.Update(ctx, id, existing)
+}
+
+func (s *service) DeleteRate(ctx context.Context, id uint) fx.Result[bool] {
+	ctx, span := tracer.Start(ctx, "currency.DeleteRate")
+	defer span.End()
+
+	return s.repo.Delete(ctx, id)
+}
+
+// ImportCSV loads rates from CSV and upserts them in one go. Nothing is
+// written unless every row parses.
+func (s *service) ImportCSV(ctx context.Context, r io.Reader) fx.Result[int] {
+	ctx, span := tracer.Start(ctx, "currency.ImportCSV")
+	defer span.End()
+
+	rates, err := parseCSV(r)
+	if err != nil {
+		return fx.Err[int](err)
+	}
+	return s.repo.Upsert(ctx, rates)
+}
@@ -0,0 +1,65 @@
+package inflation
+
+import (
+	"time"
+
+	"github.com/ryuzxy/FuncPro/pkg/money"
+)
+
+// CreatePriceIndexRequest DTO for creating price index
+type CreatePriceIndexRequest struct {
+	Period string     `json:"period" binding:"required"`
+	Value  money.Rate `json:"value" binding:"required,gt=0"`
+	Source string     `json:"source" binding:"max=100"`
+}
+
+// UpdatePriceIndexRequest DTO for updating price index
+type UpdatePriceIndexRequest struct {
+	Value  money.Rate `json:"value" binding:"omitempty,gt=0"`
+	Source string     `json:"source" binding:"max=100"`
+}
+
+// ListPriceIndicesQuery query parameters for listing price indices
+type ListPriceIndicesQuery struct {
+	From string `form:"from"`
+	To   string `form:"to"`
+}
+
+// PriceIndexResponse DTO for price index response
+type PriceIndexResponse struct {
+	ID        uint       `json:"id"`
+	Period    string     `json:"period"`
+	Value     money.Rate `json:"value"`
+	Source    string     `json:"source"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// ToResponse converts PriceIndex to response DTO
+func ToResponse(p PriceIndex) PriceIndexResponse {
+	return PriceIndexResponse{
+		ID:        p.ID,
+		Period:    p.Period.Format(PeriodLayout),
+		Value:     p.Value,
+		Source:    p.Source,
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
+
+// ToFilter converts query parameters to a repository filter
+func (q ListPriceIndicesQuery) ToFilter() (Filter, error) {
+	var f Filter
+	var err error
+	if q.From != "" {
+		if f.Start, err = ParsePeriod(q.From); err != nil {
+			return Filter{}, err
+		}
+	}
+	if q.To != "" {
+		if f.End, err = ParsePeriod(q.To); err != nil {
+			return Filter{}, err
+		}
+	}
+	return f, nil
+}
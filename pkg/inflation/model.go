@@ -0,0 +1,47 @@
+package inflation
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ryuzxy/FuncPro/pkg/money"
+)
+
+// PeriodLayout is how index periods are written in requests and responses.
+const PeriodLayout = "2006-01"
+
+// PriceIndex is one month of a CPI or other deflator series. Period is
+// always the first day of its month.
+type PriceIndex struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	Period    time.Time      `gorm:"type:date;not null;uniqueIndex" json:"period"`
+	Value     money.Rate     `gorm:"type:numeric(24,10);not null" json:"value"`
+	Source    string         `gorm:"size:100" json:"source"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+type Filter struct {
+	Start time.Time
+	End   time.Time
+}
+
+// MonthStart truncates t to the first day of its month.
+func MonthStart(t time.Time) time.Time {
+	y, m, _ := t.Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// ParsePeriod accepts YYYY-MM or a full YYYY-MM-DD date and returns the
+// start of that month.
+func ParsePeriod(s string) (time.Time, error) {
+	for _, layout := range []string{PeriodLayout, "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return MonthStart(t), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid period %q, expected YYYY-MM", s)
+}
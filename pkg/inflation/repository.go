@@ -0,0 +1,77 @@
+package inflation
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/ryuzxy/FuncPro/pkg/fx"
+)
+
+type Repository interface {
+	GetAll(ctx context.Context, filter Filter) fx.Result[[]PriceIndex]
+	GetByID(ctx context.Context, id uint) fx.Result[*PriceIndex]
+	Create(ctx context.Context, index *PriceIndex) fx.Result[*PriceIndex]
+	Update(ctx context.Context, id uint, index *PriceIndex) fx.Result[*PriceIndex]
+	Delete(ctx context.Context, id uint) fx.Result[bool]
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) GetAll(ctx context.Context, filter Filter) fx.Result[[]PriceIndex] {
+	q := r.db.WithContext(ctx)
+	if !filter.Start.IsZero() {
+		q = q.Where("period >= ?", filter.Start)
+	}
+	if !filter.End.IsZero() {
+		q = q.Where("period <= ?", filter.End)
+	}
+
+	var indices []PriceIndex
+	if err := q.Order("period asc").Find(&indices).Error; err != nil {
+		return fx.Err[[]PriceIndex](fmt.Errorf("failed to get price indices: %w", err))
+	}
+	return fx.Ok(indices)
+}
+
+func (r *repository) GetByID(ctx context.Context, id uint) fx.Result[*PriceIndex] {
+	var index PriceIndex
+	err := r.db.WithContext(ctx).First(&index, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fx.Err[*PriceIndex](fmt.Errorf("price index not found"))
+		}
+		return fx.Err[*PriceIndex](fmt.Errorf("failed to get price index: %w", err))
+	}
+	return fx.Ok(&index)
+}
+
+func (r *repository) Create(ctx context.Context, index *PriceIndex) fx.Result[*PriceIndex] {
+	if err := r.db.WithContext(ctx).Create(index).Error; err != nil {
+		return fx.Err[*PriceIndex](fmt.Errorf("failed to create price index: %w", err))
+	}
+	return fx.Ok(index)
+}
+
+func (r *repository) Update(ctx context.Context, id uint, index *PriceIndex) fx.Result[*PriceIndex] {
+	if err := r.db.WithContext(ctx).Model(&PriceIndex{}).Where("id = ?", id).Updates(index).Error; err != nil {
+		return fx.Err[*PriceIndex](fmt.Errorf("failed to update price index: %w", err))
+	}
+	return r.GetByID(ctx, id)
+}
+
+// Delete removes the row for good so the month can be entered again; the
+// period is unique.
+func (r *repository) Delete(ctx context.Context, id uint) fx.Result[bool] {
+	if err := r.db.WithContext(ctx).Unscoped().Delete(&PriceIndex{}, id).Error; err != nil {
+		return fx.Err[bool](fmt.Errorf("failed to delete price index: %w", err))
+	}
+	return fx.Ok(true)
+}
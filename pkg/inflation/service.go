@@ -0,0 +1,85 @@
+package inflation
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/ryuzxy/FuncPro/pkg/fx"
+)
+
+var tracer = otel.Tracer("github.com/ryuzxy/FuncPro/pkg/inflation")
+
+type Service interface {
+	GetAllIndices(ctx context.Context, filter Filter) fx.Result[[]PriceIndex]
+	GetIndexByID(ctx context.Context, id uint) fx.Result[*PriceIndex]
+	CreateIndex(ctx context.Context, req CreatePriceIndexRequest) fx.Result[*PriceIndex]
+	UpdateIndex(ctx context.Context, id uint, req UpdatePriceIndexRequest) fx.Result[*PriceIndex]
+	DeleteIndex(ctx context.Context, id uint) fx.Result[bool]
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) GetAllIndices(ctx context.Context, filter Filter) fx.Result[[]PriceIndex] {
+	ctx, span := tracer.Start(ctx, "inflation.GetAllIndices")
+	defer span.End()
+
+	return s.repo.GetAll(ctx, filter)
+}
+
+func (s *service) GetIndexByID(ctx context.Context, id uint) fx.Result[*PriceIndex] {
+	ctx, span := tracer.Start(ctx, "inflation.GetIndexByID")
+	defer span.End()
+
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *service) CreateIndex(ctx context.Context, req CreatePriceIndexRequest) fx.Result[*PriceIndex] {
+	ctx, span := tracer.Start(ctx, "inflation.CreateIndex")
+	defer span.End()
+
+	period, err := ParsePeriod(req.Period)
+	if err != nil {
+		return fx.Err[*PriceIndex](err)
+	}
+
+	index := &PriceIndex{
+		Period: period,
+		Value:  req.Value,
+		Source: req.Source,
+	}
+	return s.repo.Create(ctx, index)
+}
+
+func (s *service) UpdateIndex(ctx context.Context, id uint, req UpdatePriceIndexRequest) fx.Result[*PriceIndex] {
+	ctx, span := tracer.Start(ctx, "inflation.UpdateIndex")
+	defer span.End()
+
+	existing, err := s.repo.GetByID(ctx, id).Unwrap()
+	if err != nil {
+		return fx.Err[*PriceIndex](fmt.Errorf("price index not found: %w", err))
+	}
+
+	if req.Value != 0 {
+		existing.Value = req.Value
+	}
+	if req.Source != "" {
+		existing.Source = req.Source
+	}
+
+	return s.repo.Update(ctx, id, existing)
+}
+
+func (s *service) DeleteIndex(ctx context.Context, id uint) fx.Result[bool] {
+	ctx, span := tracer.Start(ctx, "inflation.DeleteIndex")
+	defer span.End()
+
+	return s.repo.Delete(ctx, id)
+}
@@ -4,23 +4,30 @@ import "time"
 
 // CreateKomoditasRequest DTO for creating komoditas
 type CreateKomoditasRequest struct {
-	Name string `json:"name" binding:"required,min=1,max=100"`
-	Type string `json:"type" binding:"required,min=1,max=50"`
+	Name             string `json:"name" binding:"required,min=1,max=100"`
+	Type             string `json:"type" binding:"required,min=1,max=50"`
+	BaseUnit         string `json:"base_unit" binding:"omitempty,max=20"`
+	PieceWeightGrams *int64 `json:"piece_weight_grams" binding:"omitempty,gt=0"`
 }
 
-// UpdateKomoditasRequest DTO for updating komoditas
+// UpdateKomoditasRequest DTO for updating komoditas. The base unit is fixed
+// at creation because stored prices are already normalised to it; for the
+// same reason the piece weight can be set once but not changed.
 type UpdateKomoditasRequest struct {
-	Name string `json:"name" binding:"omitempty,min=1,max=100"`
-	Type string `json:"type" binding:"omitempty,min=1,max=50"`
+	Name             string `json:"name" binding:"omitempty,min=1,max=100"`
+	Type             string `json:"type" binding:"omitempty,min=1,max=50"`
+	PieceWeightGrams *int64 `json:"piece_weight_grams" binding:"omitempty,gt=0"`
 }
 
 // KomoditasResponse DTO for komoditas response
 type KomoditasResponse struct {
-	ID        uint      `json:"id"`
-	Name      string    `json:"name"`
-	Type      string    `json:"type"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID               uint      `json:"id"`
+	Name             string    `json:"name"`
+	Type             string    `json:"type"`
+	BaseUnit         string    `json:"base_unit"`
+	PieceWeightGrams *int64    `json:"piece_weight_grams"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
 }
 
 // KomoditasWithStatsResponse DTO for komoditas with stats
@@ -32,11 +39,13 @@ type KomoditasWithStatsResponse struct {
 // ToResponse converts Komoditas to response DTO
 func ToResponse(k Komoditas) KomoditasResponse {
 	return KomoditasResponse{
-		ID:        k.ID,
-		Name:      k.Name,
-		Type:      k.Type,
-		CreatedAt: k.CreatedAt,
-		UpdatedAt: k.UpdatedAt,
+		ID:               k.ID,
+		Name:             k.Name,
+		Type:             k.Type,
+		BaseUnit:         k.BaseUnit,
+		PieceWeightGrams: k.PieceWeightGrams,
+		CreatedAt:        k.CreatedAt,
+		UpdatedAt:        k.UpdatedAt,
 	}
 }
 
@@ -6,13 +6,18 @@ import (
 	"gorm.io/gorm"
 )
 
+// Komoditas is a tracked commodity. Prices are stored normalised to BaseUnit;
+// PieceWeightGrams lets prices reported per piece (butir, ekor, ...) be
+// converted to a mass base unit.
 type Komoditas struct {
-	ID        uint           `gorm:"primarykey" json:"id"`
-	Name      string         `gorm:"size:100;not null" json:"name"`
-	Type      string         `gorm:"size:50;not null" json:"type"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID               uint           `gorm:"primarykey" json:"id"`
+	Name             string         `gorm:"size:100;not null" json:"name"`
+	Type             string         `gorm:"size:50;not null" json:"type"`
+	BaseUnit         string         `gorm:"size:20;not null;default:kg" json:"base_unit"`
+	PieceWeightGrams *int64         `json:"piece_weight_grams"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 type PriceStats struct {
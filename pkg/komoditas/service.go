@@ -4,9 +4,14 @@ import (
 	"context"
 	"fmt"
 
+	"go.opentelemetry.io/otel"
+
 	"github.com/ryuzxy/FuncPro/pkg/fx"
+	"github.com/ryuzxy/FuncPro/pkg/unit"
 )
 
+var tracer = otel.Tracer("github.com/ryuzxy/FuncPro/pkg/komoditas")
+
 type Service interface {
 	GetAllKomoditas(ctx context.Context) fx.Result[[]Komoditas]
 	GetKomoditasByID(ctx context.Context, id uint) fx.Result[*Komoditas]
@@ -25,22 +30,44 @@ func NewService(repo Repository) Service {
 }
 
 func (s *service) GetAllKomoditas(ctx context.Context) fx.Result[[]Komoditas] {
+	ctx, span := tracer.Start(ctx, "komoditas.GetAllKomoditas")
+	defer span.End()
+
 	return s.repo.GetAll(ctx)
 }
 
 func (s *service) GetKomoditasByID(ctx context.Context, id uint) fx.Result[*Komoditas] {
+	ctx, span := tracer.Start(ctx, "komoditas.GetKomoditasByID")
+	defer span.End()
+
 	return s.repo.GetByID(ctx, id)
 }
 
 func (s *service) CreateKomoditas(ctx context.Context, req CreateKomoditasRequest) fx.Result[*Komoditas] {
+	ctx, span := tracer.Start(ctx, "komoditas.CreateKomoditas")
+	defer span.End()
+
+	baseUnit := req.BaseUnit
+	if baseUnit == "" {
+		baseUnit = "kg"
+	}
+	u, err := unit.Lookup(baseUnit)
+	if err != nil {
+		return fx.Err[*Komoditas](err)
+	}
+
 	kom := &Komoditas{
-		Name: req.Name,
-		Type: req.Type,
+		Name:             req.Name,
+		Type:             req.Type,
+		BaseUnit:         u.Code,
+		PieceWeightGrams: req.PieceWeightGrams,
 	}
 	return s.repo.Create(ctx, kom)
 }
 
 func (s *service) UpdateKomoditas(ctx context.Context, id uint, req UpdateKomoditasRequest) fx.Result[*Komoditas] {
+	ctx, span := tracer.Start(ctx, "komoditas.UpdateKomoditas")
+	defer span.End()
 
 	existing, err := s.repo.GetByID(ctx, id).Unwrap()
 	if err != nil {
@@ -53,15 +80,28 @@ func (s *service) UpdateKomoditas(ctx context.Context, id uint, req UpdateKomodi
 	if req.Type != "" {
 		existing.Type = req.Type
 	}
+	if req.PieceWeightGrams != nil {
+		// Prices reported per piece were normalised with the old weight.
+		if existing.PieceWeightGrams != nil && *existing.PieceWeightGrams != *req.PieceWeightGrams {
+			return fx.Err[*Komoditas](fmt.Errorf("piece_weight_grams is already set to %d and cannot be changed", *existing.PieceWeightGrams))
+		}
+		existing.PieceWeightGrams = req.PieceWeightGrams
+	}
 
 	return s.repo.Update(ctx, id, existing)
 }
 
 func (s *service) DeleteKomoditas(ctx context.Context, id uint) fx.Result[bool] {
+	ctx, span := tracer.Start(ctx, "komoditas.DeleteKomoditas")
+	defer span.End()
+
 	return s.repo.Delete(ctx, id)
 }
 
 func (s *service) GetKomoditasWithStats(ctx context.Context, id uint) fx.Result[KomoditasWithStats] {
+	ctx, span := tracer.Start(ctx, "komoditas.GetKomoditasWithStats")
+	defer span.End()
+
 	kom, err := s.repo.GetByID(ctx, id).Unwrap()
 	if err != nil {
 		return fx.Err[KomoditasWithStats](fmt.Errorf("komoditas not found: %w", err))
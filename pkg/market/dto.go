@@ -0,0 +1,71 @@
+package market
+
+import "time"
+
+// CreateMarketRequest DTO for creating market
+type CreateMarketRequest struct {
+	Code      string   `json:"code" binding:"omitempty,max=50"`
+	Name      string   `json:"name" binding:"required,min=1,max=100"`
+	Province  string   `json:"province" binding:"omitempty,max=100"`
+	Regency   string   `json:"regency" binding:"omitempty,max=100"`
+	Latitude  *float64 `json:"latitude" binding:"omitempty,gte=-90,lte=90"`
+	Longitude *float64 `json:"longitude" binding:"omitempty,gte=-180,lte=180"`
+	Type      string   `json:"type" binding:"omitempty,oneof=wholesale retail"`
+}
+
+// UpdateMarketRequest DTO for updating market
+type UpdateMarketRequest struct {
+	Code      string   `json:"code" binding:"omitempty,max=50"`
+	Name      string   `json:"name" binding:"omitempty,min=1,max=100"`
+	Province  string   `json:"province" binding:"omitempty,max=100"`
+	Regency   string   `json:"regency" binding:"omitempty,max=100"`
+	Latitude  *float64 `json:"latitude" binding:"omitempty,gte=-90,lte=90"`
+	Longitude *float64 `json:"longitude" binding:"omitempty,gte=-180,lte=180"`
+	Type      string   `json:"type" binding:"omitempty,oneof=wholesale retail"`
+}
+
+// ListMarketsQuery query parameters for listing markets
+type ListMarketsQuery struct {
+	Province string `form:"province"`
+	Regency  string `form:"regency"`
+	Type     string `form:"type" binding:"omitempty,oneof=wholesale retail"`
+}
+
+// MarketResponse DTO for market response
+type MarketResponse struct {
+	ID        uint      `json:"id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Province  string    `json:"province"`
+	Regency   string    `json:"regency"`
+	Latitude  *float64  `json:"latitude"`
+	Longitude *float64  `json:"longitude"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ToResponse converts Market to response DTO
+func ToResponse(m Market) MarketResponse {
+	return MarketResponse{
+		ID:        m.ID,
+		Code:      m.Code,
+		Name:      m.Name,
+		Province:  m.Province,
+		Regency:   m.Regency,
+		Latitude:  m.Latitude,
+		Longitude: m.Longitude,
+		Type:      m.Type,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+// ToFilter converts query parameters to a repository filter
+func (q ListMarketsQuery) ToFilter() Filter {
+	return Filter{
+		Province: NormalizeName(q.Province),
+		Regency:  NormalizeName(q.Regency),
+		Type:     q.Type,
+	}
+}
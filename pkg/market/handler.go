@@ -0,0 +1,186 @@
+package market
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ryuzxy/FuncPro/pkg/fx"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) GetAllMarkets(c *gin.Context) {
+	var q ListMarketsQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	result := h.service.GetAllMarkets(c.Request.Context(), q.ToFilter())
+
+	fx.Match(
+		result,
+		func(data []Market) any {
+			responses := fx.Map(data, ToResponse)
+			c.JSON(http.StatusOK, gin.H{
+				"success": true,
+				"data":    responses,
+				"count":   len(responses),
+			})
+			return nil
+		},
+		func(err error) any {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return nil
+		},
+	)
+}
+
+func (h *Handler) GetMarketByID(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	result := h.service.GetMarketByID(c.Request.Context(), id)
+
+	fx.Match(
+		result,
+		func(data *Market) any {
+			c.JSON(http.StatusOK, gin.H{
+				"success": true,
+				"data":    ToResponse(*data),
+			})
+			return nil
+		},
+		func(err error) any {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return nil
+		},
+	)
+}
+
+func (h *Handler) CreateMarket(c *gin.Context) {
+	var req CreateMarketRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result := h.service.CreateMarket(c.Request.Context(), req)
+
+	fx.Match(
+		result,
+		func(data *Market) any {
+			c.JSON(http.StatusCreated, gin.H{
+				"success": true,
+				"data":    ToResponse(*data),
+			})
+			return nil
+		},
+		func(err error) any {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return nil
+		},
+	)
+}
+
+func (h *Handler) UpdateMarket(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateMarketRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result := h.service.UpdateMarket(c.Request.Context(), id, req)
+
+	fx.Match(
+		result,
+		func(data *Market) any {
+			c.JSON(http.StatusOK, gin.H{
+				"success": true,
+				"data":    ToResponse(*data),
+			})
+			return nil
+		},
+		func(err error) any {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return nil
+		},
+	)
+}
+
+func (h *Handler) DeleteMarket(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	result := h.service.DeleteMarket(c.Request.Context(), id)
+
+	fx.Match(
+		result,
+		func(success bool) any {
+			c.JSON(http.StatusOK, gin.H{
+				"success": true,
+				"message": "Market deleted successfully",
+			})
+			return nil
+		},
+		func(err error) any {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return nil
+		},
+	)
+}
+
+func parseID(c *gin.Context) (uint, bool) {
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid ID format",
+		})
+		return 0, false
+	}
+	return uint(id64), true
+}
+
+func bindJSON[T any](c *gin.Context, target *T) bool {
+	if err := c.ShouldBindJSON(target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return false
+	}
+	return true
+}
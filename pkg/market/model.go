@@ -0,0 +1,51 @@
+package market
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	TypeWholesale = "wholesale"
+	TypeRetail    = "retail"
+)
+
+// Market rows are soft-deleted because prices keep pointing at them, so the
+// code is only unique among markets that have not been deleted.
+type Market struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	Code      string         `gorm:"size:50;not null;uniqueIndex:idx_markets_code_active,where:deleted_at IS NULL" json:"code"`
+	Name      string         `gorm:"size:100;not null" json:"name"`
+	Province  string         `gorm:"size:100;index" json:"province"`
+	Regency   string         `gorm:"size:100;index" json:"regency"`
+	Latitude  *float64       `json:"latitude"`
+	Longitude *float64       `json:"longitude"`
+	Type      string         `gorm:"size:20;not null;default:retail" json:"type"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+type Filter struct {
+	Province string
+	Regency  string
+	Type     string
+}
+
+var nonCode = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// NormalizeName trims and collapses whitespace so "pasar  induk " and
+// "pasar induk" end up as the same name.
+func NormalizeName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// CodeFromName derives the stable market code used to deduplicate free-text
+// market names, e.g. "Pasar Induk " -> "PASAR-INDUK".
+func CodeFromName(name string) string {
+	code := nonCode.ReplaceAllString(strings.ToUpper(NormalizeName(name)), "-")
+	return strings.Trim(code, "-")
+}
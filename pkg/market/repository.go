@@ -0,0 +1,104 @@
+package market
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/ryuzxy/FuncPro/pkg/fx"
+)
+
+type Repository interface {
+	GetAll(ctx context.Context, filter Filter) fx.Result[[]Market]
+	GetByID(ctx context.Context, id uint) fx.Result[*Market]
+	GetByCode(ctx context.Context, code string) fx.Result[*Market]
+	Create(ctx context.Context, market *Market) fx.Result[*Market]
+	Update(ctx context.Context, id uint, market *Market) fx.Result[*Market]
+	Delete(ctx context.Context, id uint) fx.Result[bool]
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) GetAll(ctx context.Context, filter Filter) fx.Result[[]Market] {
+	q := r.db.WithContext(ctx)
+	if filter.Province != "" {
+		q = q.Where("LOWER(province) = LOWER(?)", filter.Province)
+	}
+	if filter.Regency != "" {
+		q = q.Where("LOWER(regency) = LOWER(?)", filter.Regency)
+	}
+	if filter.Type != "" {
+		q = q.Where("type = ?", filter.Type)
+	}
+
+	var markets []Market
+	if err := q.Order("code asc").Find(&markets).Error; err != nil {
+		return fx.Err[[]Market](fmt.Errorf("failed to get markets: %w", err))
+	}
+	return fx.Ok(markets)
+}
+
+func (r *repository) GetByID(ctx context.Context, id uint) fx.Result[*Market] {
+	var market Market
+	err := r.db.WithContext(ctx).First(&market, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fx.Err[*Market](fmt.Errorf("market not found"))
+		}
+		return fx.Err[*Market](fmt.Errorf("failed to get market: %w", err))
+	}
+	return fx.Ok(&market)
+}
+
+func (r *repository) GetByCode(ctx context.Context, code string) fx.Result[*Market] {
+	var market Market
+	err := r.db.WithContext(ctx).Where("code = ?", code).First(&market).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fx.Err[*Market](fmt.Errorf("market not found"))
+		}
+		return fx.Err[*Market](fmt.Errorf("failed to get market by code: %w", err))
+	}
+	return fx.Ok(&market)
+}
+
+func (r *repository) Create(ctx context.Context, market *Market) fx.Result[*Market] {
+	if err := r.db.WithContext(ctx).Create(market).Error; err != nil {
+		return fx.Err[*Market](fmt.Errorf("failed to create market: %w", err))
+	}
+	return fx.Ok(market)
+}
+
+// Update also copies a new name onto the legacy prices.market column of the
+// market's prices.
+func (r *repository) Update(ctx context.Context, id uint, market *Market) fx.Result[*Market] {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Market{}).Where("id = ?", id).Updates(market).Error; err != nil {
+			return err
+		}
+		if market.Name == "" {
+			return nil
+		}
+		return tx.Table("prices").
+			Where("market_id = ? AND market IS DISTINCT FROM ?", id, market.Name).
+			Update("market", market.Name).Error
+	})
+	if err != nil {
+		return fx.Err[*Market](fmt.Errorf("failed to update market: %w", err))
+	}
+	return r.GetByID(ctx, id)
+}
+
+func (r *repository) Delete(ctx context.Context, id uint) fx.Result[bool] {
+	if err := r.db.WithContext(ctx).Delete(&Market{}, id).Error; err != nil {
+		return fx.Err[bool](fmt.Errorf("failed to delete market: %w", err))
+	}
+	return fx.Ok(true)
+}
@@ -0,0 +1,127 @@
+package market
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/ryuzxy/FuncPro/pkg/fx"
+)
+
+var tracer = otel.Tracer("github.com/ryuzxy/FuncPro/pkg/market")
+
+type Service interface {
+	GetAllMarkets(ctx context.Context, filter Filter) fx.Result[[]Market]
+	GetMarketByID(ctx context.Context, id uint) fx.Result[*Market]
+	CreateMarket(ctx context.Context, req CreateMarketRequest) fx.Result[*Market]
+	UpdateMarket(ctx context.Context, id uint, req UpdateMarketRequest) fx.Result[*Market]
+	DeleteMarket(ctx context.Context, id uint) fx.Result[bool]
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+func validateCoordinates(lat, lng *float64) error {
+	if (lat == nil) != (lng == nil) {
+		return fmt.Errorf("latitude and longitude must be given together")
+	}
+	return nil
+}
+
+func (s *service) GetAllMarkets(ctx context.Context, filter Filter) fx.Result[[]Market] {
+	ctx, span := tracer.Start(ctx, "market.GetAllMarkets")
+	defer span.End()
+
+	return s.repo.GetAll(ctx, filter)
+}
+
+func (s *service) GetMarketByID(ctx context.Context, id uint) fx.Result[*Market] {
+	ctx, span := tracer.Start(ctx, "market.GetMarketByID")
+	defer span.End()
+
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *service) CreateMarket(ctx context.Context, req CreateMarketRequest) fx.Result[*Market] {
+	ctx, span := tracer.Start(ctx, "market.CreateMarket")
+	defer span.End()
+
+	if err := validateCoordinates(req.Latitude, req.Longitude); err != nil {
+		return fx.Err[*Market](err)
+	}
+
+	code := CodeFromName(req.Code)
+	if code == "" {
+		code = CodeFromName(req.Name)
+	}
+	if s.repo.GetByCode(ctx, code).IsOk() {
+		return fx.Err[*Market](fmt.Errorf("market with code %s already exists", code))
+	}
+
+	mkt := &Market{
+		Code:      code,
+		Name:      NormalizeName(req.Name),
+		Province:  NormalizeName(req.Province),
+		Regency:   NormalizeName(req.Regency),
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		Type:      req.Type,
+	}
+	if mkt.Type == "" {
+		mkt.Type = TypeRetail
+	}
+	return s.repo.Create(ctx, mkt)
+}
+
+func (s *service) UpdateMarket(ctx context.Context, id uint, req UpdateMarketRequest) fx.Result[*Market] {
+	ctx, span := tracer.Start(ctx, "market.UpdateMarket")
+	defer span.End()
+
+	if err := validateCoordinates(req.Latitude, req.Longitude); err != nil {
+		return fx.Err[*Market](err)
+	}
+
+	existing, err := s.repo.GetByID(ctx, id).Unwrap()
+	if err != nil {
+		return fx.Err[*Market](fmt.Errorf("market not found: %w", err))
+	}
+
+	if req.Code != "" {
+		code := CodeFromName(req.Code)
+		if other, err := s.repo.GetByCode(ctx, code).Unwrap(); err == nil && other.ID != id {
+			return fx.Err[*Market](fmt.Errorf("market with code %s already exists", code))
+		}
+		existing.Code = code
+	}
+	if req.Name != "" {
+		existing.Name = NormalizeName(req.Name)
+	}
+	if req.Province != "" {
+		existing.Province = NormalizeName(req.Province)
+	}
+	if req.Regency != "" {
+		existing.Regency = NormalizeName(req.Regency)
+	}
+	if req.Latitude != nil {
+		existing.Latitude = req.Latitude
+		existing.Longitude = req.Longitude
+	}
+	if req.Type != "" {
+		existing.Type = req.Type
+	}
+
+	return s.repo.Update(ctx, id, existing)
+}
+
+func (s *service) DeleteMarket(ctx context.Context, id uint) fx.Result[bool] {
+	ctx, span := tracer.Start(ctx, "market.DeleteMarket")
+	defer span.End()
+
+	return s.repo.Delete(ctx, id)
+}
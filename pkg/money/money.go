@@ -0,0 +1,245 @@
+package money
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Scale is the number of fractional digits a Money value keeps.
+const Scale = 4
+
+const factor = 10000
+
+// Money is an exact fixed-point decimal amount stored as an integer number
+// of ten-thousandths. It maps onto numeric(18,4) in Postgres and is encoded
+// as a plain JSON number.
+type Money int64
+
+// ErrOverflow is returned when a result does not fit in 64 bits.
+var ErrOverflow = errors.New("money: result out of range")
+
+// decimal is what Parse accepts: an optionally signed decimal with an
+// optional, short exponent. big.Rat alone would also take "1/4" and hex.
+var decimal = regexp.MustCompile(`^[+-]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][+-]?[0-9]{1,3})?$`)
+
+func FromInt(n int64) Money {
+	return Money(n * factor)
+}
+
+// FromFloat rounds f to the nearest ten-thousandth. Only use it at the edges
+// where a float is all there is, e.g. a database driver handing back float8.
+func FromFloat(f float64) Money {
+	return Money(math.Round(f * factor))
+}
+
+// Parse reads a decimal string such as "12500", "-3.25" or "1e3". Values
+// with more than Scale fractional digits are rejected rather than rounded.
+func Parse(s string) (Money, error) {
+	n, err := parseScaled(s, factor, Scale)
+	return Money(n), err
+}
+
+func parseScaled(s string, scale int64, digits int) (int64, error) {
+	trimmed := strings.TrimSpace(s)
+	if !decimal.MatchString(trimmed) {
+		return 0, fmt.Errorf("money: invalid amount %q", s)
+	}
+	r, ok := new(big.Rat).SetString(trimmed)
+	if !ok {
+		return 0, fmt.Errorf("money: invalid amount %q", s)
+	}
+	r.Mul(r, new(big.Rat).SetInt64(scale))
+	if !r.IsInt() {
+		return 0, fmt.Errorf("money: %q has more than %d decimal places", s, digits)
+	}
+	n := r.Num()
+	if !n.IsInt64() {
+		return 0, fmt.Errorf("money: %q out of range", s)
+	}
+	return n.Int64(), nil
+}
+
+func MustParse(s string) Money {
+	m, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func (m Money) Add(o Money) Money { return m + o }
+
+func (m Money) Sub(o Money) Money { return m - o }
+
+func (m Money) Mul(n int64) Money { return m * Money(n) }
+
+// Div divides by a positive count n, rounding half away from zero. The
+// result is no larger than m, so unlike MulRat it cannot overflow.
+func (m Money) Div(n int64) Money {
+	return Money(mulRat(int64(m), 1, n).Int64())
+}
+
+// MulRat multiplies by num/den, rounding half away from zero. The
+// intermediate product is computed with big integers; ErrOverflow is
+// returned when the rounded result does not fit.
+func (m Money) MulRat(num, den int64) (Money, error) {
+	q := mulRat(int64(m), num, den)
+	if !q.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return Money(q.Int64()), nil
+}
+
+func mulRat(m, num, den int64) *big.Int {
+	if den == 0 {
+		panic("money: division by zero")
+	}
+	p := new(big.Int).Mul(big.NewInt(m), big.NewInt(num))
+	d := big.NewInt(den)
+	if d.Sign() < 0 {
+		p.Neg(p)
+		d.Neg(d)
+	}
+
+	q, r := new(big.Int).QuoRem(p, d, new(big.Int))
+	r.Abs(r).Lsh(r, 1)
+	if r.Cmp(d) >= 0 {
+		if p.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+// Times multiplies by a decimal quantity q (e.g. 2.5 kg at m per kg),
+// rounding half away from zero.
+func (m Money) Times(q Money) (Money, error) {
+	return m.MulRat(int64(q), factor)
+}
+
+// Ratio returns m/o as a float, for percentages and other dimensionless
+// figures. It returns 0 when o is zero.
+func (m Money) Ratio(o Money) float64 {
+	if o == 0 {
+		return 0
+	}
+	return float64(m) / float64(o)
+}
+
+func (m Money) Float64() float64 {
+	return float64(m) / factor
+}
+
+func (m Money) IsZero() bool { return m == 0 }
+
+func (m Money) String() string {
+	return formatScaled(int64(m), factor, Scale)
+}
+
+func formatScaled(n, scale int64, digits int) string {
+	sign := ""
+	u := uint64(n)
+	if n < 0 {
+		sign = "-"
+		u = uint64(-n)
+	}
+	whole := strconv.FormatUint(u/uint64(scale), 10)
+	frac := u % uint64(scale)
+	if frac == 0 {
+		return sign + whole
+	}
+	return sign + whole + "." + strings.TrimRight(fmt.Sprintf("%0*d", digits, frac), "0")
+}
+
+// Sum adds the values exactly.
+func Sum(values []Money) Money {
+	var total Money
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+// Mean is the exact sum divided by the count, rounded to Scale digits.
+func Mean(values []Money) Money {
+	if len(values) == 0 {
+		return 0
+	}
+	return Sum(values).Div(int64(len(values)))
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts both JSON numbers and quoted decimal strings. The
+// literal is parsed directly so it never round-trips through float64.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s, ok := jsonLiteral(data)
+	if !ok {
+		return nil
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// jsonLiteral strips optional quotes from a JSON number or string. It
+// reports false for null.
+func jsonLiteral(data []byte) (string, bool) {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		return "", false
+	}
+	s := string(data)
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	return s, true
+}
+
+// Scan implements sql.Scanner for numeric columns.
+func (m *Money) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+		return nil
+	case []byte:
+		return m.scanString(string(v))
+	case string:
+		return m.scanString(v)
+	case int64:
+		*m = FromInt(v)
+		return nil
+	case float64:
+		*m = FromFloat(v)
+		return nil
+	}
+	return fmt.Errorf("money: cannot scan %T", src)
+}
+
+func (m *Money) scanString(s string) error {
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// Value implements driver.Valuer, sending the exact decimal text.
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
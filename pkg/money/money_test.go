@@ -0,0 +1,197 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Money
+		wantErr bool
+	}{
+		{"12500", 125000000, false},
+		{"-3.25", -32500, false},
+		{" 0.0001 ", 1, false},
+		{"1e3", 10000000, false},
+		{".5", 5000, false},
+		{"+2.", 20000, false},
+		{"0.00001", 0, true},
+		{"1/4", 0, true},
+		{"0x10", 0, true},
+		{"1_000", 0, true},
+		{"abc", 0, true},
+		{"", 0, true},
+		{"1e9999", 0, true},
+		{"99999999999999999999", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Parse(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMulRatRounding(t *testing.T) {
+	tests := []struct {
+		name     string
+		m        Money
+		num, den int64
+		want     Money
+	}{
+		{"exact", MustParse("10"), 1, 4, MustParse("2.5")},
+		{"half up", 5, 1, 2, 3},
+		{"half away from zero", -5, 1, 2, -3},
+		{"below half", 4, 1, 3, 1},
+		{"negative den", 5, 1, -2, -3},
+		{"kg to g", MustParse("15000"), 1, 1000, MustParse("15")},
+		{"thirds", MustParse("100"), 1, 3, MustParse("33.3333")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.m.MulRat(tt.num, tt.den)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("%s.MulRat(%d, %d) = %s, want %s", tt.m, tt.num, tt.den, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMulRatOverflow(t *testing.T) {
+	if _, err := Money(math.MaxInt64/2+1).MulRat(2, 1); !errors.Is(err, ErrOverflow) {
+		t.Errorf("err = %v, want ErrOverflow", err)
+	}
+	if _, err := MustParse("900000000000000").Times(MustParse("1000")); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Times err = %v, want ErrOverflow", err)
+	}
+	if _, err := Rate(1).Invert(); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Invert err = %v, want ErrOverflow", err)
+	}
+}
+
+func TestMean(t *testing.T) {
+	got := Mean([]Money{MustParse("1"), MustParse("2"), MustParse("2")})
+	if got != MustParse("1.6667") {
+		t.Errorf("Mean = %s, want 1.6667", got)
+	}
+	if Mean(nil) != 0 {
+		t.Error("Mean(nil) != 0")
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{0, "0"},
+		{MustParse("12500"), "12500"},
+		{MustParse("12.5"), "12.5"},
+		{MustParse("-0.0001"), "-0.0001"},
+		{MustParse("1.2340"), "1.234"},
+	}
+	for _, tt := range tests {
+		if got := tt.m.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Money
+	}{
+		{`12.5`, MustParse("12.5")},
+		{`"12.5"`, MustParse("12.5")},
+		{`0.1`, MustParse("0.1")},
+		{`null`, 0},
+	}
+	for _, tt := range tests {
+		var m Money
+		if err := json.Unmarshal([]byte(tt.in), &m); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", tt.in, err)
+		}
+		if m != tt.want {
+			t.Errorf("Unmarshal(%s) = %s, want %s", tt.in, m, tt.want)
+		}
+	}
+
+	out, err := json.Marshal(struct {
+		Value Money `json:"value"`
+	}{MustParse("0.1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `{"value":0.1}` {
+		t.Errorf("Marshal = %s", out)
+	}
+
+	var m Money
+	if err := json.Unmarshal([]byte(`"1/4"`), &m); err == nil {
+		t.Error("Unmarshal accepted a fraction")
+	}
+}
+
+func TestSQL(t *testing.T) {
+	v, err := MustParse("1234.5").Value()
+	if err != nil || v != "1234.5" {
+		t.Errorf("Value() = %v, %v", v, err)
+	}
+
+	tests := []struct {
+		src  any
+		want Money
+	}{
+		{[]byte("1234.5000"), MustParse("1234.5")},
+		{"0.0001", 1},
+		{int64(7), MustParse("7")},
+		{float64(0.1), MustParse("0.1")},
+		{nil, 0},
+	}
+	for _, tt := range tests {
+		m := Money(99)
+		if err := m.Scan(tt.src); err != nil {
+			t.Fatalf("Scan(%v): %v", tt.src, err)
+		}
+		if m != tt.want {
+			t.Errorf("Scan(%v) = %s, want %s", tt.src, m, tt.want)
+		}
+	}
+
+	var m Money
+	if err := m.Scan(true); err == nil {
+		t.Error("Scan(bool) succeeded")
+	}
+}
+
+func TestRate(t *testing.T) {
+	r, err := ParseRate("0.0000612")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.String() != "0.0000612" {
+		t.Errorf("String() = %s", r)
+	}
+	got, err := MustParse("1000000").MulRate(r)
+	if err != nil || got != MustParse("61.2") {
+		t.Errorf("MulRate = %s, %v, want 61.2", got, err)
+	}
+	usd, _ := ParseRate("16000")
+	idr, err := usd.Invert()
+	if err != nil || idr.String() != "0.0000625" {
+		t.Errorf("Invert = %s, %v, want 0.0000625", idr, err)
+	}
+}
@@ -0,0 +1,86 @@
+package money
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// RateScale is the number of fractional digits a Rate keeps, enough for
+// quotes like 1 IDR = 0.0000612 USD.
+const RateScale = 10
+
+const rateFactor = 10000000000
+
+// Rate is an exact conversion factor (e.g. an exchange rate) stored as an
+// integer number of 1e-10 units. It maps onto numeric(24,10).
+type Rate int64
+
+func ParseRate(s string) (Rate, error) {
+	n, err := parseScaled(s, rateFactor, RateScale)
+	return Rate(n), err
+}
+
+// Invert returns 1/r rounded to RateScale digits. Rates below 1e-9 have no
+// representable inverse and give ErrOverflow.
+func (r Rate) Invert() (Rate, error) {
+	if r == 0 {
+		return 0, nil
+	}
+	inv, err := Money(rateFactor).MulRat(rateFactor, int64(r))
+	return Rate(inv), err
+}
+
+func (r Rate) String() string {
+	return formatScaled(int64(r), rateFactor, RateScale)
+}
+
+// MulRate converts m with the given rate, rounding half away from zero.
+func (m Money) MulRate(r Rate) (Money, error) {
+	return m.MulRat(int64(r), rateFactor)
+}
+
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	s, ok := jsonLiteral(data)
+	if !ok {
+		return nil
+	}
+	v, err := ParseRate(s)
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
+}
+
+func (r *Rate) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*r = 0
+		return nil
+	case []byte:
+		return r.scanString(string(v))
+	case string:
+		return r.scanString(v)
+	case int64:
+		*r = Rate(v * rateFactor)
+		return nil
+	}
+	return fmt.Errorf("money: cannot scan %T into Rate", src)
+}
+
+func (r *Rate) scanString(s string) error {
+	v, err := ParseRate(s)
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
+}
+
+func (r Rate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
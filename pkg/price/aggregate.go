@@ -0,0 +1,53 @@
+package price
+
+import (
+    "fmt"
+    "time"
+)
+
+// Interval is the bucket size used when resampling prices. The values map
+// directly onto the Postgres date_trunc field names.
+type Interval string
+
+const (
+    IntervalDay     Interval = "day"
+    IntervalWeek    Interval = "week"
+    IntervalMonth   Interval = "month"
+    IntervalQuarter Interval = "quarter"
+)
+
+func ParseInterval(s string) (Interval, error) {
+    switch Interval(s) {
+    case "":
+        return IntervalDay, nil
+    case IntervalDay, IntervalWeek, IntervalMonth, IntervalQuarter:
+        return Interval(s), nil
+    }
+    return "", fmt.Errorf("invalid interval %q", s)
+}
+
+// Truncate returns the start of the bucket t falls into, matching
+// date_trunc semantics (weeks start on Monday).
+func (i Interval) Truncate(t time.Time) time.Time {
+    d := truncateDay(t)
+    switch i {
+    case IntervalWeek:
+        offset := (int(d.Weekday()) + 6) % 7
+        return d.AddDate(0, 0, -offset)
+    case IntervalMonth:
+        return time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, time.UTC)
+    case IntervalQuarter:
+        month := time.Month((int(d.Month())-1)/3*3 + 1)
+        return time.Date(d.Year(), month, 1, 0, 0, 0, 0, time.UTC)
+    }
+    return d
+}
+
+type AggregateOptions struct {
+    Interval Interval
+    ByMarket bool
+    MarketID *uint
+    Start    time.Time
+    End      time.Time
+    Values   ValueOptions
+}
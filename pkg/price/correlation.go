@@ -0,0 +1,224 @@
+package price
+
+import (
+    "math"
+    "sort"
+    "time"
+
+    "github.com/ryuzxy/FuncPro/pkg/money"
+)
+
+const maxCorrelationLag = 30
+
+// maxCorrelationIDs bounds the matrix size, and correlationWorkers how many
+// komoditas price queries run at once.
+const (
+    maxCorrelationIDs  = 20
+    correlationWorkers = 4
+)
+
+type CorrelationOptions struct {
+    IDs    []uint
+    Start  time.Time
+    End    time.Time
+    MaxLag int
+    Values ValueOptions
+}
+
+// LagCorrelation is the correlation of A's returns with B's returns Lag
+// observations later. A positive best lag means A tends to lead B.
+type LagCorrelation struct {
+    A       uint
+    B       uint
+    BestLag int
+    Best    float64
+    ByLag   []LagValue
+}
+
+type LagValue struct {
+    Lag         int
+    Correlation float64
+}
+
+// Correlation holds matrices indexed like IDs. Entries are NaN when a
+// series has no variance over the common dates.
+type Correlation struct {
+    IDs          []uint
+    Dates        []time.Time
+    Observations int
+    Pearson      [][]float64
+    Spearman     [][]float64
+    Lags         []LagCorrelation
+}
+
+// alignSeries averages each komoditas per day and keeps only the days every
+// komoditas has a price on.
+func alignSeries(ids []uint, prices map[uint][]Price) ([]time.Time, [][]money.Money) {
+    daily := make([]map[time.Time]money.Money, len(ids))
+    for i, id := range ids {
+        sums := make(map[time.Time]money.Money)
+        counts := make(map[time.Time]int64)
+        for _, p := range prices[id] {
+            d := truncateDay(p.Date)
+            sums[d] = sums[d].Add(p.Value)
+            counts[d]++
+        }
+        daily[i] = make(map[time.Time]money.Money, len(sums))
+        for d, sum := range sums {
+            daily[i][d] = sum.Div(counts[d])
+        }
+    }
+
+    var dates []time.Time
+    if len(daily) > 0 {
+        for d := range daily[0] {
+            common := true
+            for _, series := range daily[1:] {
+                if _, ok := series[d]; !ok {
+                    common = false
+                    break
+                }
+            }
+            if common {
+                dates = append(dates, d)
+            }
+        }
+    }
+    sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+    values := make([][]money.Money, len(ids))
+    for i := range ids {
+        values[i] = make([]money.Money, len(dates))
+        for j, d := range dates {
+            values[i][j] = daily[i][d]
+        }
+    }
+    return dates, values
+}
+
+// returns turns a price series into simple period-over-period returns.
+func returns(values []money.Money) []float64 {
+    if len(values) < 2 {
+        return []float64{}
+    }
+    out := make([]float64, len(values)-1)
+    for i := 1; i < len(values); i++ {
+        out[i-1] = values[i].Ratio(values[i-1]) - 1
+    }
+    return out
+}
+
+func pearson(x, y []float64) float64 {
+    if len(x) != len(y) || len(x) < 2 {
+        return math.NaN()
+    }
+    mx, my := AveragePrice(x), AveragePrice(y)
+    var sxy, sxx, syy float64
+    for i := range x {
+        dx, dy := x[i]-mx, y[i]-my
+        sxy += dx * dy
+        sxx += dx * dx
+        syy += dy * dy
+    }
+    if sxx == 0 || syy == 0 {
+        return math.NaN()
+    }
+    return sxy / math.Sqrt(sxx*syy)
+}
+
+// ranks assigns 1-based ranks, giving tied values their average rank.
+func ranks(values []float64) []float64 {
+    order := make([]int, len(values))
+    for i := range order {
+        order[i] = i
+    }
+    sort.SliceStable(order, func(a, b int) bool { return values[order[a]] < values[order[b]] })
+
+    out := make([]float64, len(values))
+    for i := 0; i < len(order); {
+        j := i
+        for j+1 < len(order) && values[order[j+1]] == values[order[i]] {
+            j++
+        }
+        rank := float64(i+j)/2 + 1
+        for k := i; k <= j; k++ {
+            out[order[k]] = rank
+        }
+        i = j + 1
+    }
+    return out
+}
+
+func spearman(x, y []float64) float64 {
+    return pearson(ranks(x), ranks(y))
+}
+
+// laggedPearson correlates x[t] with y[t+lag]; a negative lag shifts the
+// other way.
+func laggedPearson(x, y []float64, lag int) float64 {
+    if lag < 0 {
+        return laggedPearson(y, x, -lag)
+    }
+    if lag >= len(x) {
+        return math.NaN()
+    }
+    return pearson(x[:len(x)-lag], y[lag:])
+}
+
+func correlationMatrix(series [][]float64, fn func(x, y []float64) float64) [][]float64 {
+    m := make([][]float64, len(series))
+    for i := range series {
+        m[i] = make([]float64, len(series))
+    }
+    for i := range series {
+        for j := i; j < len(series); j++ {
+            c := fn(series[i], series[j])
+            if i == j && !math.IsNaN(c) {
+                c = 1
+            }
+            m[i][j], m[j][i] = c, c
+        }
+    }
+    return m
+}
+
+// analyzeCorrelation correlates returns rather than price levels, since
+// levels of almost any two trending series correlate strongly.
+func analyzeCorrelation(ids []uint, prices map[uint][]Price, maxLag int) (Correlation, error) {
+    dates, values := alignSeries(ids, prices)
+    if len(dates) < 3 {
+        return Correlation{}, invalidf("need at least 3 common dates, found %d", len(dates))
+    }
+
+    series := make([][]float64, len(ids))
+    for i := range ids {
+        series[i] = returns(values[i])
+    }
+
+    c := Correlation{
+        IDs:          ids,
+        Dates:        dates,
+        Observations: len(series[0]),
+        Pearson:      correlationMatrix(series, pearson),
+        Spearman:     correlationMatrix(series, spearman),
+        Lags:         []LagCorrelation{},
+    }
+    if maxLag <= 0 {
+        return c, nil
+    }
+
+    for i := range ids {
+        for j := i + 1; j < len(ids); j++ {
+            lc := LagCorrelation{A: ids[i], B: ids[j], Best: math.NaN(), ByLag: make([]LagValue, 0, 2*maxLag+1)}
+            for lag := -maxLag; lag <= maxLag; lag++ {
+                r := laggedPearson(series[i], series[j], lag)
+                lc.ByLag = append(lc.ByLag, LagValue{Lag: lag, Correlation: r})
+                if !math.IsNaN(r) && (math.IsNaN(lc.Best) || math.Abs(r) > math.Abs(lc.Best)) {
+                    lc.BestLag, lc.Best = lag, r
+                }
+            }
+            c.Lags = append(c.Lags, lc)
+        }
+    }
+    return c, nil
+}
@@ -0,0 +1,144 @@
+package price
+
+import (
+    "encoding/csv"
+    "errors"
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/ryuzxy/FuncPro/pkg/money"
+)
+
+// CSVColumns is the header WriteCSV writes. ParseCSV needs date,
+// komoditas_id and value; the other columns are optional.
+var CSVColumns = []string{"date", "komoditas_id", "value", "unit", "currency", "market_id", "market"}
+
+var requiredCSVColumns = []string{"date", "komoditas_id", "value"}
+
+// ParseCSV reads price rows with a header line into create requests. Errors
+// from all rows are joined so a bad file can be fixed in one pass.
+func ParseCSV(r io.Reader) ([]CreatePriceRequest, error) {
+    reader := csv.NewReader(r)
+    reader.TrimLeadingSpace = true
+
+    header, err := reader.Read()
+    if err != nil {
+        return nil, fmt.Errorf("reading csv header: %w", err)
+    }
+    index := make(map[string]int, len(header))
+    for i, name := range header {
+        index[strings.ToLower(strings.TrimSpace(name))] = i
+    }
+    for _, col := range requiredCSVColumns {
+        if _, ok := index[col]; !ok {
+            return nil, fmt.Errorf("csv header is missing column %q", col)
+        }
+    }
+
+    var reqs []CreatePriceRequest
+    var errs []error
+    for line := 2; ; line++ {
+        record, err := reader.Read()
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            errs = append(errs, fmt.Errorf("line %d: %w", line, err))
+            continue
+        }
+
+        req, err := parseRecord(record, index)
+        if err != nil {
+            errs = append(errs, fmt.Errorf("line %d: %w", line, err))
+            continue
+        }
+        reqs = append(reqs, req)
+    }
+
+    if len(errs) > 0 {
+        return nil, errors.Join(errs...)
+    }
+    if len(reqs) == 0 {
+        return nil, fmt.Errorf("csv contains no prices")
+    }
+    return reqs, nil
+}
+
+func parseRecord(record []string, index map[string]int) (CreatePriceRequest, error) {
+    field := func(name string) string {
+        i, ok := index[name]
+        if !ok {
+            return ""
+        }
+        return strings.TrimSpace(record[i])
+    }
+
+    var req CreatePriceRequest
+    date, err := time.Parse("2006-01-02", field("date"))
+    if err != nil {
+        return req, fmt.Errorf("invalid date %q", field("date"))
+    }
+    komoditasID, err := strconv.ParseUint(field("komoditas_id"), 10, 64)
+    if err != nil || komoditasID == 0 {
+        return req, fmt.Errorf("invalid komoditas_id %q", field("komoditas_id"))
+    }
+    value, err := money.Parse(field("value"))
+    if err != nil {
+        return req, err
+    }
+
+    req = CreatePriceRequest{
+        KomoditasID: uint(komoditasID),
+        Value:       value,
+        Unit:        field("unit"),
+        Currency:    field("currency"),
+        Date:        date,
+        Market:      field("market"),
+    }
+    if raw := field("market_id"); raw != "" {
+        id, err := strconv.ParseUint(raw, 10, 64)
+        if err != nil || id == 0 {
+            return req, fmt.Errorf("invalid market_id %q", raw)
+        }
+        marketID := uint(id)
+        req.MarketID = &marketID
+    }
+    return req, nil
+}
+
+// WriteCSV writes prices as they were reported, so the file can be fed back
+// through ParseCSV. Prices stored before units existed are written in the
+// komoditas base unit.
+func WriteCSV(w io.Writer, prices []Price) error {
+    writer := csv.NewWriter(w)
+    if err := writer.Write(CSVColumns); err != nil {
+        return err
+    }
+    for _, p := range prices {
+        value, unitCode := p.ReportedValue, p.Unit
+        if value == 0 {
+            value, unitCode = p.Value, ""
+        }
+        marketID := ""
+        if p.MarketID != nil {
+            marketID = strconv.FormatUint(uint64(*p.MarketID), 10)
+        }
+        record := []string{
+            p.Date.Format("2006-01-02"),
+            strconv.FormatUint(uint64(p.KomoditasID), 10),
+            value.String(),
+            unitCode,
+            p.Currency,
+            marketID,
+            p.Market,
+        }
+        if err := writer.Write(record); err != nil {
+            return err
+        }
+    }
+    writer.Flush()
+    return writer.Error()
+}
@@ -0,0 +1,91 @@
+package price
+
+import (
+    "bytes"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/ryuzxy/FuncPro/pkg/money"
+)
+
+func TestParseCSV(t *testing.T) {
+    in := "Date, komoditas_id, value, unit, market_id\n" +
+        "2024-03-01, 1, 12500.50, kg, 4\n" +
+        "2024-03-02, 2, 3000, , \n"
+    reqs, err := ParseCSV(strings.NewReader(in))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(reqs) != 2 {
+        t.Fatalf("got %d requests, want 2", len(reqs))
+    }
+    first := reqs[0]
+    if first.KomoditasID != 1 || first.Value != money.MustParse("12500.5") || first.Unit != "kg" ||
+        first.MarketID == nil || *first.MarketID != 4 || !first.Date.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+        t.Errorf("first row = %+v", first)
+    }
+    if reqs[1].MarketID != nil || reqs[1].Unit != "" {
+        t.Errorf("second row = %+v, want no market and no unit", reqs[1])
+    }
+}
+
+func TestParseCSVErrors(t *testing.T) {
+    tests := []struct {
+        name string
+        in   string
+        want []string
+    }{
+        {"missing column", "date,value\n2024-01-01,1\n", []string{`"komoditas_id"`}},
+        {"no rows", "date,komoditas_id,value\n", []string{"no prices"}},
+        {"every bad row", "date,komoditas_id,value,market_id\n" +
+            "01/02/2024,1,10,\n" +
+            "2024-01-02,x,10,\n" +
+            "2024-01-03,1,ten,\n" +
+            "2024-01-04,1,10,0\n",
+            []string{"line 2", "line 3", "line 4", "line 5"}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            _, err := ParseCSV(strings.NewReader(tt.in))
+            if err == nil {
+                t.Fatal("no error")
+            }
+            for _, s := range tt.want {
+                if !strings.Contains(err.Error(), s) {
+                    t.Errorf("error %q lacks %q", err, s)
+                }
+            }
+        })
+    }
+}
+
+func TestWriteCSVRoundTrip(t *testing.T) {
+    market := uint(9)
+    prices := []Price{
+        {KomoditasID: 1, Value: money.MustParse("10000"), ReportedValue: money.MustParse("1000"), Unit: "ons",
+            Currency: "IDR", Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), MarketID: &market, Market: "Pasar Baru"},
+        // Stored before units existed: written in the base unit.
+        {KomoditasID: 2, Value: money.MustParse("2.5"), Currency: "USD", Date: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)},
+    }
+    var buf bytes.Buffer
+    if err := WriteCSV(&buf, prices); err != nil {
+        t.Fatal(err)
+    }
+
+    reqs, err := ParseCSV(&buf)
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := []CreatePriceRequest{
+        {KomoditasID: 1, Value: money.MustParse("1000"), Unit: "ons", Currency: "IDR", Date: prices[0].Date, MarketID: &market, Market: "Pasar Baru"},
+        {KomoditasID: 2, Value: money.MustParse("2.5"), Currency: "USD", Date: prices[1].Date},
+    }
+    for i, w := range want {
+        got := reqs[i]
+        if got.KomoditasID != w.KomoditasID || got.Value != w.Value || got.Unit != w.Unit || got.Currency != w.Currency ||
+            !got.Date.Equal(w.Date) || got.Market != w.Market || (got.MarketID == nil) != (w.MarketID == nil) {
+            t.Errorf("row %d = %+v, want %+v", i, got, w)
+        }
+    }
+}
@@ -0,0 +1,177 @@
+package price
+
+import (
+    "fmt"
+    "sort"
+    "strconv"
+    "time"
+
+    "github.com/ryuzxy/FuncPro/pkg/money"
+)
+
+// DisparityLevel is the regional granularity prices are compared at.
+type DisparityLevel string
+
+const (
+    LevelMarket   DisparityLevel = "market"
+    LevelProvince DisparityLevel = "province"
+)
+
+func ParseDisparityLevel(s string) (DisparityLevel, error) {
+    switch DisparityLevel(s) {
+    case "":
+        return LevelMarket, nil
+    case LevelMarket, LevelProvince:
+        return DisparityLevel(s), nil
+    }
+    return "", fmt.Errorf("invalid level %q", s)
+}
+
+// MarketPrice is a price joined with the market and region it was reported in.
+type MarketPrice struct {
+    Date       time.Time
+    Value      money.Money
+    MarketID   uint
+    MarketCode string
+    MarketName string
+    Province   string
+    Regency    string
+}
+
+type DisparityOptions struct {
+    Level    DisparityLevel
+    Interval Interval
+    Start    time.Time
+    End      time.Time
+    Values   ValueOptions
+}
+
+type DisparityGroup struct {
+    MarketID uint
+    Code     string
+    Name     string
+    Province string
+    Mean     money.Money
+    Count    int
+}
+
+type DisparityPoint struct {
+    Period time.Time
+    Groups int
+    Spread money.Money
+    CV     float64
+}
+
+type Disparity struct {
+    Level         DisparityLevel
+    Start         time.Time
+    End           time.Time
+    Mean          money.Money
+    Min           money.Money
+    Max           money.Money
+    Spread        money.Money
+    CV            float64
+    Cheapest      *DisparityGroup
+    MostExpensive *DisparityGroup
+    Groups        []DisparityGroup
+    Index         []DisparityPoint
+}
+
+func groupKey(p MarketPrice, level DisparityLevel) string {
+    if level == LevelProvince {
+        return p.Province
+    }
+    return strconv.FormatUint(uint64(p.MarketID), 10)
+}
+
+// groupMeans averages prices per market or province, ordered cheapest first.
+func groupMeans(prices []MarketPrice, level DisparityLevel) []DisparityGroup {
+    groups := make(map[string]*DisparityGroup)
+    sums := make(map[string]money.Money)
+    for _, p := range prices {
+        key := groupKey(p, level)
+        g, ok := groups[key]
+        if !ok {
+            g = &DisparityGroup{Province: p.Province}
+            if level == LevelProvince {
+                g.Name = p.Province
+            } else {
+                g.MarketID = p.MarketID
+                g.Code = p.MarketCode
+                g.Name = p.MarketName
+            }
+            groups[key] = g
+        }
+        sums[key] = sums[key].Add(p.Value)
+        g.Count++
+    }
+
+    out := make([]DisparityGroup, 0, len(groups))
+    for key, g := range groups {
+        g.Mean = sums[key].Div(int64(g.Count))
+        out = append(out, *g)
+    }
+    sort.Slice(out, func(i, j int) bool {
+        if out[i].Mean != out[j].Mean {
+            return out[i].Mean < out[j].Mean
+        }
+        return out[i].Name < out[j].Name
+    })
+    return out
+}
+
+func spreadAndCV(groups []DisparityGroup) (mean, min, max, spread money.Money, cv float64) {
+    if len(groups) == 0 {
+        return 0, 0, 0, 0, 0
+    }
+    means := make([]money.Money, len(groups))
+    for i, g := range groups {
+        means[i] = g.Mean
+    }
+    mean = money.Mean(means)
+    min, max = groups[0].Mean, groups[len(groups)-1].Mean
+    floats := moneyFloats(means)
+    if avg := AveragePrice(floats); avg != 0 {
+        cv = StdDev(floats) / avg
+    }
+    return mean, min, max, max.Sub(min), cv
+}
+
+// analyzeDisparity compares one komoditas across regions. The headline
+// figures use the mean per group over the whole window, and the index
+// repeats the coefficient of variation for every interval bucket.
+func analyzeDisparity(prices []MarketPrice, opts DisparityOptions) Disparity {
+    d := Disparity{Level: opts.Level, Start: opts.Start, End: opts.End}
+
+    d.Groups = groupMeans(prices, opts.Level)
+    d.Mean, d.Min, d.Max, d.Spread, d.CV = spreadAndCV(d.Groups)
+
+    markets := d.Groups
+    if opts.Level != LevelMarket {
+        markets = groupMeans(prices, LevelMarket)
+    }
+    if len(markets) > 0 {
+        cheapest, priciest := markets[0], markets[len(markets)-1]
+        d.Cheapest, d.MostExpensive = &cheapest, &priciest
+    }
+
+    buckets := make(map[time.Time][]MarketPrice)
+    for _, p := range prices {
+        period := opts.Interval.Truncate(p.Date)
+        buckets[period] = append(buckets[period], p)
+    }
+    d.Index = make([]DisparityPoint, 0, len(buckets))
+    for period, bucket := range buckets {
+        groups := groupMeans(bucket, opts.Level)
+        _, _, _, spread, cv := spreadAndCV(groups)
+        d.Index = append(d.Index, DisparityPoint{
+            Period: period,
+            Groups: len(groups),
+            Spread: spread,
+            CV:     cv,
+        })
+    }
+    sort.Slice(d.Index, func(i, j int) bool { return d.Index[i].Period.Before(d.Index[j].Period) })
+
+    return d
+}
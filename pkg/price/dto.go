@@ -1,40 +1,216 @@
 package price
 
-import "time"
+import (
+    "fmt"
+    "math"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/ryuzxy/FuncPro/pkg/money"
+)
 
 type CreatePriceRequest struct {
-    KomoditasID uint      `json:"komoditas_id" binding:"required"`
-    Value       float64   `json:"value" binding:"required,gt=0"`
-    Date        time.Time `json:"date" binding:"required"`
-    Market      string    `json:"market" binding:"max=100"`
+    KomoditasID uint        `json:"komoditas_id" binding:"required"`
+    Value       money.Money `json:"value" binding:"required,gt=0"`
+    Unit        string      `json:"unit" binding:"omitempty,max=20"`
+    Currency    string      `json:"currency" binding:"omitempty,len=3"`
+    Date        time.Time   `json:"date" binding:"required"`
+    MarketID    *uint       `json:"market_id"`
+    Market      string      `json:"market" binding:"max=100"`
+}
+
+// ValueQuery holds the read options shared by every price listing and
+// analysis endpoint.
+type ValueQuery struct {
+    Currency string    `form:"currency" binding:"omitempty,len=3"`
+    Adjust   string    `form:"adjust" binding:"omitempty,oneof=nominal real"`
+    Base     time.Time `form:"base" time_format:"2006-01"`
+}
+
+type ListPricesQuery struct {
+    ValueQuery
+}
+
+type AggregateQuery struct {
+    ValueQuery
+    Interval string    `form:"interval" binding:"omitempty,oneof=day week month quarter"`
+    GroupBy  string    `form:"group_by" binding:"omitempty,oneof=market"`
+    MarketID *uint     `form:"market_id"`
+    From     time.Time `form:"from" time_format:"2006-01-02"`
+    To       time.Time `form:"to" time_format:"2006-01-02"`
+}
+
+type SeriesQuery struct {
+    ValueQuery
+    Fill   string    `form:"fill" binding:"omitempty,oneof=none ffill linear seasonal"`
+    Season int       `form:"season" binding:"omitempty,min=1,max=366"`
+    From   time.Time `form:"from" time_format:"2006-01-02"`
+    To     time.Time `form:"to" time_format:"2006-01-02"`
+}
+
+type AnalysisQuery struct {
+    ValueQuery
+    Fill string `form:"fill" binding:"omitempty,oneof=none ffill linear seasonal"`
+}
+
+type DisparityQuery struct {
+    ValueQuery
+    Level    string    `form:"level" binding:"omitempty,oneof=market province"`
+    Interval string    `form:"interval" binding:"omitempty,oneof=day week month quarter"`
+    Date     time.Time `form:"date" time_format:"2006-01-02"`
+    From     time.Time `form:"from" time_format:"2006-01-02"`
+    To       time.Time `form:"to" time_format:"2006-01-02"`
+}
+
+type CorrelationQuery struct {
+    ValueQuery
+    IDs    string    `form:"ids" binding:"required"`
+    MaxLag int       `form:"max_lag" binding:"omitempty,min=0"`
+    From   time.Time `form:"from" time_format:"2006-01-02"`
+    To     time.Time `form:"to" time_format:"2006-01-02"`
+}
+
+type SeasonalityQuery struct {
+    ValueQuery
+    Season  string    `form:"season" binding:"omitempty,oneof=month week"`
+    Model   string    `form:"model" binding:"omitempty,oneof=multiplicative additive"`
+    Horizon int       `form:"horizon" binding:"omitempty,min=0,max=104"`
+    From    time.Time `form:"from" time_format:"2006-01-02"`
+    To      time.Time `form:"to" time_format:"2006-01-02"`
 }
 
 type PriceResponse struct {
-    ID          uint      `json:"id"`
-    KomoditasID uint      `json:"komoditas_id"`
-    Value       float64   `json:"value"`
-    Date        time.Time `json:"date"`
-    Market      string    `json:"market"`
-    CreatedAt   time.Time `json:"created_at"`
+    ID            uint        `json:"id"`
+    KomoditasID   uint        `json:"komoditas_id"`
+    Value         money.Money `json:"value"`
+    ReportedValue money.Money `json:"reported_value"`
+    Unit          string      `json:"unit"`
+    Currency      string      `json:"currency"`
+    Date          time.Time   `json:"date"`
+    MarketID      *uint       `json:"market_id"`
+    Market        string      `json:"market"`
+    CreatedAt     time.Time   `json:"created_at"`
 }
 
 type PriceAnalysisResponse struct {
-    Current    float64 `json:"current"`
-    Previous   float64 `json:"previous"`
-    Change     float64 `json:"change"`
-    ChangePct  float64 `json:"change_percentage"`
-    Trend      string  `json:"trend"`
-    Volatility float64 `json:"volatility"`
+    Current    money.Money `json:"current"`
+    Previous   money.Money `json:"previous"`
+    Change     money.Money `json:"change"`
+    ChangePct  float64     `json:"change_percentage"`
+    Trend      string      `json:"trend"`
+    Volatility float64     `json:"volatility"`
+    Forecast   *money.Money `json:"forecast,omitempty"`
+}
+
+type PriceBucketResponse struct {
+    Period   time.Time   `json:"period"`
+    MarketID *uint       `json:"market_id,omitempty"`
+    Market   string      `json:"market,omitempty"`
+    Open     money.Money `json:"open"`
+    High     money.Money `json:"high"`
+    Low      money.Money `json:"low"`
+    Close    money.Money `json:"close"`
+    Mean     money.Money `json:"mean"`
+    Median   money.Money `json:"median"`
+    Count    int         `json:"count"`
+}
+
+type SeriesPointResponse struct {
+    Date    time.Time    `json:"date"`
+    Value   *money.Money `json:"value"`
+    Imputed bool         `json:"imputed"`
+}
+
+type DisparityGroupResponse struct {
+    MarketID uint        `json:"market_id,omitempty"`
+    Code     string      `json:"code,omitempty"`
+    Name     string      `json:"name"`
+    Province string      `json:"province"`
+    Mean     money.Money `json:"mean"`
+    Count    int         `json:"count"`
+}
+
+type DisparityPointResponse struct {
+    Period time.Time   `json:"period"`
+    Groups int         `json:"groups"`
+    Spread money.Money `json:"spread"`
+    CV     float64     `json:"coefficient_of_variation"`
+}
+
+type DisparityResponse struct {
+    Level         DisparityLevel           `json:"level"`
+    From          time.Time                `json:"from"`
+    To            time.Time                `json:"to"`
+    Mean          money.Money              `json:"mean"`
+    Min           money.Money              `json:"min"`
+    Max           money.Money              `json:"max"`
+    Spread        money.Money              `json:"spread"`
+    CV            float64                  `json:"coefficient_of_variation"`
+    Cheapest      *DisparityGroupResponse  `json:"cheapest_market"`
+    MostExpensive *DisparityGroupResponse  `json:"most_expensive_market"`
+    Groups        []DisparityGroupResponse `json:"groups"`
+    Index         []DisparityPointResponse `json:"disparity_index"`
+}
+
+type LagCorrelationResponse struct {
+    A           uint               `json:"komoditas_a"`
+    B           uint               `json:"komoditas_b"`
+    BestLag     int                `json:"best_lag"`
+    Correlation *float64           `json:"correlation"`
+    ByLag       []LagValueResponse `json:"by_lag"`
+}
+
+type LagValueResponse struct {
+    Lag         int      `json:"lag"`
+    Correlation *float64 `json:"correlation"`
+}
+
+type CorrelationResponse struct {
+    KomoditasIDs []uint                   `json:"komoditas_ids"`
+    From         *time.Time               `json:"from"`
+    To           *time.Time               `json:"to"`
+    CommonDates  int                      `json:"common_dates"`
+    Observations int                      `json:"observations"`
+    Pearson      [][]*float64             `json:"pearson"`
+    Spearman     [][]*float64             `json:"spearman"`
+    Lags         []LagCorrelationResponse `json:"lagged"`
+}
+
+type SeasonalIndexResponse struct {
+    Slot  int     `json:"slot"`
+    Index float64 `json:"index"`
+}
+
+type SeasonalPointResponse struct {
+    Period   time.Time   `json:"period"`
+    Observed money.Money `json:"observed"`
+    Imputed  bool        `json:"imputed"`
+    Trend    *float64    `json:"trend"`
+    Seasonal float64     `json:"seasonal"`
+    Residual *float64    `json:"residual"`
+}
+
+type SeasonalityResponse struct {
+    Season   Season                  `json:"season"`
+    Model    DecompositionModel      `json:"model"`
+    Index    []SeasonalIndexResponse `json:"seasonal_index"`
+    Points   []SeasonalPointResponse `json:"components"`
+    Forecast []SeriesPointResponse   `json:"forecast"`
 }
 
 func ToResponse(p Price) PriceResponse {
     return PriceResponse{
-        ID:          p.ID,
-        KomoditasID: p.KomoditasID,
-        Value:       p.Value,
-        Date:        p.Date,
-        Market:      p.Market,
-        CreatedAt:   p.CreatedAt,
+        ID:            p.ID,
+        KomoditasID:   p.KomoditasID,
+        Value:         p.Value,
+        ReportedValue: p.ReportedValue,
+        Unit:          p.Unit,
+        Currency:      p.Currency,
+        Date:          p.Date,
+        MarketID:      p.MarketID,
+        Market:        p.Market,
+        CreatedAt:     p.CreatedAt,
     }
 }
 
@@ -46,5 +222,292 @@ func ToAnalysisResponse(a PriceAnalysis) PriceAnalysisResponse {
     r.ChangePct = a.ChangePct
     r.Trend = a.Trend
     r.Volatility = a.Volatility
+    r.Forecast = a.Forecast
+    return r
+}
+
+func ToBucketResponse(b PriceBucket) PriceBucketResponse {
+    return PriceBucketResponse{
+        Period:   b.Period,
+        MarketID: b.MarketID,
+        Market:   b.Market,
+        Open:     b.Open,
+        High:     b.High,
+        Low:      b.Low,
+        Close:    b.Close,
+        Mean:     b.Mean,
+        Median:   b.Median,
+        Count:    b.Count,
+    }
+}
+
+func (q ValueQuery) ToValueOptions() (ValueOptions, error) {
+    opts := ValueOptions{Currency: q.Currency, Real: q.Adjust == "real", Base: q.Base}
+    if opts.Real && opts.Base.IsZero() {
+        return ValueOptions{}, fmt.Errorf("base period (YYYY-MM) is required when adjust=real")
+    }
+    if !opts.Real && !opts.Base.IsZero() {
+        return ValueOptions{}, fmt.Errorf("base is only valid with adjust=real")
+    }
+    return opts, nil
+}
+
+func (q AnalysisQuery) ToOptions() (AnalysisOptions, error) {
+    fill, err := ParseFillStrategy(q.Fill)
+    if err != nil {
+        return AnalysisOptions{}, err
+    }
+    values, err := q.ToValueOptions()
+    if err != nil {
+        return AnalysisOptions{}, err
+    }
+    return AnalysisOptions{Fill: fill, Values: values}, nil
+}
+
+func (q AggregateQuery) ToOptions() (AggregateOptions, error) {
+    interval, err := ParseInterval(q.Interval)
+    if err != nil {
+        return AggregateOptions{}, err
+    }
+    values, err := q.ToValueOptions()
+    if err != nil {
+        return AggregateOptions{}, err
+    }
+    return AggregateOptions{
+        Interval: interval,
+        ByMarket: q.GroupBy == "market",
+        MarketID: q.MarketID,
+        Start:    q.From,
+        End:      q.To,
+        Values:   values,
+    }, nil
+}
+
+func ToSeriesPointResponse(p SeriesPoint) SeriesPointResponse {
+    r := SeriesPointResponse{Date: p.Date, Imputed: p.Imputed}
+    if !p.Missing {
+        v := p.Value
+        r.Value = &v
+    }
+    return r
+}
+
+func (q SeriesQuery) ToOptions() (SeriesOptions, error) {
+    fill, err := ParseFillStrategy(q.Fill)
+    if err != nil {
+        return SeriesOptions{}, err
+    }
+    values, err := q.ToValueOptions()
+    if err != nil {
+        return SeriesOptions{}, err
+    }
+    return SeriesOptions{
+        Start:  q.From,
+        End:    q.To,
+        Fill:   fill,
+        Season: q.Season,
+        Values: values,
+    }, nil
+}
+
+func ToDisparityGroupResponse(g DisparityGroup) DisparityGroupResponse {
+    return DisparityGroupResponse{
+        MarketID: g.MarketID,
+        Code:     g.Code,
+        Name:     g.Name,
+        Province: g.Province,
+        Mean:     g.Mean,
+        Count:    g.Count,
+    }
+}
+
+func ToDisparityResponse(d Disparity) DisparityResponse {
+    r := DisparityResponse{
+        Level:  d.Level,
+        From:   d.Start,
+        To:     d.End,
+        Mean:   d.Mean,
+        Min:    d.Min,
+        Max:    d.Max,
+        Spread: d.Spread,
+        CV:     d.CV,
+        Groups: make([]DisparityGroupResponse, 0, len(d.Groups)),
+        Index:  make([]DisparityPointResponse, 0, len(d.Index)),
+    }
+    if d.Cheapest != nil {
+        g := ToDisparityGroupResponse(*d.Cheapest)
+        r.Cheapest = &g
+    }
+    if d.MostExpensive != nil {
+        g := ToDisparityGroupResponse(*d.MostExpensive)
+        r.MostExpensive = &g
+    }
+    for _, g := range d.Groups {
+        r.Groups = append(r.Groups, ToDisparityGroupResponse(g))
+    }
+    for _, p := range d.Index {
+        r.Index = append(r.Index, DisparityPointResponse{
+            Period: p.Period,
+            Groups: p.Groups,
+            Spread: p.Spread,
+            CV:     p.CV,
+        })
+    }
+    return r
+}
+
+// ToOptions turns the query into disparity options. A single date takes
+// precedence over a from/to period.
+func (q DisparityQuery) ToOptions() (DisparityOptions, error) {
+    level, err := ParseDisparityLevel(q.Level)
+    if err != nil {
+        return DisparityOptions{}, err
+    }
+    interval, err := ParseInterval(q.Interval)
+    if err != nil {
+        return DisparityOptions{}, err
+    }
+    values, err := q.ToValueOptions()
+    if err != nil {
+        return DisparityOptions{}, err
+    }
+    opts := DisparityOptions{
+        Level:    level,
+        Interval: interval,
+        Start:    q.From,
+        End:      q.To,
+        Values:   values,
+    }
+    if !q.Date.IsZero() {
+        opts.Start, opts.End = q.Date, q.Date
+    }
+    return opts, nil
+}
+
+// nullable maps NaN, which JSON cannot carry, to null.
+func nullable(f float64) *float64 {
+    if math.IsNaN(f) {
+        return nil
+    }
+    return &f
+}
+
+func nullableMatrix(m [][]float64) [][]*float64 {
+    out := make([][]*float64, len(m))
+    for i, row := range m {
+        out[i] = make([]*float64, len(row))
+        for j, v := range row {
+            out[i][j] = nullable(v)
+        }
+    }
+    return out
+}
+
+func ToCorrelationResponse(c Correlation) CorrelationResponse {
+    r := CorrelationResponse{
+        KomoditasIDs: c.IDs,
+        CommonDates:  len(c.Dates),
+        Observations: c.Observations,
+        Pearson:      nullableMatrix(c.Pearson),
+        Spearman:     nullableMatrix(c.Spearman),
+        Lags:         make([]LagCorrelationResponse, 0, len(c.Lags)),
+    }
+    if len(c.Dates) > 0 {
+        from, to := c.Dates[0], c.Dates[len(c.Dates)-1]
+        r.From, r.To = &from, &to
+    }
+    for _, l := range c.Lags {
+        lr := LagCorrelationResponse{
+            A:           l.A,
+            B:           l.B,
+            BestLag:     l.BestLag,
+            Correlation: nullable(l.Best),
+            ByLag:       make([]LagValueResponse, 0, len(l.ByLag)),
+        }
+        for _, v := range l.ByLag {
+            lr.ByLag = append(lr.ByLag, LagValueResponse{Lag: v.Lag, Correlation: nullable(v.Correlation)})
+        }
+        r.Lags = append(r.Lags, lr)
+    }
     return r
 }
+
+// ToOptions parses the comma-separated komoditas ids, keeping their order
+// so the matrices line up with the request.
+func (q CorrelationQuery) ToOptions() (CorrelationOptions, error) {
+    var ids []uint
+    for _, part := range strings.Split(q.IDs, ",") {
+        part = strings.TrimSpace(part)
+        if part == "" {
+            continue
+        }
+        id, err := strconv.ParseUint(part, 10, 32)
+        if err != nil {
+            return CorrelationOptions{}, fmt.Errorf("invalid komoditas id %q", part)
+        }
+        ids = append(ids, uint(id))
+    }
+    values, err := q.ToValueOptions()
+    if err != nil {
+        return CorrelationOptions{}, err
+    }
+    return CorrelationOptions{
+        IDs:    ids,
+        Start:  q.From,
+        End:    q.To,
+        MaxLag: q.MaxLag,
+        Values: values,
+    }, nil
+}
+
+// ToSeasonalityResponse numbers slots from 1, so months read 1-12 and weeks
+// 1-52.
+func ToSeasonalityResponse(s Seasonality) SeasonalityResponse {
+    r := SeasonalityResponse{
+        Season:   s.Season,
+        Model:    s.Model,
+        Index:    make([]SeasonalIndexResponse, 0, len(s.Index)),
+        Points:   make([]SeasonalPointResponse, 0, len(s.Points)),
+        Forecast: make([]SeriesPointResponse, 0, len(s.Forecast)),
+    }
+    for slot, v := range s.Index {
+        r.Index = append(r.Index, SeasonalIndexResponse{Slot: slot + 1, Index: v})
+    }
+    for _, p := range s.Points {
+        r.Points = append(r.Points, SeasonalPointResponse{
+            Period:   p.Period,
+            Observed: p.Observed,
+            Imputed:  p.Imputed,
+            Trend:    nullable(p.Trend),
+            Seasonal: p.Seasonal,
+            Residual: nullable(p.Residual),
+        })
+    }
+    for _, p := range s.Forecast {
+        r.Forecast = append(r.Forecast, ToSeriesPointResponse(p))
+    }
+    return r
+}
+
+func (q SeasonalityQuery) ToOptions() (SeasonalityOptions, error) {
+    season, err := ParseSeason(q.Season)
+    if err != nil {
+        return SeasonalityOptions{}, err
+    }
+    model, err := ParseDecompositionModel(q.Model)
+    if err != nil {
+        return SeasonalityOptions{}, err
+    }
+    values, err := q.ToValueOptions()
+    if err != nil {
+        return SeasonalityOptions{}, err
+    }
+    return SeasonalityOptions{
+        Season:  season,
+        Model:   model,
+        Start:   q.From,
+        End:     q.To,
+        Horizon: q.Horizon,
+        Values:  values,
+    }, nil
+}
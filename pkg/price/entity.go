@@ -3,24 +3,55 @@ package price
 import (
     "time"
     "gorm.io/gorm"
+
+    "github.com/ryuzxy/FuncPro/pkg/money"
 )
 
+// Price is a single reported price. Value is normalised to the komoditas
+// base unit while ReportedValue and Unit keep what the source reported.
+// Market is the legacy free-text market name, kept in sync with the
+// referenced market's name for older clients.
 type Price struct {
-    ID          uint           `gorm:"primarykey" json:"id"`
-    KomoditasID uint           `gorm:"not null;index" json:"komoditas_id"`
-    Value       float64        `gorm:"type:decimal(10,2);not null" json:"value"`
-    Date        time.Time      `gorm:"type:date;not null" json:"date"`
-    Market      string         `gorm:"size:100" json:"market"`
-    CreatedAt   time.Time      `json:"created_at"`
-    UpdatedAt   time.Time      `json:"updated_at"`
-    DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+    ID            uint           `gorm:"primarykey" json:"id"`
+    KomoditasID   uint           `gorm:"not null;index" json:"komoditas_id"`
+    Value         money.Money    `gorm:"type:numeric(18,4);not null" json:"value"`
+    ReportedValue money.Money    `gorm:"type:numeric(18,4)" json:"reported_value"`
+    Unit          string         `gorm:"size:20" json:"unit"`
+    Currency      string         `gorm:"size:3;not null;default:IDR" json:"currency"`
+    Date          time.Time      `gorm:"type:date;not null" json:"date"`
+    MarketID      *uint          `gorm:"index" json:"market_id"`
+    Market        string         `gorm:"size:100" json:"market"`
+    CreatedAt     time.Time      `json:"created_at"`
+    UpdatedAt     time.Time      `json:"updated_at"`
+    DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 type PriceAnalysis struct {
-    Current    float64 `json:"current"`
-    Previous   float64 `json:"previous"`
-    Change     float64 `json:"change"`
-    ChangePct  float64 `json:"change_percentage"`
-    Trend      string  `json:"trend"`
-    Volatility float64 `json:"volatility"`
+    Current    money.Money `json:"current"`
+    Previous   money.Money `json:"previous"`
+    Change     money.Money `json:"change"`
+    ChangePct  float64     `json:"change_percentage"`
+    Trend      string      `json:"trend"`
+    Volatility float64     `json:"volatility"`
+    // Forecast is the expected price the day after the latest report. It is
+    // only set for gap-filled analyses, which have a regular daily grid.
+    Forecast   *money.Money `json:"forecast,omitempty"`
+}
+
+type AnalysisOptions struct {
+    Fill   FillStrategy
+    Values ValueOptions
+}
+
+type PriceBucket struct {
+    Period   time.Time   `json:"period"`
+    MarketID *uint       `json:"market_id,omitempty"`
+    Market   string      `json:"market,omitempty"`
+    Open     money.Money `json:"open"`
+    High     money.Money `json:"high"`
+    Low      money.Money `json:"low"`
+    Close    money.Money `json:"close"`
+    Mean     money.Money `json:"mean"`
+    Median   money.Money `json:"median"`
+    Count    int         `json:"count"`
 }
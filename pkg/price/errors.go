@@ -0,0 +1,37 @@
+package price
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+)
+
+// ErrInvalid matches errors caused by the request itself or by data it
+// depends on being absent (too few observations, no exchange rate, no price
+// index), as opposed to storage failures.
+var ErrInvalid = errors.New("invalid request")
+
+type invalidError struct {
+    err error
+}
+
+func (e invalidError) Error() string        { return e.err.Error() }
+func (e invalidError) Unwrap() error        { return e.err }
+func (e invalidError) Is(target error) bool { return target == ErrInvalid }
+
+func invalid(err error) error {
+    return invalidError{err: err}
+}
+
+func invalidf(format string, args ...any) error {
+    return invalid(fmt.Errorf(format, args...))
+}
+
+// statusFor maps a service error to 400 for invalid requests and 500 for
+// everything else.
+func statusFor(err error) int {
+    if errors.Is(err, ErrInvalid) {
+        return http.StatusBadRequest
+    }
+    return http.StatusInternalServerError
+}
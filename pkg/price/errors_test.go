@@ -0,0 +1,32 @@
+package price
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+    "testing"
+)
+
+func TestStatusFor(t *testing.T) {
+    tests := []struct {
+        name string
+        err  error
+        want int
+    }{
+        {"validation", invalidf("from must be before to"), http.StatusBadRequest},
+        {"wrapped validation", fmt.Errorf("komoditas 1: %w", invalidf("no price index for 2024-01")), http.StatusBadRequest},
+        {"decompose", func() error { _, err := Decompose([]float64{1, 2}, []int{0, 1}, 12, ModelAdditive); return err }(), http.StatusBadRequest},
+        {"storage", fmt.Errorf("range query failed: %w", errors.New("connection refused")), http.StatusInternalServerError},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := statusFor(tt.err); got != tt.want {
+                t.Errorf("statusFor(%v) = %d, want %d", tt.err, got, tt.want)
+            }
+        })
+    }
+
+    if msg := invalidf("need at least %d", 3).Error(); msg != "need at least 3" {
+        t.Errorf("invalid error message changed to %q", msg)
+    }
+}
@@ -1,5 +1,11 @@
 package price
 
+import (
+    "math"
+
+    "github.com/ryuzxy/FuncPro/pkg/money"
+)
+
 func AveragePrice(values []float64) float64 {
     if len(values) == 0 {
         return 0
@@ -11,6 +17,30 @@ func AveragePrice(values []float64) float64 {
     return sum / float64(len(values))
 }
 
+// StdDev is the population standard deviation of values.
+func StdDev(values []float64) float64 {
+    if len(values) == 0 {
+        return 0
+    }
+    mean := AveragePrice(values)
+    sum := 0.0
+    for _, v := range values {
+        diff := v - mean
+        sum += diff * diff
+    }
+    return math.Sqrt(sum / float64(len(values)))
+}
+
+// moneyFloats converts prices to floats for statistics such as variance
+// where exact decimal arithmetic has no meaning.
+func moneyFloats(values []money.Money) []float64 {
+    out := make([]float64, len(values))
+    for i, v := range values {
+        out[i] = v.Float64()
+    }
+    return out
+}
+
 func Estimate(method func([]float64) float64, data []float64) float64 {
     return method(data)
 }
@@ -1,6 +1,7 @@
 package price
 
 import (
+    "errors"
     "net/http"
     "strconv"
 
@@ -42,9 +43,21 @@ func (h *Handler) GetPricesByKomoditas(c *gin.Context) {
         return
     }
 
-    prices, err := h.service.GetPricesByKomoditas(c.Request.Context(), uint(id)).Unwrap()
+    var q ListPricesQuery
+    if err := c.ShouldBindQuery(&q); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+        return
+    }
+
+    values, err := q.ToValueOptions()
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+        return
+    }
+
+    prices, err := h.service.GetPricesByKomoditas(c.Request.Context(), uint(id), values).Unwrap()
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+        c.JSON(statusFor(err), gin.H{"success": false, "error": err.Error()})
         return
     }
 
@@ -67,9 +80,21 @@ func (h *Handler) GetPriceAnalysis(c *gin.Context) {
         return
     }
 
-    analysis, err := h.service.GetPriceAnalysis(c.Request.Context(), uint(id)).Unwrap()
+    var q AnalysisQuery
+    if err := c.ShouldBindQuery(&q); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+        return
+    }
+
+    opts, err := q.ToOptions()
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+        return
+    }
+
+    analysis, err := h.service.GetPriceAnalysis(c.Request.Context(), uint(id), opts).Unwrap()
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+        c.JSON(statusFor(err), gin.H{"success": false, "error": err.Error()})
         return
     }
 
@@ -79,6 +104,174 @@ func (h *Handler) GetPriceAnalysis(c *gin.Context) {
     })
 }
 
+func (h *Handler) GetPriceAggregate(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("komoditas_id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid komoditas id"})
+        return
+    }
+
+    var q AggregateQuery
+    if err := c.ShouldBindQuery(&q); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+        return
+    }
+
+    opts, err := q.ToOptions()
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+        return
+    }
+
+    buckets, err := h.service.AggregatePrices(c.Request.Context(), uint(id), opts).Unwrap()
+    if err != nil {
+        c.JSON(statusFor(err), gin.H{"success": false, "error": err.Error()})
+        return
+    }
+
+    resp := make([]PriceBucketResponse, 0, len(buckets))
+    for _, b := range buckets {
+        resp = append(resp, ToBucketResponse(b))
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "success":  true,
+        "interval": opts.Interval,
+        "data":     resp,
+        "count":    len(resp),
+    })
+}
+
+func (h *Handler) GetPriceSeries(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("komoditas_id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid komoditas id"})
+        return
+    }
+
+    var q SeriesQuery
+    if err := c.ShouldBindQuery(&q); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+        return
+    }
+
+    opts, err := q.ToOptions()
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+        return
+    }
+
+    points, err := h.service.GetPriceSeries(c.Request.Context(), uint(id), opts).Unwrap()
+    if err != nil {
+        c.JSON(statusFor(err), gin.H{"success": false, "error": err.Error()})
+        return
+    }
+
+    imputed := 0
+    resp := make([]SeriesPointResponse, 0, len(points))
+    for _, p := range points {
+        if p.Imputed {
+            imputed++
+        }
+        resp = append(resp, ToSeriesPointResponse(p))
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "success": true,
+        "fill":    opts.Fill,
+        "data":    resp,
+        "count":   len(resp),
+        "imputed": imputed,
+    })
+}
+
+func (h *Handler) GetDisparity(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("komoditas_id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid komoditas id"})
+        return
+    }
+
+    var q DisparityQuery
+    if err := c.ShouldBindQuery(&q); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+        return
+    }
+
+    opts, err := q.ToOptions()
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+        return
+    }
+
+    disparity, err := h.service.GetDisparity(c.Request.Context(), uint(id), opts).Unwrap()
+    if err != nil {
+        c.JSON(statusFor(err), gin.H{"success": false, "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "success": true,
+        "data":    ToDisparityResponse(disparity),
+    })
+}
+
+func (h *Handler) GetSeasonality(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("komoditas_id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid komoditas id"})
+        return
+    }
+
+    var q SeasonalityQuery
+    if err := c.ShouldBindQuery(&q); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+        return
+    }
+
+    opts, err := q.ToOptions()
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+        return
+    }
+
+    seasonality, err := h.service.GetSeasonality(c.Request.Context(), uint(id), opts).Unwrap()
+    if err != nil {
+        c.JSON(statusFor(err), gin.H{"success": false, "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "success": true,
+        "data":    ToSeasonalityResponse(seasonality),
+    })
+}
+
+func (h *Handler) GetCorrelation(c *gin.Context) {
+    var q CorrelationQuery
+    if err := c.ShouldBindQuery(&q); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+        return
+    }
+
+    opts, err := q.ToOptions()
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+        return
+    }
+
+    correlation, err := h.service.GetCorrelation(c.Request.Context(), opts).Unwrap()
+    if err != nil {
+        c.JSON(statusFor(err), gin.H{"success": false, "error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "success": true,
+        "data":    ToCorrelationResponse(correlation),
+    })
+}
+
 func (h *Handler) BulkCreatePrices(c *gin.Context) {
     var reqs []CreatePriceRequest
     if err := c.ShouldBindJSON(&reqs); err != nil {
@@ -88,7 +281,11 @@ func (h *Handler) BulkCreatePrices(c *gin.Context) {
 
     prices, err := h.service.BulkCreatePrices(c.Request.Context(), reqs).Unwrap()
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+        status := http.StatusBadRequest
+        if errors.Is(err, ErrTooManyItems) {
+            status = http.StatusRequestEntityTooLarge
+        }
+        c.JSON(status, gin.H{"success": false, "error": err.Error()})
         return
     }
 
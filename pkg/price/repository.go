@@ -15,11 +15,15 @@ type PriceRepository interface {
     GetByKomoditasIDAndDateRange(ctx context.Context, komoditasID uint, start, end time.Time) fx.Result[[]Price]
     GetLatestByKomoditasID(ctx context.Context, komoditasID uint) fx.Result[Price]
     BulkCreate(ctx context.Context, prices []Price) fx.Result[[]Price]
+    Aggregate(ctx context.Context, komoditasID uint, opts AggregateOptions) fx.Result[[]PriceBucket]
+    GetMarketPrices(ctx context.Context, komoditasID uint, start, end time.Time) fx.Result[[]MarketPrice]
     Delete(ctx context.Context, id uint) fx.Result[bool]
+    WithValues(opts ValueOptions) PriceRepository
 }
 
 type priceRepository struct {
-    db *gorm.DB
+    db     *gorm.DB
+    values ValueOptions
 }
 
 func NewPriceRepository(db *gorm.DB) PriceRepository {
@@ -33,12 +37,20 @@ func (r *priceRepository) Create(ctx context.Context, price Price) fx.Result[Pri
     return fx.Ok(price)
 }
 
+// WithValues returns a repository whose reads present values according to
+// opts. Writes are unaffected.
+func (r *priceRepository) WithValues(opts ValueOptions) PriceRepository {
+    return &priceRepository{db: r.db, values: opts}
+}
+
 func (r *priceRepository) GetByKomoditasID(ctx context.Context, komoditasID uint) fx.Result[[]Price] {
+    q, err := r.read(ctx, forKomoditas(komoditasID))
+    if err != nil {
+        return fx.Err[[]Price](err)
+    }
+
     var list []Price
-    err := r.db.WithContext(ctx).
-        Where("komoditas_id = ?", komoditasID).
-        Order("date asc").
-        Find(&list).Error
+    err = q.Order("prices.date asc").Find(&list).Error
 
     if err != nil {
         return fx.Err[[]Price](fmt.Errorf("query failed: %w", err))
@@ -48,11 +60,13 @@ func (r *priceRepository) GetByKomoditasID(ctx context.Context, komoditasID uint
 }
 
 func (r *priceRepository) GetByKomoditasIDAndDateRange(ctx context.Context, komoditasID uint, start, end time.Time) fx.Result[[]Price] {
+    q, err := r.read(ctx, forKomoditas(komoditasID), between(start, end))
+    if err != nil {
+        return fx.Err[[]Price](err)
+    }
+
     var list []Price
-    err := r.db.WithContext(ctx).
-        Where("komoditas_id = ? AND date BETWEEN ? AND ?", komoditasID, start, end).
-        Order("date asc").
-        Find(&list).Error
+    err = q.Order("prices.date asc").Find(&list).Error
 
     if err != nil {
         return fx.Err[[]Price](fmt.Errorf("range query failed: %w", err))
@@ -82,6 +96,62 @@ func (r *priceRepository) BulkCreate(ctx context.Context, prices []Price) fx.Res
     return fx.Ok(prices)
 }
 
+// Aggregate buckets prices with date_trunc so the grouping happens in
+// Postgres instead of pulling every row into Go.
+func (r *priceRepository) Aggregate(ctx context.Context, komoditasID uint, opts AggregateOptions) fx.Result[[]PriceBucket] {
+    cols := "date_trunc(?, prices.date::timestamp) AS period, "
+    group := "period"
+    if opts.ByMarket {
+        cols += "prices.market_id, markets.name AS market, "
+        group = "period, prices.market_id, markets.name"
+    }
+    cols += `(array_agg(prices.value ORDER BY prices.date ASC, prices.id ASC))[1] AS open,
+        max(prices.value) AS high,
+        min(prices.value) AS low,
+        (array_agg(prices.value ORDER BY prices.date DESC, prices.id DESC))[1] AS close,
+        round(avg(prices.value), 4) AS mean,
+        round((percentile_cont(0.5) WITHIN GROUP (ORDER BY prices.value))::numeric, 4) AS median,
+        count(*) AS count`
+
+    q, err := r.read(ctx, forKomoditas(komoditasID), between(opts.Start, opts.End), forMarket(opts.MarketID))
+    if err != nil {
+        return fx.Err[[]PriceBucket](err)
+    }
+    q = q.Select(cols, string(opts.Interval))
+    if opts.ByMarket {
+        q = q.Joins("LEFT JOIN markets ON markets.id = prices.market_id")
+    }
+
+    var buckets []PriceBucket
+    if err := q.Group(group).Order(group).Scan(&buckets).Error; err != nil {
+        return fx.Err[[]PriceBucket](fmt.Errorf("aggregate query failed: %w", err))
+    }
+
+    return fx.Ok(buckets)
+}
+
+func (r *priceRepository) GetMarketPrices(ctx context.Context, komoditasID uint, start, end time.Time) fx.Result[[]MarketPrice] {
+    q, err := r.read(ctx, forKomoditas(komoditasID), between(start, end))
+    if err != nil {
+        return fx.Err[[]MarketPrice](err)
+    }
+
+    var list []MarketPrice
+    err = q.
+        Select(`prices.date, prices.value, prices.market_id,
+            markets.code AS market_code, markets.name AS market_name,
+            markets.province, markets.regency`).
+        Joins("JOIN markets ON markets.id = prices.market_id AND markets.deleted_at IS NULL").
+        Order("prices.date asc").
+        Scan(&list).Error
+
+    if err != nil {
+        return fx.Err[[]MarketPrice](fmt.Errorf("market price query failed: %w", err))
+    }
+
+    return fx.Ok(list)
+}
+
 func (r *priceRepository) Delete(ctx context.Context, id uint) fx.Result[bool] {
     if err := r.db.WithContext(ctx).Delete(&Price{}, id).Error; err != nil {
         return fx.Err[bool](fmt.Errorf("delete failed: %w", err))
@@ -0,0 +1,177 @@
+package price
+
+import (
+    "fmt"
+    "time"
+
+    "github.com/ryuzxy/FuncPro/pkg/money"
+)
+
+// FillStrategy decides how days without any reported price are filled when
+// a series is resampled onto a daily grid.
+type FillStrategy string
+
+const (
+    FillNone     FillStrategy = "none"
+    FillForward  FillStrategy = "ffill"
+    FillLinear   FillStrategy = "linear"
+    FillSeasonal FillStrategy = "seasonal"
+)
+
+const defaultSeason = 7
+
+func ParseFillStrategy(s string) (FillStrategy, error) {
+    switch FillStrategy(s) {
+    case "":
+        return FillNone, nil
+    case FillNone, FillForward, FillLinear, FillSeasonal:
+        return FillStrategy(s), nil
+    }
+    return "", fmt.Errorf("invalid fill strategy %q", s)
+}
+
+type SeriesPoint struct {
+    Date    time.Time
+    Value   money.Money
+    Imputed bool
+    Missing bool
+}
+
+type SeriesOptions struct {
+    Start  time.Time
+    End    time.Time
+    Fill   FillStrategy
+    Season int
+    Values ValueOptions
+}
+
+func truncateDay(t time.Time) time.Time {
+    y, m, d := t.Date()
+    return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// daysBetween counts the calendar days from start's date to end's, each
+// read in its own location. It goes through Unix seconds rather than a
+// Duration, which saturates after 292 years.
+func daysBetween(start, end time.Time) int {
+    return int((truncateDay(end).Unix() - truncateDay(start).Unix()) / (24 * 60 * 60))
+}
+
+// Resample puts prices on a daily grid between start and end. Days with
+// several reports (e.g. from different markets) are averaged, and empty days
+// are filled according to the strategy. Filled points are flagged Imputed;
+// points that could not be filled are flagged Missing.
+func Resample(prices []Price, start, end time.Time, strategy FillStrategy, season int) []SeriesPoint {
+    if len(prices) == 0 && (start.IsZero() || end.IsZero()) {
+        return []SeriesPoint{}
+    }
+    if start.IsZero() {
+        start = prices[0].Date
+    }
+    if end.IsZero() {
+        end = prices[len(prices)-1].Date
+    }
+    start, end = truncateDay(start), truncateDay(end)
+    if end.Before(start) {
+        return []SeriesPoint{}
+    }
+
+    sums := make(map[time.Time]money.Money)
+    counts := make(map[time.Time]int)
+    for _, p := range prices {
+        d := truncateDay(p.Date)
+        sums[d] += p.Value
+        counts[d]++
+    }
+
+    days := daysBetween(start, end) + 1
+    points := make([]SeriesPoint, days)
+    for i := range points {
+        d := start.AddDate(0, 0, i)
+        points[i] = SeriesPoint{Date: d, Missing: true}
+        if n := counts[d]; n > 0 {
+            points[i] = SeriesPoint{Date: d, Value: sums[d].Div(int64(n))}
+        }
+    }
+
+    switch strategy {
+    case FillForward:
+        fillForward(points)
+    case FillLinear:
+        fillLinear(points)
+    case FillSeasonal:
+        if season <= 0 {
+            season = defaultSeason
+        }
+        fillSeasonal(points, season)
+    }
+
+    return points
+}
+
+func fillForward(points []SeriesPoint) {
+    last := -1
+    for i := range points {
+        if !points[i].Missing {
+            last = i
+            continue
+        }
+        if last >= 0 {
+            points[i] = SeriesPoint{Date: points[i].Date, Value: points[last].Value, Imputed: true}
+        }
+    }
+}
+
+// fillLinear interpolates between the nearest observations on either side.
+// Gaps before the first or after the last observation stay missing since
+// there is nothing to interpolate towards.
+func fillLinear(points []SeriesPoint) {
+    prev := -1
+    for i := range points {
+        if points[i].Missing {
+            continue
+        }
+        if prev >= 0 && i-prev > 1 {
+            from, step := points[prev].Value, points[i].Value.Sub(points[prev].Value)
+            span := int64(i - prev)
+            for j := prev + 1; j < i; j++ {
+                // |step * k / span| < |step|, so this cannot overflow.
+                delta, _ := step.MulRat(int64(j-prev), span)
+                value := from.Add(delta)
+                points[j] = SeriesPoint{Date: points[j].Date, Value: value, Imputed: true}
+            }
+        }
+        prev = i
+    }
+}
+
+// fillSeasonal copies the value from one season earlier. Imputed values can
+// feed later gaps, so a long gap repeats the last complete season.
+func fillSeasonal(points []SeriesPoint, season int) {
+    for i := season; i < len(points); i++ {
+        if points[i].Missing && !points[i-season].Missing {
+            points[i] = SeriesPoint{Date: points[i].Date, Value: points[i-season].Value, Imputed: true}
+        }
+    }
+}
+
+// observedThrough drops the points after the last reported (neither imputed
+// nor missing) one.
+func observedThrough(points []SeriesPoint) []SeriesPoint {
+    for i := len(points) - 1; i >= 0; i-- {
+        if !points[i].Imputed && !points[i].Missing {
+            return points[:i+1]
+        }
+    }
+    return points[:0]
+}
+
+func seriesValues(points []SeriesPoint) []money.Money {
+    values := make([]money.Money, 0, len(points))
+    for _, p := range points {
+        if !p.Missing {
+            values = append(values, p.Value)
+        }
+    }
+    return values
+}
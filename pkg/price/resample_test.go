@@ -0,0 +1,103 @@
+package price
+
+import (
+    "testing"
+    "time"
+
+    "github.com/ryuzxy/FuncPro/pkg/money"
+)
+
+func day(d int) time.Time {
+    return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC)
+}
+
+type wantPoint struct {
+    value   string
+    imputed bool
+    missing bool
+}
+
+func TestResample(t *testing.T) {
+    prices := []Price{
+        {Value: money.MustParse("100"), Date: day(2)},
+        {Value: money.MustParse("110"), Date: day(2).Add(9 * time.Hour)},
+        {Value: money.MustParse("120"), Date: day(5)},
+    }
+
+    tests := []struct {
+        name     string
+        strategy FillStrategy
+        season   int
+        want     []wantPoint
+    }{
+        {"none", FillNone, 0, []wantPoint{
+            {missing: true}, {value: "105"}, {missing: true}, {missing: true}, {value: "120"}, {missing: true},
+        }},
+        {"ffill", FillForward, 0, []wantPoint{
+            {missing: true}, {value: "105"}, {"105", true, false}, {"105", true, false}, {value: "120"}, {"120", true, false},
+        }},
+        {"linear", FillLinear, 0, []wantPoint{
+            {missing: true}, {value: "105"}, {"110", true, false}, {"115", true, false}, {value: "120"}, {missing: true},
+        }},
+        {"seasonal", FillSeasonal, 3, []wantPoint{
+            {missing: true}, {value: "105"}, {missing: true}, {missing: true}, {value: "120"}, {missing: true},
+        }},
+        {"seasonal period 2", FillSeasonal, 2, []wantPoint{
+            {missing: true}, {value: "105"}, {missing: true}, {"105", true, false}, {value: "120"}, {"105", true, false},
+        }},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            points := Resample(prices, day(1), day(6), tt.strategy, tt.season)
+            if len(points) != len(tt.want) {
+                t.Fatalf("got %d points, want %d", len(points), len(tt.want))
+            }
+            for i, w := range tt.want {
+                p := points[i]
+                if !p.Date.Equal(day(i + 1)) {
+                    t.Errorf("point %d date = %s", i, p.Date)
+                }
+                if p.Missing != w.missing || p.Imputed != w.imputed {
+                    t.Errorf("point %d missing=%v imputed=%v, want missing=%v imputed=%v", i, p.Missing, p.Imputed, w.missing, w.imputed)
+                }
+                if !w.missing && p.Value != money.MustParse(w.value) {
+                    t.Errorf("point %d value = %s, want %s", i, p.Value, w.value)
+                }
+            }
+        })
+    }
+}
+
+func TestResampleBounds(t *testing.T) {
+    if got := Resample(nil, time.Time{}, day(3), FillForward, 0); len(got) != 0 {
+        t.Errorf("open window without prices gave %d points", len(got))
+    }
+    if got := Resample(nil, day(3), day(1), FillNone, 0); len(got) != 0 {
+        t.Errorf("reversed window gave %d points", len(got))
+    }
+    prices := []Price{{Value: 1, Date: day(3)}, {Value: 2, Date: day(4)}}
+    if got := Resample(prices, time.Time{}, time.Time{}, FillNone, 0); len(got) != 2 {
+        t.Errorf("window from prices gave %d points, want 2", len(got))
+    }
+}
+
+func TestDaysBetween(t *testing.T) {
+    jakarta := time.FixedZone("WIB", 7*60*60)
+    tests := []struct {
+        name       string
+        start, end time.Time
+        want       int
+    }{
+        {"same day", day(1), day(1).Add(23 * time.Hour), 0},
+        {"leap year", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), 366},
+        {"local dates", time.Date(2024, 1, 1, 23, 0, 0, 0, jakarta), time.Date(2024, 1, 2, 1, 0, 0, 0, time.UTC), 1},
+        {"past a Duration", time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(1000, 1, 1, 0, 0, 0, 0, time.UTC), 364877},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := daysBetween(tt.start, tt.end); got != tt.want {
+                t.Errorf("daysBetween() = %d, want %d", got, tt.want)
+            }
+        })
+    }
+}
@@ -0,0 +1,405 @@
+package price
+
+import (
+    "fmt"
+    "math"
+    "time"
+
+    "github.com/ryuzxy/FuncPro/pkg/money"
+)
+
+// Season is the calendar cycle a seasonal index is computed over.
+type Season string
+
+const (
+    SeasonMonth Season = "month"
+    SeasonWeek  Season = "week"
+)
+
+func ParseSeason(s string) (Season, error) {
+    switch Season(s) {
+    case "":
+        return SeasonMonth, nil
+    case SeasonMonth, SeasonWeek:
+        return Season(s), nil
+    }
+    return "", fmt.Errorf("invalid season %q", s)
+}
+
+// Period is the number of observations in one cycle.
+func (s Season) Period() int {
+    if s == SeasonWeek {
+        return 52
+    }
+    return 12
+}
+
+func (s Season) interval() Interval {
+    if s == SeasonWeek {
+        return IntervalWeek
+    }
+    return IntervalMonth
+}
+
+func (s Season) next(t time.Time) time.Time {
+    if s == SeasonWeek {
+        return t.AddDate(0, 0, 7)
+    }
+    return t.AddDate(0, 1, 0)
+}
+
+// weekEpoch is the Monday of ISO week 1 of 2024, where week slot 0 starts.
+var weekEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Slot is the zero-based position of t within the cycle. For months it is
+// the month. For weeks it is the number of weeks since weekEpoch modulo 52,
+// so consecutive weeks always take consecutive slots, which Forecast relies
+// on. The price is that a 52-week cycle is 364 days long and the slots drift
+// against ISO weeks by one week every five or six years. Moving feasts such
+// as Lebaran drift through the slots anyway, so their effect is smeared over
+// neighbouring slots rather than captured exactly.
+func (s Season) Slot(t time.Time) int {
+    if s == SeasonWeek {
+        days := int(truncateDay(t).Sub(weekEpoch).Hours()) / 24
+        weeks := days / 7
+        if days%7 < 0 {
+            weeks--
+        }
+        return ((weeks % 52) + 52) % 52
+    }
+    return int(t.Month()) - 1
+}
+
+// DecompositionModel says whether the seasonal effect scales with the price
+// level (multiplicative) or is a fixed amount (additive).
+type DecompositionModel string
+
+const (
+    ModelMultiplicative DecompositionModel = "multiplicative"
+    ModelAdditive       DecompositionModel = "additive"
+)
+
+func ParseDecompositionModel(s string) (DecompositionModel, error) {
+    switch DecompositionModel(s) {
+    case "":
+        return ModelMultiplicative, nil
+    case ModelMultiplicative, ModelAdditive:
+        return DecompositionModel(s), nil
+    }
+    return "", fmt.Errorf("invalid model %q", s)
+}
+
+// Decomposition splits a series into trend, seasonal and residual parts.
+// Trend and Residual are NaN for the half cycle at either end, where the
+// centred moving average is undefined. Index holds the seasonal factor per
+// slot: ratios averaging 1 for the multiplicative model, offsets averaging 0
+// for the additive one.
+type Decomposition struct {
+    Model    DecompositionModel
+    Period   int
+    Slots    []int
+    Observed []float64
+    Trend    []float64
+    Seasonal []float64
+    Residual []float64
+    Index    []float64
+}
+
+// Decompose runs a classical decomposition. slots gives each observation's
+// position in the cycle; at least two full cycles are needed.
+func Decompose(values []float64, slots []int, period int, model DecompositionModel) (Decomposition, error) {
+    n := len(values)
+    if period < 2 {
+        return Decomposition{}, invalidf("period must be at least 2")
+    }
+    if n < 2*period {
+        return Decomposition{}, invalidf("need at least %d observations for a period of %d, have %d", 2*period, period, n)
+    }
+    if model == ModelMultiplicative {
+        for _, v := range values {
+            if v <= 0 {
+                return Decomposition{}, invalidf("multiplicative decomposition needs positive values")
+            }
+        }
+    }
+
+    d := Decomposition{
+        Model:    model,
+        Period:   period,
+        Slots:    slots,
+        Observed: values,
+        Trend:    centredMovingAverage(values, period),
+        Seasonal: make([]float64, n),
+        Residual: make([]float64, n),
+    }
+
+    sums := make([]float64, period)
+    counts := make([]int, period)
+    for i, v := range values {
+        if math.IsNaN(d.Trend[i]) {
+            continue
+        }
+        sums[slots[i]] += d.detrend(v, d.Trend[i])
+        counts[slots[i]]++
+    }
+
+    d.Index = make([]float64, period)
+    var total float64
+    var filled int
+    for slot := range sums {
+        if counts[slot] == 0 {
+            d.Index[slot] = math.NaN()
+            continue
+        }
+        d.Index[slot] = sums[slot] / float64(counts[slot])
+        total += d.Index[slot]
+        filled++
+    }
+    // Normalise so the seasonal effect cancels out over a full cycle.
+    mean := total / float64(filled)
+    for slot, v := range d.Index {
+        switch {
+        case math.IsNaN(v):
+            d.Index[slot] = d.neutral()
+        case model == ModelMultiplicative:
+            d.Index[slot] = v / mean
+        default:
+            d.Index[slot] = v - mean
+        }
+    }
+
+    for i, v := range values {
+        d.Seasonal[i] = d.Index[slots[i]]
+        if math.IsNaN(d.Trend[i]) {
+            d.Residual[i] = math.NaN()
+            continue
+        }
+        d.Residual[i] = d.detrend(d.detrend(v, d.Trend[i]), d.Seasonal[i])
+    }
+
+    return d, nil
+}
+
+func (d Decomposition) neutral() float64 {
+    if d.Model == ModelMultiplicative {
+        return 1
+    }
+    return 0
+}
+
+func (d Decomposition) detrend(v, by float64) float64 {
+    if d.Model == ModelMultiplicative {
+        return v / by
+    }
+    return v - by
+}
+
+func (d Decomposition) combine(trend, seasonal float64) float64 {
+    if d.Model == ModelMultiplicative {
+        return trend * seasonal
+    }
+    return trend + seasonal
+}
+
+// Forecast projects h steps past the end of the series: a straight line
+// fitted to the seasonally adjusted values, with the seasonal index of each
+// future slot put back on top.
+func (d Decomposition) Forecast(h int) []float64 {
+    n := len(d.Observed)
+    if h <= 0 || n == 0 {
+        return []float64{}
+    }
+
+    adjusted := make([]float64, n)
+    for i, v := range d.Observed {
+        adjusted[i] = d.detrend(v, d.Seasonal[i])
+    }
+    intercept, slope := linearFit(adjusted)
+
+    out := make([]float64, h)
+    last := d.Slots[n-1]
+    for k := 1; k <= h; k++ {
+        trend := intercept + slope*float64(n-1+k)
+        out[k-1] = d.combine(trend, d.Index[(last+k)%d.Period])
+    }
+    return out
+}
+
+// centredMovingAverage is the classical trend estimate: a plain moving
+// average for odd periods and a 2xperiod average for even ones, so it stays
+// centred on each observation.
+func centredMovingAverage(values []float64, period int) []float64 {
+    n := len(values)
+    out := make([]float64, n)
+    half := period / 2
+    for i := range out {
+        out[i] = math.NaN()
+        if i < half || i+half >= n {
+            continue
+        }
+        if period%2 == 1 {
+            sum := 0.0
+            for j := i - half; j <= i+half; j++ {
+                sum += values[j]
+            }
+            out[i] = sum / float64(period)
+            continue
+        }
+        sum := (values[i-half] + values[i+half]) / 2
+        for j := i - half + 1; j < i+half; j++ {
+            sum += values[j]
+        }
+        out[i] = sum / float64(period)
+    }
+    return out
+}
+
+// linearFit is an ordinary least squares line through values against their
+// index.
+func linearFit(values []float64) (intercept, slope float64) {
+    n := float64(len(values))
+    if n < 2 {
+        return AveragePrice(values), 0
+    }
+    mx := (n - 1) / 2
+    my := AveragePrice(values)
+    var sxy, sxx float64
+    for i, v := range values {
+        dx := float64(i) - mx
+        sxy += dx * (v - my)
+        sxx += dx * dx
+    }
+    slope = sxy / sxx
+    return my - slope*mx, slope
+}
+
+// SeasonalForecast returns an estimator for Estimate that predicts the next
+// value of a series with the given cycle length, treating the first value as
+// slot 0. Series too short to decompose fall back to the last value.
+func SeasonalForecast(period int) func([]float64) float64 {
+    return func(values []float64) float64 {
+        slots := make([]int, len(values))
+        for i := range slots {
+            slots[i] = i % period
+        }
+        d, err := Decompose(values, slots, period, ModelMultiplicative)
+        if err != nil {
+            if len(values) == 0 {
+                return 0
+            }
+            return values[len(values)-1]
+        }
+        return d.Forecast(1)[0]
+    }
+}
+
+// forecastNext estimates the day after a daily grid with a weekly
+// SeasonalForecast. Leading missing days are skipped; a gap inside the grid
+// would shift every later slot, so there is no forecast then.
+func forecastNext(points []SeriesPoint) (money.Money, bool) {
+    start := 0
+    for start < len(points) && points[start].Missing {
+        start++
+    }
+    if start == len(points) {
+        return 0, false
+    }
+    values := make([]float64, 0, len(points)-start)
+    for _, p := range points[start:] {
+        if p.Missing {
+            return 0, false
+        }
+        values = append(values, p.Value.Float64())
+    }
+    return money.FromFloat(Estimate(SeasonalForecast(defaultSeason), values)), true
+}
+
+type SeasonalityOptions struct {
+    Season  Season
+    Model   DecompositionModel
+    Start   time.Time
+    End     time.Time
+    Horizon int
+    Values  ValueOptions
+}
+
+// SeasonalPoint is one period of a decomposed series. Imputed marks periods
+// without reports that were interpolated to keep the grid regular.
+type SeasonalPoint struct {
+    Period   time.Time
+    Observed money.Money
+    Imputed  bool
+    Trend    float64
+    Seasonal float64
+    Residual float64
+}
+
+type Seasonality struct {
+    Season   Season
+    Model    DecompositionModel
+    Index    []float64
+    Points   []SeasonalPoint
+    Forecast []SeriesPoint
+}
+
+// seasonalGrid lays bucket means on a regular month or week grid and
+// interpolates the gaps.
+func seasonalGrid(buckets []PriceBucket, season Season) []SeriesPoint {
+    if len(buckets) == 0 {
+        return []SeriesPoint{}
+    }
+    means := make(map[time.Time]money.Money, len(buckets))
+    for _, b := range buckets {
+        means[truncateDay(b.Period)] = b.Mean
+    }
+
+    var points []SeriesPoint
+    end := truncateDay(buckets[len(buckets)-1].Period)
+    for t := truncateDay(buckets[0].Period); !t.After(end); t = season.next(t) {
+        mean, ok := means[t]
+        points = append(points, SeriesPoint{Date: t, Value: mean, Missing: !ok})
+    }
+    fillLinear(points)
+    return points
+}
+
+func analyzeSeasonality(buckets []PriceBucket, opts SeasonalityOptions) (Seasonality, error) {
+    grid := seasonalGrid(buckets, opts.Season)
+
+    values := make([]float64, len(grid))
+    slots := make([]int, len(grid))
+    for i, p := range grid {
+        values[i] = p.Value.Float64()
+        slots[i] = opts.Season.Slot(p.Date)
+    }
+
+    d, err := Decompose(values, slots, opts.Season.Period(), opts.Model)
+    if err != nil {
+        return Seasonality{}, err
+    }
+
+    s := Seasonality{
+        Season:   opts.Season,
+        Model:    opts.Model,
+        Index:    d.Index,
+        Points:   make([]SeasonalPoint, len(grid)),
+        Forecast: make([]SeriesPoint, 0, opts.Horizon),
+    }
+    for i, p := range grid {
+        s.Points[i] = SeasonalPoint{
+            Period:   p.Date,
+            Observed: p.Value,
+            Imputed:  p.Imputed,
+            Trend:    d.Trend[i],
+            Seasonal: d.Seasonal[i],
+            Residual: d.Residual[i],
+        }
+    }
+
+    next := grid[len(grid)-1].Date
+    for _, v := range d.Forecast(opts.Horizon) {
+        next = opts.Season.next(next)
+        s.Forecast = append(s.Forecast, SeriesPoint{Date: next, Value: money.FromFloat(v), Imputed: true})
+    }
+    return s, nil
+}
@@ -0,0 +1,171 @@
+package price
+
+import (
+    "errors"
+    "math"
+    "testing"
+    "time"
+
+    "github.com/ryuzxy/FuncPro/pkg/money"
+)
+
+// synthetic builds cycles*period values of a rising trend with the given
+// seasonal index applied.
+func synthetic(index []float64, cycles int, model DecompositionModel) ([]float64, []int) {
+    period := len(index)
+    values := make([]float64, period*cycles)
+    slots := make([]int, len(values))
+    for i := range values {
+        trend := 100 + 2*float64(i)
+        slots[i] = i % period
+        if model == ModelMultiplicative {
+            values[i] = trend * index[slots[i]]
+        } else {
+            values[i] = trend + index[slots[i]]
+        }
+    }
+    return values, slots
+}
+
+func TestDecompose(t *testing.T) {
+    tests := []struct {
+        name  string
+        model DecompositionModel
+        index []float64
+        tol   float64
+    }{
+        {"additive even period", ModelAdditive, []float64{-10, 5, 15, -10}, 1e-9},
+        {"additive odd period", ModelAdditive, []float64{-4, 1, 3}, 1e-9},
+        {"multiplicative", ModelMultiplicative, []float64{0.9, 1.1, 1.2, 0.8}, 0.01},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            values, slots := synthetic(tt.index, 6, tt.model)
+            d, err := Decompose(values, slots, len(tt.index), tt.model)
+            if err != nil {
+                t.Fatal(err)
+            }
+            for slot, want := range tt.index {
+                if math.Abs(d.Index[slot]-want) > tt.tol {
+                    t.Errorf("index[%d] = %.4f, want %.4f", slot, d.Index[slot], want)
+                }
+            }
+            half := len(tt.index) / 2
+            for i := range values {
+                edge := i < half || i+half >= len(values)
+                if edge != math.IsNaN(d.Trend[i]) {
+                    t.Errorf("trend[%d] = %v, edge %v", i, d.Trend[i], edge)
+                }
+            }
+
+            forecast := d.Forecast(len(tt.index))
+            next, _ := synthetic(tt.index, 7, tt.model)
+            for k, got := range forecast {
+                want := next[len(values)+k]
+                if math.Abs(got-want)/want > 0.01 {
+                    t.Errorf("forecast[%d] = %.2f, want %.2f", k, got, want)
+                }
+            }
+        })
+    }
+}
+
+func TestDecomposeErrors(t *testing.T) {
+    values, slots := synthetic([]float64{1, 1, 1, 1}, 2, ModelMultiplicative)
+    tests := []struct {
+        name   string
+        values []float64
+        period int
+        model  DecompositionModel
+    }{
+        {"period too small", values, 1, ModelAdditive},
+        {"too few observations", values[:7], 4, ModelAdditive},
+        {"non-positive multiplicative", append([]float64{0}, values[1:]...), 4, ModelMultiplicative},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            _, err := Decompose(tt.values, slots[:len(tt.values)], tt.period, tt.model)
+            if !errors.Is(err, ErrInvalid) {
+                t.Errorf("err = %v, want an ErrInvalid", err)
+            }
+        })
+    }
+}
+
+func TestSeasonSlotWeeksAreConsecutive(t *testing.T) {
+    monday := time.Date(2019, 12, 30, 0, 0, 0, 0, time.UTC)
+    prev := SeasonWeek.Slot(monday)
+    for w := 1; w < 52*12; w++ {
+        week := monday.AddDate(0, 0, 7*w)
+        slot := SeasonWeek.Slot(week)
+        if slot != (prev+1)%52 {
+            t.Fatalf("%s has slot %d after slot %d", week.Format("2006-01-02"), slot, prev)
+        }
+        // Every day of the week shares the Monday's slot.
+        if got := SeasonWeek.Slot(week.AddDate(0, 0, 6)); got != slot {
+            t.Fatalf("%s has slot %d, its Monday %d", week.AddDate(0, 0, 6).Format("2006-01-02"), got, slot)
+        }
+        prev = slot
+    }
+
+    tests := []struct {
+        date string
+        want int
+    }{
+        {"2024-01-01", 0},
+        {"2024-12-23", 51},
+        {"2024-12-30", 0},
+        {"2026-12-21", 51},
+        {"2026-12-28", 0},
+        {"2023-12-31", 51},
+    }
+    for _, tt := range tests {
+        d, _ := time.Parse("2006-01-02", tt.date)
+        if got := SeasonWeek.Slot(d); got != tt.want {
+            t.Errorf("Slot(%s) = %d, want %d", tt.date, got, tt.want)
+        }
+    }
+    if got := SeasonMonth.Slot(time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)); got != 11 {
+        t.Errorf("December slot = %d, want 11", got)
+    }
+}
+
+func TestSeasonalForecast(t *testing.T) {
+    weekly := []float64{0.9, 1.0, 1.0, 1.0, 1.0, 1.1, 1.0}
+    values, _ := synthetic(weekly, 4, ModelMultiplicative)
+    next, _ := synthetic(weekly, 5, ModelMultiplicative)
+
+    tests := []struct {
+        name   string
+        values []float64
+        want   float64
+        tol    float64
+    }{
+        {"four cycles", values, next[len(values)], 0.01},
+        {"too short falls back to last", values[:5], values[4], 0},
+        {"empty", nil, 0, 0},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got := Estimate(SeasonalForecast(7), tt.values)
+            if math.Abs(got-tt.want) > tt.tol*math.Max(1, tt.want) {
+                t.Errorf("forecast = %.4f, want %.4f", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestForecastNext(t *testing.T) {
+    grid := []SeriesPoint{{Missing: true}, {Value: money.FromInt(100)}, {Value: money.FromInt(110), Imputed: true}}
+    got, ok := forecastNext(grid)
+    if !ok || got != money.FromInt(110) {
+        t.Errorf("forecastNext = %s, %v, want 110 (last value for a short grid)", got, ok)
+    }
+    grid = append(grid, SeriesPoint{Missing: true}, SeriesPoint{Value: money.FromInt(120)})
+    if _, ok := forecastNext(grid); ok {
+        t.Error("forecast made across an interior gap")
+    }
+    if _, ok := forecastNext([]SeriesPoint{{Missing: true}}); ok {
+        t.Error("forecast made without values")
+    }
+}
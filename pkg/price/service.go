@@ -2,26 +2,88 @@ package price
 
 import (
     "context"
+    "errors"
     "fmt"
+    "strings"
     "time"
 
+    "go.opentelemetry.io/otel"
+
+    "github.com/ryuzxy/FuncPro/pkg/currency"
+    "github.com/ryuzxy/FuncPro/pkg/inflation"
     "github.com/ryuzxy/FuncPro/pkg/fx"
+    "github.com/ryuzxy/FuncPro/pkg/komoditas"
+    "github.com/ryuzxy/FuncPro/pkg/market"
+    "github.com/ryuzxy/FuncPro/pkg/money"
+    "github.com/ryuzxy/FuncPro/pkg/unit"
 )
 
+var tracer = otel.Tracer("github.com/ryuzxy/FuncPro/pkg/price")
+
 type Service interface {
     CreatePrice(ctx context.Context, req CreatePriceRequest) fx.Result[Price]
-    GetPricesByKomoditas(ctx context.Context, id uint) fx.Result[[]Price]
-    GetPriceAnalysis(ctx context.Context, id uint) fx.Result[PriceAnalysis]
+    GetPricesByKomoditas(ctx context.Context, id uint, values ValueOptions) fx.Result[[]Price]
+    GetPriceAnalysis(ctx context.Context, id uint, opts AnalysisOptions) fx.Result[PriceAnalysis]
     BulkCreatePrices(ctx context.Context, reqs []CreatePriceRequest) fx.Result[[]Price]
     GetPriceTrends(ctx context.Context, ids []uint) fx.Result[map[uint]PriceAnalysis]
+    AggregatePrices(ctx context.Context, id uint, opts AggregateOptions) fx.Result[[]PriceBucket]
+    GetPriceSeries(ctx context.Context, id uint, opts SeriesOptions) fx.Result[[]SeriesPoint]
+    GetDisparity(ctx context.Context, id uint, opts DisparityOptions) fx.Result[Disparity]
+    GetCorrelation(ctx context.Context, opts CorrelationOptions) fx.Result[Correlation]
+    GetSeasonality(ctx context.Context, id uint, opts SeasonalityOptions) fx.Result[Seasonality]
+    OnCreated(listener CreatedListener)
+    OnBulkRejected(listener RejectedListener)
+}
+
+// CreatedListener is told about prices right after they are stored. It runs
+// on the request path, so it should hand slow work off instead of blocking.
+type CreatedListener func(ctx context.Context, prices []Price)
+
+// RejectedListener is told when a bulk request of rows prices is rejected
+// as a whole, with the reason. Like CreatedListener it runs on the request
+// path.
+type RejectedListener func(ctx context.Context, rows int, err error)
+
+// DefaultWindowDays is the analysis window when Config leaves it unset.
+const DefaultWindowDays = 30
+
+// ErrTooManyItems rejects a bulk request larger than Config.MaxBulkItems.
+var ErrTooManyItems = errors.New("too many prices in one bulk request")
+
+// Config tunes the service. WindowDays is how far back analyses look and
+// the default range of series and disparity queries. MaxBulkItems caps a
+// bulk request and MaxSeriesDays the days one series may span; 0, as the
+// offline commands use, leaves either unbounded.
+type Config struct {
+    WindowDays    int
+    MaxBulkItems  int
+    MaxSeriesDays int
 }
 
 type service struct {
-    repo PriceRepository
+    repo      PriceRepository
+    markets   market.Repository
+    komoditas komoditas.Repository
+    window    int
+    maxBulk   int
+    maxSeries int
+    listeners []CreatedListener
+    rejected  []RejectedListener
 }
 
-func NewService(repo PriceRepository) Service {
-    return &service{repo: repo}
+func NewService(repo PriceRepository, markets market.Repository, komoditas komoditas.Repository, cfg Config) Service {
+    window := cfg.WindowDays
+    if window <= 0 {
+        window = DefaultWindowDays
+    }
+    return &service{
+        repo:      repo,
+        markets:   markets,
+        komoditas: komoditas,
+        window:    window,
+        maxBulk:   cfg.MaxBulkItems,
+        maxSeries: cfg.MaxSeriesDays,
+    }
 }
 
 func validateCreateRequest(req CreatePriceRequest) error {
@@ -40,59 +102,276 @@ func validateCreateRequest(req CreatePriceRequest) error {
     return nil
 }
 
-func requestToPrice(req CreatePriceRequest) Price {
-    return Price{
-        KomoditasID: req.KomoditasID,
-        Value:       req.Value,
-        Date:        req.Date,
-        Market:      req.Market,
+// normalizeValue converts a price reported per `reported` unit into a price
+// per base unit of the komoditas.
+func normalizeValue(k *komoditas.Komoditas, value money.Money, reported string) (money.Money, string, error) {
+    base, err := unit.Lookup(k.BaseUnit)
+    if err != nil {
+        return 0, "", err
+    }
+    if reported == "" {
+        return value, base.Code, nil
     }
+    from, err := unit.Lookup(reported)
+    if err != nil {
+        return 0, "", err
+    }
+
+    var pieceGrams int64
+    if k.PieceWeightGrams != nil {
+        pieceGrams = *k.PieceWeightGrams
+    }
+    num, den, err := unit.PriceFactor(from, base, pieceGrams)
+    if err != nil {
+        return 0, "", fmt.Errorf("komoditas %s: %w", k.Name, err)
+    }
+    normalized, err := value.MulRat(num, den)
+    if err != nil {
+        return 0, "", fmt.Errorf("value %s %s is out of range in %s", value, from.Code, base.Code)
+    }
+    return normalized, from.Code, nil
+}
+
+func requestToPrice(req CreatePriceRequest, k *komoditas.Komoditas, m *market.Market) (Price, error) {
+    value, unitCode, err := normalizeValue(k, req.Value, req.Unit)
+    if err != nil {
+        return Price{}, err
+    }
+
+    code := currency.Default
+    if req.Currency != "" {
+        if code, err = currency.NormalizeCode(req.Currency); err != nil {
+            return Price{}, err
+        }
+    }
+
+    p := Price{
+        KomoditasID:   req.KomoditasID,
+        Value:         value,
+        ReportedValue: req.Value,
+        Unit:          unitCode,
+        Currency:      code,
+        Date:          req.Date,
+    }
+    if m != nil {
+        p.MarketID = &m.ID
+        p.Market = m.Name
+    }
+    return p, nil
+}
+
+// resolveMarket looks up the market a request refers to, either by id or by
+// the legacy free-text name. Unknown names are rejected instead of silently
+// creating yet another spelling of the same market.
+func (s *service) resolveMarket(ctx context.Context, req CreatePriceRequest) (*market.Market, error) {
+    if req.MarketID != nil {
+        m, err := s.markets.GetByID(ctx, *req.MarketID).Unwrap()
+        if err != nil {
+            return nil, fmt.Errorf("market_id %d: %w", *req.MarketID, err)
+        }
+        return m, nil
+    }
+    if strings.TrimSpace(req.Market) == "" {
+        return nil, nil
+    }
+    m, err := s.markets.GetByCode(ctx, market.CodeFromName(req.Market)).Unwrap()
+    if err != nil {
+        return nil, fmt.Errorf("market %q is not registered", req.Market)
+    }
+    return m, nil
 }
 
 func (s *service) CreatePrice(ctx context.Context, req CreatePriceRequest) fx.Result[Price] {
+    ctx, span := tracer.Start(ctx, "price.CreatePrice")
+    defer span.End()
+
     if err := validateCreateRequest(req); err != nil {
         return fx.Err[Price](err)
     }
 
-    p := requestToPrice(req)
-    return s.repo.Create(ctx, p)
+    k, err := s.komoditas.GetByID(ctx, req.KomoditasID).Unwrap()
+    if err != nil {
+        return fx.Err[Price](fmt.Errorf("komoditas_id %d: %w", req.KomoditasID, err))
+    }
+
+    m, err := s.resolveMarket(ctx, req)
+    if err != nil {
+        return fx.Err[Price](err)
+    }
+
+    p, err := requestToPrice(req, k, m)
+    if err != nil {
+        return fx.Err[Price](err)
+    }
+
+    result := s.repo.Create(ctx, p)
+    if created, err := result.Unwrap(); err == nil {
+        s.notify(ctx, []Price{created})
+    }
+    return result
+}
+
+// OnCreated registers a listener for stored prices. Listeners are meant to
+// be registered while wiring the application, before requests are served.
+func (s *service) OnCreated(listener CreatedListener) {
+    s.listeners = append(s.listeners, listener)
+}
+
+// OnBulkRejected registers a listener for rejected bulk requests, under the
+// same rules as OnCreated.
+func (s *service) OnBulkRejected(listener RejectedListener) {
+    s.rejected = append(s.rejected, listener)
+}
+
+func (s *service) notify(ctx context.Context, prices []Price) {
+    for _, listener := range s.listeners {
+        listener(ctx, prices)
+    }
 }
 
-func (s *service) GetPricesByKomoditas(ctx context.Context, id uint) fx.Result[[]Price] {
-    return s.repo.GetByKomoditasID(ctx, id)
+// reader returns the repository to read through for the given value
+// options, rejecting malformed currency codes up front and snapping the
+// real-price base to the start of its month.
+func (s *service) reader(values ValueOptions) (PriceRepository, error) {
+    if values.IsZero() {
+        return s.repo, nil
+    }
+    if values.Currency != "" {
+        code, err := currency.NormalizeCode(values.Currency)
+        if err != nil {
+            return nil, invalid(err)
+        }
+        values.Currency = code
+    }
+    if values.Real {
+        values.Base = inflation.MonthStart(values.Base)
+    }
+    return s.repo.WithValues(values), nil
+}
+
+func (s *service) GetPricesByKomoditas(ctx context.Context, id uint, values ValueOptions) fx.Result[[]Price] {
+    ctx, span := tracer.Start(ctx, "price.GetPricesByKomoditas")
+    defer span.End()
+
+    repo, err := s.reader(values)
+    if err != nil {
+        return fx.Err[[]Price](err)
+    }
+    return repo.GetByKomoditasID(ctx, id)
 }
 
-func (s *service) GetPriceAnalysis(ctx context.Context, id uint) fx.Result[PriceAnalysis] {
+func (s *service) GetPriceAnalysis(ctx context.Context, id uint, opts AnalysisOptions) fx.Result[PriceAnalysis] {
+    ctx, span := tracer.Start(ctx, "price.GetPriceAnalysis")
+    defer span.End()
+
     end := time.Now()
-    start := end.AddDate(0, 0, -30)
+    start := end.AddDate(0, 0, -s.window)
+
+    if opts.Fill != "" && opts.Fill != FillNone {
+        // On a gap-filled daily grid "previous" is always the day before.
+        // The grid is cut at the last real report, otherwise forward-filled
+        // days up to today would compare the latest price with itself.
+        series := SeriesOptions{Start: start, End: end, Fill: opts.Fill, Values: opts.Values}
+        points, err := s.GetPriceSeries(ctx, id, series).Unwrap()
+        if err != nil {
+            return fx.Err[PriceAnalysis](err)
+        }
+        observed := observedThrough(points)
+        analysis := analyzeValues(seriesValues(observed))
+        if forecast, ok := forecastNext(observed); ok {
+            analysis.Forecast = &forecast
+        }
+        return fx.Ok(analysis)
+    }
+
+    repo, err := s.reader(opts.Values)
+    if err != nil {
+        return fx.Err[PriceAnalysis](err)
+    }
 
-    prices, err := s.repo.GetByKomoditasIDAndDateRange(ctx, id, start, end).Unwrap()
+    prices, err := repo.GetByKomoditasIDAndDateRange(ctx, id, start, end).Unwrap()
     if err != nil {
         return fx.Err[PriceAnalysis](err)
     }
 
-    analysis := analyzePrices(prices)
+    // A span of its own separates the computation from the query above.
+    _, analyze := tracer.Start(ctx, "price.AnalyzePrices")
+    analysis := AnalyzePrices(prices)
+    analyze.End()
     return fx.Ok(analysis)
 }
 
 func (s *service) BulkCreatePrices(ctx context.Context, reqs []CreatePriceRequest) fx.Result[[]Price] {
+    ctx, span := tracer.Start(ctx, "price.BulkCreatePrices")
+    defer span.End()
+
+    result := s.bulkCreate(ctx, reqs)
+    if _, err := result.Unwrap(); err != nil {
+        for _, listener := range s.rejected {
+            listener(ctx, len(reqs), err)
+        }
+    }
+    return result
+}
+
+func (s *service) bulkCreate(ctx context.Context, reqs []CreatePriceRequest) fx.Result[[]Price] {
+    if s.maxBulk > 0 && len(reqs) > s.maxBulk {
+        return fx.Err[[]Price](fmt.Errorf("%w: %d, at most %d", ErrTooManyItems, len(reqs), s.maxBulk))
+    }
+
     prices := make([]Price, 0, len(reqs))
+    resolved := make(map[string]*market.Market)
+    commodities := make(map[uint]*komoditas.Komoditas)
 
     for _, req := range reqs {
         if err := validateCreateRequest(req); err != nil {
             return fx.Err[[]Price](err)
         }
-        prices = append(prices, requestToPrice(req))
+
+        k, ok := commodities[req.KomoditasID]
+        if !ok {
+            var err error
+            if k, err = s.komoditas.GetByID(ctx, req.KomoditasID).Unwrap(); err != nil {
+                return fx.Err[[]Price](fmt.Errorf("komoditas_id %d: %w", req.KomoditasID, err))
+            }
+            commodities[req.KomoditasID] = k
+        }
+
+        key := market.CodeFromName(req.Market)
+        if req.MarketID != nil {
+            key = fmt.Sprintf("#%d", *req.MarketID)
+        }
+        m, ok := resolved[key]
+        if !ok {
+            var err error
+            if m, err = s.resolveMarket(ctx, req); err != nil {
+                return fx.Err[[]Price](err)
+            }
+            resolved[key] = m
+        }
+
+        p, err := requestToPrice(req, k, m)
+        if err != nil {
+            return fx.Err[[]Price](err)
+        }
+        prices = append(prices, p)
     }
 
-    return s.repo.BulkCreate(ctx, prices)
+    result := s.repo.BulkCreate(ctx, prices)
+    if created, err := result.Unwrap(); err == nil {
+        s.notify(ctx, created)
+    }
+    return result
 }
 
 func (s *service) GetPriceTrends(ctx context.Context, ids []uint) fx.Result[map[uint]PriceAnalysis] {
+    ctx, span := tracer.Start(ctx, "price.GetPriceTrends")
+    defer span.End()
+
     trends := make(map[uint]PriceAnalysis)
 
     for _, id := range ids {
-        analysis, err := s.GetPriceAnalysis(ctx, id).Unwrap()
+        analysis, err := s.GetPriceAnalysis(ctx, id, AnalysisOptions{}).Unwrap()
         if err != nil {
             return fx.Err[map[uint]PriceAnalysis](err)
         }
@@ -102,35 +381,233 @@ func (s *service) GetPriceTrends(ctx context.Context, ids []uint) fx.Result[map[
     return fx.Ok(trends)
 }
 
-func analyzePrices(prices []Price) PriceAnalysis {
-    if len(prices) == 0 {
-        return PriceAnalysis{}
+func (s *service) AggregatePrices(ctx context.Context, id uint, opts AggregateOptions) fx.Result[[]PriceBucket] {
+    ctx, span := tracer.Start(ctx, "price.AggregatePrices")
+    defer span.End()
+
+    if opts.Interval == "" {
+        opts.Interval = IntervalDay
+    }
+    if !opts.Start.IsZero() && !opts.End.IsZero() && opts.Start.After(opts.End) {
+        return fx.Err[[]PriceBucket](invalidf("from must be before to"))
+    }
+    repo, err := s.reader(opts.Values)
+    if err != nil {
+        return fx.Err[[]PriceBucket](err)
     }
+    return repo.Aggregate(ctx, id, opts)
+}
 
-    current := prices[len(prices)-1].Value
-    previous := current
-    if len(prices) > 1 {
-        previous = prices[len(prices)-2].Value
+func (s *service) GetPriceSeries(ctx context.Context, id uint, opts SeriesOptions) fx.Result[[]SeriesPoint] {
+    ctx, span := tracer.Start(ctx, "price.GetPriceSeries")
+    defer span.End()
+
+    if opts.End.IsZero() {
+        opts.End = time.Now()
+    }
+    if opts.Start.IsZero() {
+        opts.Start = opts.End.AddDate(0, 0, -s.window)
+    }
+    if opts.Start.After(opts.End) {
+        return fx.Err[[]SeriesPoint](invalidf("from must be before to"))
+    }
+    // Every day of the span becomes a point, reported or not.
+    if days := daysBetween(opts.Start, opts.End) + 1; s.maxSeries > 0 && days > s.maxSeries {
+        return fx.Err[[]SeriesPoint](invalidf("series spans %d days, at most %d allowed", days, s.maxSeries))
     }
 
-    change := current - previous
-    changePct := 0.0
-    if previous != 0 {
-        changePct = (change / previous) * 100
+    // Seasonal naive needs one season of history before the window starts.
+    lead := 0
+    if opts.Fill == FillSeasonal {
+        if opts.Season <= 0 {
+            opts.Season = defaultSeason
+        }
+        lead = opts.Season
     }
+    from := opts.Start.AddDate(0, 0, -lead)
 
-    values := make([]float64, len(prices))
+    repo, err := s.reader(opts.Values)
+    if err != nil {
+        return fx.Err[[]SeriesPoint](err)
+    }
+
+    prices, err := repo.GetByKomoditasIDAndDateRange(ctx, id, from, opts.End).Unwrap()
+    if err != nil {
+        return fx.Err[[]SeriesPoint](err)
+    }
+
+    points := Resample(prices, from, opts.End, opts.Fill, opts.Season)
+    if lead > len(points) {
+        lead = len(points)
+    }
+    return fx.Ok(points[lead:])
+}
+
+func (s *service) GetDisparity(ctx context.Context, id uint, opts DisparityOptions) fx.Result[Disparity] {
+    ctx, span := tracer.Start(ctx, "price.GetDisparity")
+    defer span.End()
+
+    if opts.End.IsZero() {
+        opts.End = time.Now()
+    }
+    if opts.Start.IsZero() {
+        opts.Start = opts.End.AddDate(0, 0, -s.window)
+    }
+    if opts.Start.After(opts.End) {
+        return fx.Err[Disparity](invalidf("from must be before to"))
+    }
+    if opts.Level == "" {
+        opts.Level = LevelMarket
+    }
+    if opts.Interval == "" {
+        opts.Interval = IntervalDay
+    }
+
+    repo, err := s.reader(opts.Values)
+    if err != nil {
+        return fx.Err[Disparity](err)
+    }
+
+    prices, err := repo.GetMarketPrices(ctx, id, opts.Start, opts.End).Unwrap()
+    if err != nil {
+        return fx.Err[Disparity](err)
+    }
+
+    return fx.Ok(analyzeDisparity(prices, opts))
+}
+
+// GetSeasonality decomposes the monthly or weekly mean price. Without a
+// window it uses the komoditas' whole history, since every extra cycle
+// sharpens the seasonal index.
+func (s *service) GetSeasonality(ctx context.Context, id uint, opts SeasonalityOptions) fx.Result[Seasonality] {
+    ctx, span := tracer.Start(ctx, "price.GetSeasonality")
+    defer span.End()
+
+    if opts.Season == "" {
+        opts.Season = SeasonMonth
+    }
+    if opts.Model == "" {
+        opts.Model = ModelMultiplicative
+    }
+    if !opts.Start.IsZero() && !opts.End.IsZero() && opts.Start.After(opts.End) {
+        return fx.Err[Seasonality](invalidf("from must be before to"))
+    }
+
+    buckets, err := s.AggregatePrices(ctx, id, AggregateOptions{
+        Interval: opts.Season.interval(),
+        Start:    opts.Start,
+        End:      opts.End,
+        Values:   opts.Values,
+    }).Unwrap()
+    if err != nil {
+        return fx.Err[Seasonality](err)
+    }
+
+    seasonality, err := analyzeSeasonality(buckets, opts)
+    if err != nil {
+        return fx.Err[Seasonality](err)
+    }
+    return fx.Ok(seasonality)
+}
+
+type komoditasPrices struct {
+    id     uint
+    prices []Price
+}
+
+// GetCorrelation fetches every komoditas' prices in parallel and correlates
+// their returns over the dates they share. The window defaults to the last
+// year.
+func (s *service) GetCorrelation(ctx context.Context, opts CorrelationOptions) fx.Result[Correlation] {
+    ctx, span := tracer.Start(ctx, "price.GetCorrelation")
+    defer span.End()
+
+    if len(opts.IDs) < 2 {
+        return fx.Err[Correlation](invalidf("at least 2 komoditas ids are required"))
+    }
+    if len(opts.IDs) > maxCorrelationIDs {
+        return fx.Err[Correlation](invalidf("at most %d komoditas ids are allowed", maxCorrelationIDs))
+    }
+    seen := make(map[uint]bool, len(opts.IDs))
+    for _, id := range opts.IDs {
+        if seen[id] {
+            return fx.Err[Correlation](invalidf("komoditas %d is listed more than once", id))
+        }
+        seen[id] = true
+    }
+    if opts.MaxLag < 0 || opts.MaxLag > maxCorrelationLag {
+        return fx.Err[Correlation](invalidf("max_lag must be between 0 and %d", maxCorrelationLag))
+    }
+    if opts.End.IsZero() {
+        opts.End = time.Now()
+    }
+    if opts.Start.IsZero() {
+        opts.Start = opts.End.AddDate(-1, 0, 0)
+    }
+    if opts.Start.After(opts.End) {
+        return fx.Err[Correlation](invalidf("from must be before to"))
+    }
+
+    repo, err := s.reader(opts.Values)
+    if err != nil {
+        return fx.Err[Correlation](err)
+    }
+
+    fetched, err := fx.ParallelMap(ctx, opts.IDs, func(ctx context.Context, id uint) fx.FxResult[komoditasPrices] {
+        prices, err := repo.GetByKomoditasIDAndDateRange(ctx, id, opts.Start, opts.End).Unwrap()
+        if err != nil {
+            return fx.FxErr[komoditasPrices](fmt.Errorf("komoditas %d: %w", id, err))
+        }
+        return fx.FxOk(komoditasPrices{id: id, prices: prices})
+    }, correlationWorkers).Unwrap()
+    if err != nil {
+        return fx.Err[Correlation](err)
+    }
+
+    byID := make(map[uint][]Price, len(fetched))
+    for _, f := range fetched {
+        byID[f.id] = f.prices
+    }
+
+    correlation, err := analyzeCorrelation(opts.IDs, byID, opts.MaxLag)
+    if err != nil {
+        return fx.Err[Correlation](err)
+    }
+    return fx.Ok(correlation)
+}
+
+// AnalyzePrices summarises a date-ordered price list: the latest change
+// against the previous report, its trend and the variance.
+func AnalyzePrices(prices []Price) PriceAnalysis {
+    values := make([]money.Money, len(prices))
     for i, p := range prices {
         values[i] = p.Value
     }
+    return analyzeValues(values)
+}
+
+func analyzeValues(values []money.Money) PriceAnalysis {
+    if len(values) == 0 {
+        return PriceAnalysis{}
+    }
+
+    current := values[len(values)-1]
+    previous := current
+    if len(values) > 1 {
+        previous = values[len(values)-2]
+    }
+
+    change := current.Sub(previous)
+    changePct := change.Ratio(previous) * 100
 
-    mean := AveragePrice(values)
+    floats := moneyFloats(values)
+    mean := AveragePrice(floats)
     variance := 0.0
-    for _, v := range values {
+    for _, v := range floats {
         diff := v - mean
         variance += diff * diff
     }
-    variance /= float64(len(values))
+    variance /= float64(len(floats))
 
     trend := "stable"
     if changePct > 5 {
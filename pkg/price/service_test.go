@@ -0,0 +1,170 @@
+package price
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/ryuzxy/FuncPro/pkg/fx"
+    "github.com/ryuzxy/FuncPro/pkg/money"
+)
+
+// stubRepository serves a fixed price list for range reads; every other
+// method panics through the nil embedded interface.
+type stubRepository struct {
+    PriceRepository
+    prices []Price
+}
+
+func (r stubRepository) GetByKomoditasIDAndDateRange(_ context.Context, _ uint, start, end time.Time) fx.Result[[]Price] {
+    var out []Price
+    for _, p := range r.prices {
+        if !p.Date.Before(truncateDay(start)) && !p.Date.After(end) {
+            out = append(out, p)
+        }
+    }
+    return fx.Ok(out)
+}
+
+func TestGetPriceAnalysisFillComparesLastReports(t *testing.T) {
+    today := truncateDay(time.Now())
+    repo := stubRepository{prices: []Price{
+        {KomoditasID: 1, Value: money.FromInt(100), Date: today.AddDate(0, 0, -10)},
+        {KomoditasID: 1, Value: money.FromInt(150), Date: today.AddDate(0, 0, -6)},
+    }}
+    svc := NewService(repo, nil, nil, Config{})
+
+    for _, fill := range []FillStrategy{FillForward, FillLinear, FillSeasonal} {
+        t.Run(string(fill), func(t *testing.T) {
+            analysis, err := svc.GetPriceAnalysis(context.Background(), 1, AnalysisOptions{Fill: fill}).Unwrap()
+            if err != nil {
+                t.Fatal(err)
+            }
+            if analysis.Current != money.FromInt(150) {
+                t.Errorf("current = %s, want 150", analysis.Current)
+            }
+            if analysis.Trend != "up" || analysis.ChangePct <= 0 {
+                t.Errorf("trend = %s (%.2f%%), want up", analysis.Trend, analysis.ChangePct)
+            }
+        })
+    }
+
+    analysis, err := svc.GetPriceAnalysis(context.Background(), 1, AnalysisOptions{Fill: FillForward}).Unwrap()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if analysis.Previous != money.FromInt(100) || analysis.ChangePct != 50 {
+        t.Errorf("ffill previous = %s change = %.2f%%, want 100 and +50%%", analysis.Previous, analysis.ChangePct)
+    }
+    if analysis.Forecast == nil {
+        t.Error("filled analysis has no forecast")
+    }
+
+    plain, err := svc.GetPriceAnalysis(context.Background(), 1, AnalysisOptions{}).Unwrap()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if plain.Forecast != nil {
+        t.Error("unfilled analysis has a forecast")
+    }
+}
+
+func TestObservedThrough(t *testing.T) {
+    points := []SeriesPoint{
+        {Missing: true},
+        {Value: 1},
+        {Value: 1, Imputed: true},
+        {Value: 2},
+        {Value: 2, Imputed: true},
+        {Missing: true},
+    }
+    if got := observedThrough(points); len(got) != 4 {
+        t.Errorf("len = %d, want 4", len(got))
+    }
+    if got := observedThrough([]SeriesPoint{{Missing: true}}); len(got) != 0 {
+        t.Errorf("len = %d, want 0", len(got))
+    }
+}
+
+func TestGetCorrelationValidation(t *testing.T) {
+    svc := NewService(stubRepository{}, nil, nil, Config{})
+    ids := func(n int) []uint {
+        out := make([]uint, n)
+        for i := range out {
+            out[i] = uint(i + 1)
+        }
+        return out
+    }
+
+    tests := []struct {
+        name string
+        opts CorrelationOptions
+    }{
+        {"one id", CorrelationOptions{IDs: ids(1)}},
+        {"too many ids", CorrelationOptions{IDs: ids(maxCorrelationIDs + 1)}},
+        {"duplicate id", CorrelationOptions{IDs: []uint{1, 2, 1}}},
+        {"lag too long", CorrelationOptions{IDs: ids(2), MaxLag: maxCorrelationLag + 1}},
+        {"no common dates", CorrelationOptions{IDs: ids(maxCorrelationIDs)}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            _, err := svc.GetCorrelation(context.Background(), tt.opts).Unwrap()
+            if statusFor(err) != 400 {
+                t.Errorf("err = %v, want a validation error", err)
+            }
+        })
+    }
+}
+
+func TestBulkCreatePricesReportsRejectedRows(t *testing.T) {
+    svc := NewService(stubRepository{}, nil, nil, Config{})
+    var rows int
+    svc.OnBulkRejected(func(_ context.Context, n int, err error) {
+        rows += n
+    })
+
+    reqs := []CreatePriceRequest{
+        {KomoditasID: 0, Value: money.FromInt(100), Date: time.Now().AddDate(0, 0, -1)},
+        {KomoditasID: 1, Value: money.FromInt(100), Date: time.Now().AddDate(0, 0, -1)},
+    }
+    if svc.BulkCreatePrices(context.Background(), reqs).IsOk() {
+        t.Fatal("accepted a price without komoditas_id")
+    }
+    if rows != 2 {
+        t.Errorf("rejected rows = %d, want the whole batch of 2", rows)
+    }
+}
+
+func TestBulkCreatePricesCapsItems(t *testing.T) {
+    reqs := make([]CreatePriceRequest, 3)
+    capped := NewService(stubRepository{}, nil, nil, Config{MaxBulkItems: 2})
+    if _, err := capped.BulkCreatePrices(context.Background(), reqs).Unwrap(); !errors.Is(err, ErrTooManyItems) {
+        t.Errorf("err = %v, want ErrTooManyItems", err)
+    }
+
+    // Without a cap the same batch gets as far as validating its rows.
+    unbounded := NewService(stubRepository{}, nil, nil, Config{})
+    if _, err := unbounded.BulkCreatePrices(context.Background(), reqs).Unwrap(); err == nil || errors.Is(err, ErrTooManyItems) {
+        t.Errorf("err = %v, want a validation error", err)
+    }
+}
+
+func TestGetPriceSeriesCapsSpan(t *testing.T) {
+    start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+    svc := NewService(stubRepository{}, nil, nil, Config{MaxSeriesDays: 10})
+
+    // Ten calendar days, though the times put them under 9 x 24 hours apart.
+    points, err := svc.GetPriceSeries(context.Background(), 1, SeriesOptions{Start: start, End: start.AddDate(0, 0, 9).Add(-time.Hour)}).Unwrap()
+    if err != nil || len(points) != 10 {
+        t.Fatalf("10 days gave %d points, err %v", len(points), err)
+    }
+    _, err = svc.GetPriceSeries(context.Background(), 1, SeriesOptions{Start: start, End: start.AddDate(0, 0, 10)}).Unwrap()
+    if statusFor(err) != 400 {
+        t.Errorf("11 days: err = %v, want a validation error", err)
+    }
+    _, err = svc.GetPriceSeries(context.Background(), 1, SeriesOptions{Start: time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC), End: start}).Unwrap()
+    if statusFor(err) != 400 {
+        t.Errorf("two thousand years: err = %v, want a validation error", err)
+    }
+}
@@ -0,0 +1,192 @@
+package price
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "gorm.io/gorm"
+)
+
+// ValueOptions controls how stored prices are presented on reads. The zero
+// value returns prices exactly as stored.
+type ValueOptions struct {
+    // Currency converts every price with the exchange rate effective on the
+    // price's date.
+    Currency string
+    // Real deflates prices to constant Base-month prices using the price
+    // index of each price's month. Base must be the start of a month.
+    Real bool
+    Base time.Time
+}
+
+func (o ValueOptions) IsZero() bool {
+    return o.Currency == "" && !o.Real
+}
+
+// rateLookup finds the latest rate for a pair on or before the price date.
+const rateLookup = `(SELECT %s FROM exchange_rates er
+    WHERE er.from_currency = %s AND er.to_currency = %s
+      AND er.date <= prices.date AND er.deleted_at IS NULL
+    ORDER BY er.date DESC LIMIT 1)`
+
+// monthIndex and baseIndex look up the price index of the price's month and
+// of the base month.
+const (
+    monthIndex = `(SELECT pi.value FROM price_indices pi
+    WHERE pi.period = date_trunc('month', prices.date)::date AND pi.deleted_at IS NULL)`
+    baseIndex = `(SELECT pi.value FROM price_indices pi
+    WHERE pi.period = ? AND pi.deleted_at IS NULL)`
+)
+
+// valueSQL is the expression for a presented amount column: converted
+// first, then deflated. It is NULL when a rate or index it needs is unknown.
+func (o ValueOptions) valueSQL(column string) (string, []any) {
+    expr, args := o.currencySQL(column)
+    if o.Real {
+        expr = "round((" + expr + ") * " + baseIndex + " / " + monthIndex + ", 4)"
+        args = append(args, o.Base)
+    }
+    return expr, args
+}
+
+// currencySQL is the expression for a converted amount column. It is NULL
+// when no direct or inverse rate is known for the price's currency and date.
+func (o ValueOptions) currencySQL(column string) (string, []any) {
+    if o.Currency == "" {
+        return column, nil
+    }
+    direct := fmt.Sprintf(rateLookup, "er.rate", "prices.currency", "?")
+    inverse := fmt.Sprintf(rateLookup, "1 / er.rate", "?", "prices.currency")
+    expr := `CASE WHEN prices.currency = ? THEN ` + column + `
+        ELSE round(` + column + ` * COALESCE(` + direct + `, ` + inverse + `), 4) END`
+    return expr, []any{o.Currency, o.Currency, o.Currency}
+}
+
+type scope = func(*gorm.DB) *gorm.DB
+
+func forKomoditas(id uint) scope {
+    return func(db *gorm.DB) *gorm.DB {
+        return db.Where("prices.komoditas_id = ?", id)
+    }
+}
+
+func between(start, end time.Time) scope {
+    return func(db *gorm.DB) *gorm.DB {
+        if !start.IsZero() {
+            db = db.Where("prices.date >= ?", start)
+        }
+        if !end.IsZero() {
+            db = db.Where("prices.date <= ?", end)
+        }
+        return db
+    }
+}
+
+func forMarket(id *uint) scope {
+    return func(db *gorm.DB) *gorm.DB {
+        if id != nil {
+            db = db.Where("prices.market_id = ?", *id)
+        }
+        return db
+    }
+}
+
+// read starts a query over prices as seen through the repository's value
+// options. Converted reads go through a derived table that keeps the
+// "prices" name, so callers can filter and join exactly as on the raw table.
+// Both value and reported_value are converted and deflated, so every amount
+// in a row is in the same currency and the same base-month prices.
+func (r *priceRepository) read(ctx context.Context, scopes ...scope) (*gorm.DB, error) {
+    if r.values.IsZero() {
+        return r.db.WithContext(ctx).Model(&Price{}).Scopes(scopes...), nil
+    }
+    if r.values.Currency != "" {
+        if err := r.checkConvertible(ctx, scopes...); err != nil {
+            return nil, err
+        }
+    }
+    if r.values.Real {
+        if err := r.checkDeflatable(ctx, scopes...); err != nil {
+            return nil, err
+        }
+    }
+
+    value, args := r.values.valueSQL("prices.value")
+    reported, reportedArgs := r.values.valueSQL("prices.reported_value")
+    args = append(args, reportedArgs...)
+    currency := "prices.currency"
+    if r.values.Currency != "" {
+        currency = "?"
+        args = append(args, r.values.Currency)
+    }
+    converted := r.db.Model(&Price{}).Select(
+        `prices.id, prices.komoditas_id, `+value+` AS value, `+reported+` AS reported_value,
+        prices.unit, `+currency+` AS currency, prices.date, prices.market_id, prices.market,
+        prices.created_at, prices.updated_at, prices.deleted_at`,
+        args...,
+    )
+    return r.db.WithContext(ctx).Table("(?) AS prices", converted).Scopes(scopes...), nil
+}
+
+// checkConvertible fails with the first price that has no usable exchange
+// rate, rather than letting it silently drop out of an average.
+func (r *priceRepository) checkConvertible(ctx context.Context, scopes ...scope) error {
+    expr, args := r.values.currencySQL("prices.value")
+
+    var missing struct {
+        Currency string
+        Date     time.Time
+    }
+    err := r.db.WithContext(ctx).
+        Model(&Price{}).
+        Scopes(scopes...).
+        Select("prices.currency, prices.date").
+        Where("("+expr+") IS NULL", args...).
+        Order("prices.date asc").
+        Limit(1).
+        Scan(&missing).Error
+    if err != nil {
+        return fmt.Errorf("exchange rate check failed: %w", err)
+    }
+    if missing.Currency != "" {
+        return invalidf("no %s/%s exchange rate on or before %s",
+            missing.Currency, r.values.Currency, missing.Date.Format("2006-01-02"))
+    }
+    return nil
+}
+
+// checkDeflatable makes sure the base month and every month with prices in
+// scope have an index value.
+func (r *priceRepository) checkDeflatable(ctx context.Context, scopes ...scope) error {
+    var base int64
+    err := r.db.WithContext(ctx).
+        Table("price_indices").
+        Where("period = ? AND deleted_at IS NULL", r.values.Base).
+        Count(&base).Error
+    if err != nil {
+        return fmt.Errorf("price index check failed: %w", err)
+    }
+    if base == 0 {
+        return invalidf("no price index for base period %s", r.values.Base.Format("2006-01"))
+    }
+
+    var missing struct {
+        Date time.Time
+    }
+    err = r.db.WithContext(ctx).
+        Model(&Price{}).
+        Scopes(scopes...).
+        Select("prices.date").
+        Where(monthIndex + " IS NULL").
+        Order("prices.date asc").
+        Limit(1).
+        Scan(&missing).Error
+    if err != nil {
+        return fmt.Errorf("price index check failed: %w", err)
+    }
+    if !missing.Date.IsZero() {
+        return invalidf("no price index for %s", missing.Date.Format("2006-01"))
+    }
+    return nil
+}
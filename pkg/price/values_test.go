@@ -0,0 +1,44 @@
+package price
+
+import (
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestValueSQL(t *testing.T) {
+    base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+    tests := []struct {
+        name     string
+        opts     ValueOptions
+        contains []string
+        args     int
+    }{
+        {"as stored", ValueOptions{}, nil, 0},
+        {"currency", ValueOptions{Currency: "USD"}, []string{"CASE WHEN", "exchange_rates"}, 3},
+        {"real", ValueOptions{Real: true, Base: base}, []string{"price_indices"}, 1},
+        {"currency then real", ValueOptions{Currency: "USD", Real: true, Base: base}, []string{"CASE WHEN", "price_indices"}, 4},
+    }
+    for _, tt := range tests {
+        for _, column := range []string{"prices.value", "prices.reported_value"} {
+            t.Run(tt.name+" "+column, func(t *testing.T) {
+                expr, args := tt.opts.valueSQL(column)
+                if len(args) != tt.args || strings.Count(expr, "?") != tt.args {
+                    t.Errorf("got %d args and %d placeholders, want %d", len(args), strings.Count(expr, "?"), tt.args)
+                }
+                for _, s := range tt.contains {
+                    if !strings.Contains(expr, s) {
+                        t.Errorf("expression lacks %q: %s", s, expr)
+                    }
+                }
+                other := "prices.reported_value"
+                if column == other {
+                    other = "prices.value"
+                }
+                if !strings.Contains(expr, column) || strings.Contains(expr, other) {
+                    t.Errorf("expression does not apply to %s only: %s", column, expr)
+                }
+            })
+        }
+    }
+}
@@ -0,0 +1,197 @@
+package stream
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	heartbeatInterval = 15 * time.Second
+	writeWait         = 10 * time.Second
+	pongWait          = 60 * time.Second
+	pingPeriod        = pongWait * 9 / 10
+)
+
+type Handler struct {
+	hub      *Hub
+	upgrader websocket.Upgrader
+}
+
+// NewHandler serves hub's events. allowOrigin decides which cross-origin
+// pages may open a WebSocket, and should be the same allowlist the CORS
+// middleware applies to the REST API.
+func NewHandler(hub *Hub, allowOrigin func(origin string) bool) *Handler {
+	return &Handler{hub: hub, upgrader: websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     checkOrigin(allowOrigin),
+	}}
+}
+
+// checkOrigin accepts clients that send no Origin, which are not browsers,
+// pages served from the API's own host, and the origins allowOrigin allows.
+// Browsers do not apply CORS to WebSockets, so this is the only check
+// between another site's page and the stream.
+func checkOrigin(allowOrigin func(string) bool) func(*http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+			return true
+		}
+		return allowOrigin != nil && allowOrigin(origin)
+	}
+}
+
+// StreamPrices sends price events as Server-Sent Events, with a comment line
+// every heartbeatInterval to keep proxies from closing an idle connection.
+func (h *Handler) StreamPrices(c *gin.Context) {
+	filter, ok := parseFilter(c)
+	if !ok {
+		return
+	}
+
+	sub := h.hub.Subscribe(filter)
+	defer h.hub.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+	// A stream outlives the server's write timeout by design.
+	http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-sub.Events:
+			if !ok {
+				return false
+			}
+			c.Render(-1, sse.Event{
+				Id:    strconv.FormatUint(event.ID, 10),
+				Event: event.Type,
+				Data:  event.Data,
+			})
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		}
+	})
+}
+
+// StreamPricesWS sends the same events as JSON WebSocket messages. Incoming
+// messages are ignored; reading only serves to notice the client leaving and
+// to answer pings.
+func (h *Handler) StreamPricesWS(c *gin.Context) {
+	filter, ok := parseFilter(c)
+	if !ok {
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	sub := h.hub.Subscribe(filter)
+	defer h.hub.Unsubscribe(sub)
+
+	gone := make(chan struct{})
+	conn.SetReadLimit(512)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	go func() {
+		defer close(gone)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(pingPeriod)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-gone:
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				msg := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "subscription closed")
+				conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(writeWait))
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// parseFilter reads komoditas_id and market_id, each given as a
+// comma-separated list or repeated.
+func parseFilter(c *gin.Context) (Filter, bool) {
+	komoditasIDs, err := parseIDs(c.QueryArray("komoditas_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("invalid komoditas_id: %v", err),
+		})
+		return Filter{}, false
+	}
+	marketIDs, err := parseIDs(c.QueryArray("market_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("invalid market_id: %v", err),
+		})
+		return Filter{}, false
+	}
+	return Filter{KomoditasIDs: komoditasIDs, MarketIDs: marketIDs}, true
+}
+
+func parseIDs(values []string) (map[uint]bool, error) {
+	ids := make(map[uint]bool)
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			id, err := strconv.ParseUint(part, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not an id", part)
+			}
+			ids[uint(id)] = true
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,146 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ryuzxy/FuncPro/pkg/price"
+)
+
+func TestStreamPricesFrame(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hub := NewHub()
+	defer hub.Close()
+
+	router := gin.New()
+	router.GET("/stream/prices", NewHandler(hub, nil).StreamPrices)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/stream/prices?komoditas_id=1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	// The handler subscribes before flushing headers, so the subscriber is
+	// registered by the time Do returns.
+	if hub.Count() != 1 {
+		t.Fatalf("Count() = %d, want 1", hub.Count())
+	}
+
+	hub.Publish(ctx, []price.Price{
+		{ID: 6, KomoditasID: 2},
+		{ID: 7, KomoditasID: 1, Unit: "kg", Currency: "IDR"},
+	})
+
+	reader := bufio.NewReader(resp.Body)
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading frame: %v (got %q)", err, lines)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			break
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) != 3 {
+		t.Fatalf("frame has %d lines, want 3: %q", len(lines), lines)
+	}
+	if lines[0] != "id:2" {
+		t.Errorf("id line = %q, want id:2", lines[0])
+	}
+	if lines[1] != "event:"+EventPriceCreated {
+		t.Errorf("event line = %q", lines[1])
+	}
+	data, ok := strings.CutPrefix(lines[2], "data:")
+	if !ok {
+		t.Fatalf("data line = %q", lines[2])
+	}
+	var got price.PriceResponse
+	if err := json.Unmarshal([]byte(data), &got); err != nil {
+		t.Fatalf("data is not a price: %v", err)
+	}
+	if got.ID != 7 || got.KomoditasID != 1 || got.Unit != "kg" {
+		t.Errorf("data = %+v", got)
+	}
+}
+
+func TestStreamPricesRejectsBadFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/stream/prices", NewHandler(NewHub(), nil).StreamPrices)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stream/prices?market_id=x", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
+
+func TestParseIDs(t *testing.T) {
+	ids, err := parseIDs([]string{"1, 2", "3", ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []uint{1, 2, 3} {
+		if !ids[id] {
+			t.Errorf("missing id %d", id)
+		}
+	}
+	if len(ids) != 3 {
+		t.Errorf("got %d ids, want 3", len(ids))
+	}
+	if _, err := parseIDs([]string{"1,-2"}); err == nil {
+		t.Error("negative id accepted")
+	}
+}
+
+func TestCheckOrigin(t *testing.T) {
+	allow := func(origin string) bool { return origin == "https://dash.example.com" }
+	tests := []struct {
+		name   string
+		origin string
+		allow  func(string) bool
+		want   bool
+	}{
+		{"no origin", "", nil, true},
+		{"same host", "https://api.example.com", nil, true},
+		{"same host other case", "https://API.example.com", nil, true},
+		{"allowed origin", "https://dash.example.com", allow, true},
+		{"other origin", "https://evil.example", allow, false},
+		{"no allowlist", "https://dash.example.com", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://api.example.com/stream/prices/ws", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if got := checkOrigin(tt.allow)(req); got != tt.want {
+				t.Errorf("checkOrigin(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package stream
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ryuzxy/FuncPro/pkg/price"
+)
+
+// clientBuffer is how many events a subscriber may fall behind before it is
+// dropped.
+const clientBuffer = 64
+
+const EventPriceCreated = "price.created"
+
+// Event is one message sent to subscribers.
+type Event struct {
+	ID   uint64              `json:"id"`
+	Type string              `json:"type"`
+	Data price.PriceResponse `json:"data"`
+}
+
+// Filter narrows a subscription to some komoditas and markets. Empty sets
+// match everything.
+type Filter struct {
+	KomoditasIDs map[uint]bool
+	MarketIDs    map[uint]bool
+}
+
+func (f Filter) matches(p price.Price) bool {
+	if len(f.KomoditasIDs) > 0 && !f.KomoditasIDs[p.KomoditasID] {
+		return false
+	}
+	if len(f.MarketIDs) > 0 && (p.MarketID == nil || !f.MarketIDs[*p.MarketID]) {
+		return false
+	}
+	return true
+}
+
+// Subscription receives events on Events until it is closed, either by
+// Unsubscribe or by the hub dropping it for falling behind.
+type Subscription struct {
+	Events <-chan Event
+	events chan Event
+	filter Filter
+}
+
+// Hub fans price events out to subscribers. Publishing never blocks: a
+// subscriber whose buffer is full is disconnected so one slow client cannot
+// hold up price creation or the other clients.
+type Hub struct {
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	seq    atomic.Uint64
+	closed bool
+}
+
+func NewHub() *Hub {
+	return &Hub{subs: make(map[*Subscription]struct{})}
+}
+
+func (h *Hub) Subscribe(filter Filter) *Subscription {
+	ch := make(chan Event, clientBuffer)
+	sub := &Subscription{Events: ch, events: ch, filter: filter}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(ch)
+		return sub
+	}
+	h.subs[sub] = struct{}{}
+	return sub
+}
+
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(sub)
+}
+
+// remove must be called with the lock held.
+func (h *Hub) remove(sub *Subscription) {
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.events)
+	}
+}
+
+// Publish is a price.CreatedListener.
+func (h *Hub) Publish(_ context.Context, prices []price.Price) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, p := range prices {
+		event := Event{ID: h.seq.Add(1), Type: EventPriceCreated, Data: price.ToResponse(p)}
+		for sub := range h.subs {
+			if !sub.filter.matches(p) {
+				continue
+			}
+			select {
+			case sub.events <- event:
+			default:
+				h.remove(sub)
+			}
+		}
+	}
+}
+
+// Count is the number of connected subscribers.
+func (h *Hub) Count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
+
+// Close disconnects every subscriber and refuses new ones.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for sub := range h.subs {
+		h.remove(sub)
+	}
+}
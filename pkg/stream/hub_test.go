@@ -0,0 +1,107 @@
+package stream
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ryuzxy/FuncPro/pkg/price"
+)
+
+func uintPtr(v uint) *uint { return &v }
+
+func TestFilterMatches(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter
+		price  price.Price
+		want   bool
+	}{
+		{"empty matches all", Filter{}, price.Price{KomoditasID: 1}, true},
+		{"komoditas match", Filter{KomoditasIDs: map[uint]bool{1: true}}, price.Price{KomoditasID: 1}, true},
+		{"komoditas mismatch", Filter{KomoditasIDs: map[uint]bool{1: true}}, price.Price{KomoditasID: 2}, false},
+		{"market match", Filter{MarketIDs: map[uint]bool{3: true}}, price.Price{KomoditasID: 1, MarketID: uintPtr(3)}, true},
+		{"market mismatch", Filter{MarketIDs: map[uint]bool{3: true}}, price.Price{KomoditasID: 1, MarketID: uintPtr(4)}, false},
+		{"market filter without market", Filter{MarketIDs: map[uint]bool{3: true}}, price.Price{KomoditasID: 1}, false},
+		{
+			"both match",
+			Filter{KomoditasIDs: map[uint]bool{1: true}, MarketIDs: map[uint]bool{3: true}},
+			price.Price{KomoditasID: 1, MarketID: uintPtr(3)},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.matches(tt.price); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHubPublishFiltersSubscribers(t *testing.T) {
+	hub := NewHub()
+	defer hub.Close()
+
+	rice := hub.Subscribe(Filter{KomoditasIDs: map[uint]bool{1: true}})
+	sugar := hub.Subscribe(Filter{KomoditasIDs: map[uint]bool{2: true}})
+
+	hub.Publish(context.Background(), []price.Price{{ID: 10, KomoditasID: 1}})
+
+	select {
+	case event := <-rice.Events:
+		if event.Type != EventPriceCreated || event.Data.ID != 10 || event.ID != 1 {
+			t.Errorf("unexpected event %+v", event)
+		}
+	default:
+		t.Fatal("matching subscriber got no event")
+	}
+	select {
+	case event := <-sugar.Events:
+		t.Fatalf("non-matching subscriber got %+v", event)
+	default:
+	}
+}
+
+func TestHubDropsSlowSubscriber(t *testing.T) {
+	hub := NewHub()
+	defer hub.Close()
+
+	slow := hub.Subscribe(Filter{})
+	prices := make([]price.Price, clientBuffer)
+	for i := range prices {
+		prices[i] = price.Price{ID: uint(i + 1), KomoditasID: 1}
+	}
+
+	hub.Publish(context.Background(), prices)
+	if hub.Count() != 1 {
+		t.Fatalf("subscriber dropped with a full but not overflowing buffer")
+	}
+
+	hub.Publish(context.Background(), []price.Price{{ID: uint(clientBuffer + 1), KomoditasID: 1}})
+	if hub.Count() != 0 {
+		t.Fatalf("Count() = %d after overflow, want 0", hub.Count())
+	}
+
+	received := 0
+	for range slow.Events {
+		received++
+	}
+	if received != clientBuffer {
+		t.Errorf("received %d buffered events, want %d", received, clientBuffer)
+	}
+}
+
+func TestHubClose(t *testing.T) {
+	hub := NewHub()
+	sub := hub.Subscribe(Filter{})
+	hub.Close()
+
+	if _, ok := <-sub.Events; ok {
+		t.Error("subscription still open after Close")
+	}
+	late := hub.Subscribe(Filter{})
+	if _, ok := <-late.Events; ok {
+		t.Error("Subscribe after Close returned an open subscription")
+	}
+	hub.Unsubscribe(sub)
+}
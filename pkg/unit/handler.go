@@ -0,0 +1,17 @@
+package unit
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// List returns the supported units and their sizes.
+func List(c *gin.Context) {
+	all := All()
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    all,
+		"count":   len(all),
+	})
+}
@@ -0,0 +1,115 @@
+package unit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Dimension string
+
+const (
+	Mass   Dimension = "mass"
+	Volume Dimension = "volume"
+	Count  Dimension = "count"
+)
+
+// Unit is a unit of measure. Size is expressed in the smallest unit of its
+// dimension (grams, millilitres or pieces) so conversions stay integral.
+type Unit struct {
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Dimension Dimension `json:"dimension"`
+	Size      int64     `json:"size"`
+}
+
+var units = map[string]Unit{
+	"g":       {Code: "g", Name: "gram", Dimension: Mass, Size: 1},
+	"ons":     {Code: "ons", Name: "ons", Dimension: Mass, Size: 100},
+	"kg":      {Code: "kg", Name: "kilogram", Dimension: Mass, Size: 1000},
+	"kuintal": {Code: "kuintal", Name: "kuintal", Dimension: Mass, Size: 100000},
+	"ton":     {Code: "ton", Name: "ton", Dimension: Mass, Size: 1000000},
+	"ml":      {Code: "ml", Name: "mililiter", Dimension: Volume, Size: 1},
+	"l":       {Code: "l", Name: "liter", Dimension: Volume, Size: 1000},
+	"pcs":     {Code: "pcs", Name: "buah", Dimension: Count, Size: 1},
+	"butir":   {Code: "butir", Name: "butir", Dimension: Count, Size: 1},
+	"ekor":    {Code: "ekor", Name: "ekor", Dimension: Count, Size: 1},
+	"lusin":   {Code: "lusin", Name: "lusin", Dimension: Count, Size: 12},
+	"kodi":    {Code: "kodi", Name: "kodi", Dimension: Count, Size: 20},
+}
+
+var aliases = map[string]string{
+	"gr":        "g",
+	"gram":      "g",
+	"kilogram":  "kg",
+	"kwintal":   "kuintal",
+	"q":         "kuintal",
+	"t":         "ton",
+	"liter":     "l",
+	"litre":     "l",
+	"lt":        "l",
+	"mililiter": "ml",
+	"buah":      "pcs",
+	"pc":        "pcs",
+}
+
+func Lookup(code string) (Unit, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if alias, ok := aliases[code]; ok {
+		code = alias
+	}
+	u, ok := units[code]
+	if !ok {
+		return Unit{}, fmt.Errorf("unknown unit %q", code)
+	}
+	return u, nil
+}
+
+// All lists the conversion table ordered by dimension and size.
+func All() []Unit {
+	out := make([]Unit, 0, len(units))
+	for _, u := range units {
+		out = append(out, u)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Dimension != out[j].Dimension {
+			return out[i].Dimension < out[j].Dimension
+		}
+		if out[i].Size != out[j].Size {
+			return out[i].Size < out[j].Size
+		}
+		return out[i].Code < out[j].Code
+	})
+	return out
+}
+
+// PriceFactor returns the ratio num/den that turns a price quoted per `from`
+// into a price per `to`. Count and mass units can be mixed when the weight of
+// one piece in grams is known (pieceGrams > 0); volume never converts to
+// another dimension.
+func PriceFactor(from, to Unit, pieceGrams int64) (num, den int64, err error) {
+	fromSize, toSize := from.Size, to.Size
+	if from.Dimension != to.Dimension {
+		if pieceGrams <= 0 || !massOrCount(from) || !massOrCount(to) {
+			return 0, 0, fmt.Errorf("cannot convert %s to %s", from.Code, to.Code)
+		}
+		if from.Dimension == Count {
+			fromSize *= pieceGrams
+		} else {
+			toSize *= pieceGrams
+		}
+	}
+	g := gcd(toSize, fromSize)
+	return toSize / g, fromSize / g, nil
+}
+
+func massOrCount(u Unit) bool {
+	return u.Dimension == Mass || u.Dimension == Count
+}
+
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
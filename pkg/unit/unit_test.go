@@ -0,0 +1,55 @@
+package unit
+
+import "testing"
+
+func TestPriceFactor(t *testing.T) {
+	tests := []struct {
+		name       string
+		from, to   string
+		pieceGrams int64
+		num, den   int64
+		wantErr    bool
+	}{
+		{"same unit", "kg", "kg", 0, 1, 1, false},
+		{"per gram to per kg", "g", "kg", 0, 1000, 1, false},
+		{"per ton to per kg", "ton", "kg", 0, 1, 1000, false},
+		{"per ons to per kg", "ons", "kg", 0, 10, 1, false},
+		{"per kuintal to per kg", "kwintal", "kg", 0, 1, 100, false},
+		{"per ml to per l", "ml", "liter", 0, 1000, 1, false},
+		{"per lusin to per butir", "lusin", "butir", 0, 1, 12, false},
+		{"per butir to per kg", "butir", "kg", 60, 50, 3, false},
+		{"per kg to per ekor", "kg", "ekor", 1500, 3, 2, false},
+		{"count to mass without weight", "butir", "kg", 0, 0, 0, true},
+		{"volume to mass", "l", "kg", 1000, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, err := Lookup(tt.from)
+			if err != nil {
+				t.Fatal(err)
+			}
+			to, err := Lookup(tt.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			num, den, err := PriceFactor(from, to, tt.pieceGrams)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if num != tt.num || den != tt.den {
+				t.Errorf("PriceFactor = %d/%d, want %d/%d", num, den, tt.num, tt.den)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for _, code := range []string{"KG", " gram ", "Lt", "buah"} {
+		if _, err := Lookup(code); err != nil {
+			t.Errorf("Lookup(%q): %v", code, err)
+		}
+	}
+	if _, err := Lookup("bushel"); err == nil {
+		t.Error("Lookup accepted an unknown unit")
+	}
+}
@@ -1,67 +1,272 @@
 package router
 
 import (
-    "time"
+    "context"
+    "log/slog"
 
     "github.com/gin-gonic/gin"
+    "go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
     "gorm.io/gorm"
 
+    "github.com/ryuzxy/FuncPro/db"
+    "github.com/ryuzxy/FuncPro/internal/config"
+    "github.com/ryuzxy/FuncPro/internal/health"
+    "github.com/ryuzxy/FuncPro/internal/metrics"
     "github.com/ryuzxy/FuncPro/internal/middleware"
+    "github.com/ryuzxy/FuncPro/pkg/alert"
+    "github.com/ryuzxy/FuncPro/pkg/auth"
+    "github.com/ryuzxy/FuncPro/pkg/basket"
+    "github.com/ryuzxy/FuncPro/pkg/currency"
+    "github.com/ryuzxy/FuncPro/pkg/inflation"
     "github.com/ryuzxy/FuncPro/pkg/komoditas"
+    "github.com/ryuzxy/FuncPro/pkg/market"
     "github.com/ryuzxy/FuncPro/pkg/price"
+    "github.com/ryuzxy/FuncPro/pkg/stream"
+    "github.com/ryuzxy/FuncPro/pkg/unit"
 )
 
-func SetupRouter(db *gorm.DB) *gin.Engine {
-    r := gin.Default()
+// Background is what SetupRouter starts besides the routes: the price
+// stream hub and the alert evaluator with its webhook dispatcher.
+type Background struct {
+    streams *stream.Hub
+    alerts  alert.Service
+}
+
+// CloseStreams ends open price streams, so their requests return instead of
+// holding up a graceful shutdown.
+func (b *Background) CloseStreams() {
+    b.streams.Close()
+}
 
-    // Middleware
+// Close stops the background workers. Webhook deliveries not yet sent stay
+// pending in the database for the next start.
+func (b *Background) Close() {
+    b.streams.Close()
+    b.alerts.Close()
+}
+
+func SetupRouter(database *gorm.DB, cfg *config.Config) (*gin.Engine, *Background, error) {
+    verifier, err := auth.NewVerifier(auth.JWTConfig{
+        Secret:    cfg.Auth.JWTSecret,
+        JWKSFile:  cfg.Auth.JWKSFile,
+        Issuer:    cfg.Auth.JWTIssuer,
+        Audience:  cfg.Auth.JWTAudience,
+        RoleClaim: cfg.Auth.RoleClaim,
+    })
+    if err != nil {
+        return nil, nil, err
+    }
+
+    r := gin.New()
+    if err := r.SetTrustedProxies(config.SplitList(cfg.Server.TrustedProxies)); err != nil {
+        return nil, nil, err
+    }
+    registry := metrics.New()
+
+    // Middleware. The request span starts first, from the caller's
+    // traceparent if any, then RequestID, so every later log line carries
+    // both; metrics wrap Recovery so panics are counted as 500s.
+    r.Use(otelgin.Middleware(cfg.Tracing.ServiceName, otelgin.WithGinFilter(func(c *gin.Context) bool {
+        return !isProbe(c.Request.URL.Path)
+    })))
+    r.Use(middleware.RequestID())
+    r.Use(registry.Middleware())
     r.Use(middleware.Logger())
-    r.Use(gin.Recovery()) 
-    r.Use(middleware.CORS())
+    r.Use(middleware.Recovery())
+    corsPolicy := middleware.CORSPolicy{
+        AllowedOrigins:   config.SplitList(cfg.CORS.AllowedOrigins),
+        AllowCredentials: cfg.CORS.AllowCredentials,
+        AllowedHeaders:   config.SplitList(cfg.CORS.AllowedHeaders),
+        ExposedHeaders:   config.SplitList(cfg.CORS.ExposedHeaders),
+        MaxAge:           cfg.CORS.MaxAge,
+    }
+    r.Use(middleware.CORS(corsPolicy, r.Routes))
 
     // Initialize repositories
-    komoditasRepo := komoditas.NewRepository(db)
-    priceRepo := price.NewPriceRepository(db)
+    komoditasRepo := komoditas.NewRepository(database)
+    priceRepo := price.NewPriceRepository(database)
+    marketRepo := market.NewRepository(database)
+    currencyRepo := currency.NewRepository(database)
+    inflationRepo := inflation.NewRepository(database)
+    basketRepo := basket.NewRepository(database)
+    alertRepo := alert.NewRepository(database)
+    apiKeyRepo := auth.NewRepository(database)
 
     // Initialize services
     komoditasService := komoditas.NewService(komoditasRepo) // hanya 1 argumen
-    priceService := price.NewService(priceRepo)
+    priceService := price.NewService(priceRepo, marketRepo, komoditasRepo, price.Config{
+        WindowDays:    cfg.Analysis.WindowDays,
+        MaxBulkItems:  cfg.Limits.MaxBulkItems,
+        MaxSeriesDays: cfg.Analysis.MaxSeriesDays,
+    })
+    marketService := market.NewService(marketRepo)
+    currencyService := currency.NewService(currencyRepo)
+    inflationService := inflation.NewService(inflationRepo)
+    basketService := basket.NewService(basketRepo, priceService, komoditasRepo)
+    authService := auth.NewService(apiKeyRepo)
+    alertService := alert.NewService(alertRepo, priceRepo, komoditasRepo, alert.NewDispatcher(alertRepo), cfg.Analysis.WindowDays)
+    priceService.OnCreated(alertService.PricesCreated)
+    priceHub := stream.NewHub()
+    priceService.OnCreated(priceHub.Publish)
+
+    // Metrics
+    registry.WatchPrices(priceService)
+    registry.WatchAlerts(alertService)
+    if sqlDB, err := database.DB(); err == nil {
+        registry.WatchPool(sqlDB)
+    }
+    if err := registry.WatchQueries(database); err != nil {
+        slog.Warn("repository call durations are not recorded", "error", err)
+    }
 
     // Initialize handlers
     komoditasHandler := komoditas.NewHandler(komoditasService)
     priceHandler := price.NewHandler(priceService)
+    marketHandler := market.NewHandler(marketService)
+    currencyHandler := currency.NewHandler(currencyService)
+    inflationHandler := inflation.NewHandler(inflationService)
+    basketHandler := basket.NewHandler(basketService)
+    alertHandler := alert.NewHandler(alertService)
+    streamHandler := stream.NewHandler(priceHub, corsPolicy.Allows)
+    authHandler := auth.NewHandler(authService)
+    healthHandler := health.NewHandler(cfg.Server.ReadyTimeout,
+        health.Check{Name: "database", Run: func(ctx context.Context) error { return db.Ping(ctx, database) }},
+        health.Check{Name: "migrations", Run: func(ctx context.Context) error { return db.CheckSchema(ctx, database) }},
+    )
+
+    // Probes and metrics for the orchestrator, outside the versioned API
+    r.GET("/livez", healthHandler.Live)
+    r.GET("/readyz", healthHandler.Ready)
+    r.GET("/metrics", registry.Handler())
+
+    // Every API request is authenticated; each route then names the least
+    // role it needs. The health routes need none.
+    authenticator := auth.NewAuthenticator(authService, verifier, auth.Role(cfg.Auth.AnonymousRole))
+    viewer := auth.Require(auth.RoleViewer)
+    contributor := auth.Require(auth.RoleContributor)
+    admin := auth.Require(auth.RoleAdmin)
+
+    // Each client gets its own budget per route group, after
+    // authentication so API keys are not lumped together by IP.
+    reads := middleware.RateLimit(middleware.NewRateLimiter(cfg.Limits.Read.PerMinute, cfg.Limits.Read.Burst))
+    analyses := middleware.RateLimit(middleware.NewRateLimiter(cfg.Limits.Analysis.PerMinute, cfg.Limits.Analysis.Burst))
+    writes := middleware.RateLimit(middleware.NewRateLimiter(cfg.Limits.Write.PerMinute, cfg.Limits.Write.Burst))
 
     // API routes
-    api := r.Group("/api/v1")
+    api := r.Group("/api/v1", middleware.BodyLimit(int64(cfg.Limits.MaxBodyBytes)), authenticator.Middleware())
     {
         // Komoditas routes
         komoditasGroup := api.Group("/komoditas")
         {
-            komoditasGroup.GET("", komoditasHandler.GetAllKomoditas)
-            komoditasGroup.POST("", komoditasHandler.CreateKomoditas)
-            komoditasGroup.GET("/:id", komoditasHandler.GetKomoditasByID)
-            komoditasGroup.PUT("/:id", komoditasHandler.UpdateKomoditas)
-            komoditasGroup.DELETE("/:id", komoditasHandler.DeleteKomoditas)
-            komoditasGroup.GET("/:id/stats", komoditasHandler.GetKomoditasStats)
+            komoditasGroup.GET("", viewer, reads, komoditasHandler.GetAllKomoditas)
+            komoditasGroup.POST("", admin, writes, komoditasHandler.CreateKomoditas)
+            komoditasGroup.GET("/:id", viewer, reads, komoditasHandler.GetKomoditasByID)
+            komoditasGroup.PUT("/:id", admin, writes, komoditasHandler.UpdateKomoditas)
+            komoditasGroup.DELETE("/:id", admin, writes, komoditasHandler.DeleteKomoditas)
+            komoditasGroup.GET("/:id/stats", viewer, analyses, komoditasHandler.GetKomoditasStats)
         }
 
         // Price routes
         priceGroup := api.Group("/prices")
         {
-            priceGroup.POST("", priceHandler.CreatePrice)
-            priceGroup.POST("/bulk", priceHandler.BulkCreatePrices)
-            priceGroup.GET("/komoditas/:komoditas_id", priceHandler.GetPricesByKomoditas)
-            priceGroup.GET("/komoditas/:komoditas_id/analysis", priceHandler.GetPriceAnalysis)
+            priceGroup.POST("", contributor, writes, priceHandler.CreatePrice)
+            priceGroup.POST("/bulk", contributor, writes, priceHandler.BulkCreatePrices)
+            priceGroup.GET("/correlation", viewer, analyses, priceHandler.GetCorrelation)
+            priceGroup.GET("/komoditas/:komoditas_id", viewer, reads, priceHandler.GetPricesByKomoditas)
+            priceGroup.GET("/komoditas/:komoditas_id/analysis", viewer, analyses, priceHandler.GetPriceAnalysis)
+            priceGroup.GET("/komoditas/:komoditas_id/aggregate", viewer, analyses, priceHandler.GetPriceAggregate)
+            priceGroup.GET("/komoditas/:komoditas_id/series", viewer, analyses, priceHandler.GetPriceSeries)
+            priceGroup.GET("/komoditas/:komoditas_id/disparity", viewer, analyses, priceHandler.GetDisparity)
+            priceGroup.GET("/komoditas/:komoditas_id/seasonality", viewer, analyses, priceHandler.GetSeasonality)
         }
 
-        // Health check
-        api.GET("/health", func(c *gin.Context) {
-            c.JSON(200, gin.H{
-                "status":    "ok",
-                "timestamp": time.Now().Unix(),
-            })
-        })
+        // Market routes
+        marketGroup := api.Group("/markets")
+        {
+            marketGroup.GET("", viewer, reads, marketHandler.GetAllMarkets)
+            marketGroup.POST("", admin, writes, marketHandler.CreateMarket)
+            marketGroup.GET("/:id", viewer, reads, marketHandler.GetMarketByID)
+            marketGroup.PUT("/:id", admin, writes, marketHandler.UpdateMarket)
+            marketGroup.DELETE("/:id", admin, writes, marketHandler.DeleteMarket)
+        }
+
+        // Exchange rate routes
+        rateGroup := api.Group("/exchange-rates")
+        {
+            rateGroup.GET("", viewer, reads, currencyHandler.GetAllRates)
+            rateGroup.POST("", admin, writes, currencyHandler.CreateRate)
+            rateGroup.POST("/import", admin, writes, currencyHandler.ImportRates)
+            rateGroup.GET("/:id", viewer, reads, currencyHandler.GetRateByID)
+            rateGroup.PUT("/:id", admin, writes, currencyHandler.UpdateRate)
+            rateGroup.DELETE("/:id", admin, writes, currencyHandler.DeleteRate)
+        }
+
+        // Price index (CPI / deflator) routes
+        indexGroup := api.Group("/price-indices")
+        {
+            indexGroup.GET("", viewer, reads, inflationHandler.GetAllIndices)
+            indexGroup.POST("", admin, writes, inflationHandler.CreateIndex)
+            indexGroup.GET("/:id", viewer, reads, inflationHandler.GetIndexByID)
+            indexGroup.PUT("/:id", admin, writes, inflationHandler.UpdateIndex)
+            indexGroup.DELETE("/:id", admin, writes, inflationHandler.DeleteIndex)
+        }
+
+        // Basket routes
+        basketGroup := api.Group("/baskets")
+        {
+            basketGroup.GET("", viewer, reads, basketHandler.GetAllBaskets)
+            basketGroup.POST("", admin, writes, basketHandler.CreateBasket)
+            basketGroup.GET("/:id", viewer, reads, basketHandler.GetBasketByID)
+            basketGroup.PUT("/:id", admin, writes, basketHandler.UpdateBasket)
+            basketGroup.DELETE("/:id", admin, writes, basketHandler.DeleteBasket)
+            basketGroup.GET("/:id/index", viewer, analyses, basketHandler.GetBasketIndex)
+        }
+
+        // Real-time price stream routes. EventSource and browser
+        // WebSockets cannot set headers, so these also take a token in the
+        // access_token query parameter.
+        streamGroup := r.Group("/api/v1/stream", middleware.BodyLimit(int64(cfg.Limits.MaxBodyBytes)),
+            auth.TokenFromQuery(), authenticator.Middleware())
+        {
+            streamGroup.GET("/prices", viewer, reads, streamHandler.StreamPrices)
+            streamGroup.GET("/prices/ws", viewer, reads, streamHandler.StreamPricesWS)
+        }
+
+        // Alert rule routes
+        alertGroup := api.Group("/alerts/rules")
+        {
+            alertGroup.GET("", viewer, reads, alertHandler.GetAllRules)
+            alertGroup.POST("", admin, writes, alertHandler.CreateRule)
+            alertGroup.GET("/:id", viewer, reads, alertHandler.GetRuleByID)
+            alertGroup.PUT("/:id", admin, writes, alertHandler.UpdateRule)
+            alertGroup.DELETE("/:id", admin, writes, alertHandler.DeleteRule)
+            alertGroup.GET("/:id/deliveries", viewer, reads, alertHandler.GetDeliveries)
+        }
+
+        // API key management
+        keyGroup := api.Group("/api-keys", admin)
+        {
+            keyGroup.GET("", reads, authHandler.GetAllKeys)
+            keyGroup.POST("", writes, authHandler.CreateKey)
+            keyGroup.DELETE("/:id", writes, authHandler.RevokeKey)
+        }
+
+        // Unit of measure conversion table
+        api.GET("/units", viewer, reads, unit.List)
+
+        // Readiness under the API prefix, for clients that only know it
+        api.GET("/health", healthHandler.Ready)
     }
 
-    return r
+    return r, &Background{streams: priceHub, alerts: alertService}, nil
+}
+
+// isProbe reports whether path is polled by the orchestrator or Prometheus
+// rather than requested by a client; such requests are not traced.
+func isProbe(path string) bool {
+    switch path {
+    case "/livez", "/readyz", "/metrics":
+        return true
+    }
+    return false
 }